@@ -0,0 +1,202 @@
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Fixed actions follow the rebindable ones. Their keys and buttons are the
+// same for everyone and don't appear among the controls in the menu.
+const (
+	ActionConfirm    Action = numActions + iota // Accept a prompt or walk to the look cursor
+	ActionCancel                                // Back out of whatever is open, or open the pause menu
+	ActionClick                                 // Walk to or act on the tile under the mouse
+	ActionDragView                              // Held to drag the view around
+	ActionPanHold                               // Held to make the move keys pan the view
+	ActionScrollUp                              // Scroll an open log back
+	ActionScrollDown                            // Scroll an open log forward
+	ActionRestart                               // Start the run over with the same settings
+	ActionRetry                                 // Start over from the game over screen
+	ActionQuitToMenu                            // Leave the game over screen for the menu
+	ActionCopySeed                              // Copy the run seed from the game over screen
+	ActionStrike                                // Hit the timing of the disarm minigame
+	ActionErase                                 // Delete the last character of a text field
+	ActionPaste                                 // Paste into a text field
+	ActionYes                                   // Answer yes to a prompt
+	ActionNo                                    // Answer no to a prompt
+	ActionUseItem                               // Use the item selected in the inventory
+	ActionEquipItem                             // Equip the item selected in the inventory
+	ActionDropItem                              // Drop the item selected in the inventory
+	ActionSwitchTab                             // Switch the shop between buying and selling
+	numInputs
+)
+
+// triggerKind tells which device a trigger is on
+type triggerKind int
+
+const (
+	triggerKey triggerKind = iota
+	triggerCtrlKey
+	triggerMouse
+	triggerPad
+)
+
+// Trigger is a key, key chord, mouse button or gamepad button that sets off
+// an action
+type Trigger struct {
+	kind  triggerKind
+	key   ebiten.Key
+	mouse ebiten.MouseButton
+	pad   ebiten.StandardGamepadButton
+}
+
+func keyTrigger(k ebiten.Key) Trigger                   { return Trigger{kind: triggerKey, key: k} }
+func ctrlTrigger(k ebiten.Key) Trigger                  { return Trigger{kind: triggerCtrlKey, key: k} }
+func mouseTrigger(b ebiten.MouseButton) Trigger         { return Trigger{kind: triggerMouse, mouse: b} }
+func padTrigger(b ebiten.StandardGamepadButton) Trigger { return Trigger{kind: triggerPad, pad: b} }
+
+// fixedTriggers set off the fixed actions, and extra keys for some
+// rebindable ones on top of their bound key
+var fixedTriggers = map[Action][]Trigger{
+	ActionHelp:       {keyTrigger(ebiten.KeyF1)},
+	ActionZoomIn:     {keyTrigger(ebiten.KeyNumpadAdd)},
+	ActionZoomOut:    {keyTrigger(ebiten.KeyNumpadSubtract)},
+	ActionConfirm:    {keyTrigger(ebiten.KeyEnter), padTrigger(ebiten.StandardGamepadButtonRightBottom)},
+	ActionCancel:     {keyTrigger(ebiten.KeyEscape), padTrigger(ebiten.StandardGamepadButtonCenterRight)},
+	ActionClick:      {mouseTrigger(ebiten.MouseButtonLeft)},
+	ActionDragView:   {mouseTrigger(ebiten.MouseButtonRight), mouseTrigger(ebiten.MouseButtonMiddle)},
+	ActionPanHold:    {keyTrigger(ebiten.KeyShift)},
+	ActionScrollUp:   {keyTrigger(ebiten.KeyPageUp)},
+	ActionScrollDown: {keyTrigger(ebiten.KeyPageDown)},
+	ActionRestart:    {ctrlTrigger(ebiten.KeyR)},
+	ActionRetry:      {keyTrigger(ebiten.KeyR)},
+	ActionQuitToMenu: {keyTrigger(ebiten.KeyM)},
+	ActionCopySeed:   {keyTrigger(ebiten.KeyC)},
+	ActionStrike:     {keyTrigger(ebiten.KeySpace), padTrigger(ebiten.StandardGamepadButtonRightBottom)},
+	ActionErase:      {keyTrigger(ebiten.KeyBackspace)},
+	ActionPaste:      {ctrlTrigger(ebiten.KeyV)},
+	ActionYes:        {keyTrigger(ebiten.KeyY)},
+	ActionNo:         {keyTrigger(ebiten.KeyN)},
+	ActionUseItem:    {keyTrigger(ebiten.KeyU)},
+	ActionEquipItem:  {keyTrigger(ebiten.KeyE), padTrigger(ebiten.StandardGamepadButtonRightTop)},
+	ActionDropItem:   {keyTrigger(ebiten.KeyD), keyTrigger(ebiten.KeyDelete), padTrigger(ebiten.StandardGamepadButtonRightLeft)},
+	ActionSwitchTab:  {keyTrigger(ebiten.KeyTab), padTrigger(ebiten.StandardGamepadButtonRightLeft)},
+}
+
+// padBindings are the gamepad buttons of the rebindable actions, on a
+// standard layout: the D-pad moves, the face buttons act and the shoulder
+// buttons zoom
+var padBindings = map[Action]ebiten.StandardGamepadButton{
+	ActionMoveUp:    ebiten.StandardGamepadButtonLeftTop,
+	ActionMoveDown:  ebiten.StandardGamepadButtonLeftBottom,
+	ActionMoveLeft:  ebiten.StandardGamepadButtonLeftLeft,
+	ActionMoveRight: ebiten.StandardGamepadButtonLeftRight,
+	ActionInteract:  ebiten.StandardGamepadButtonRightBottom,
+	ActionInventory: ebiten.StandardGamepadButtonRightRight,
+	ActionCharacter: ebiten.StandardGamepadButtonRightTop,
+	ActionLook:      ebiten.StandardGamepadButtonRightLeft,
+	ActionZoomIn:    ebiten.StandardGamepadButtonFrontTopRight,
+	ActionZoomOut:   ebiten.StandardGamepadButtonFrontTopLeft,
+	ActionHelp:      ebiten.StandardGamepadButtonCenterLeft,
+}
+
+// triggers returns everything that sets off an action under the bindings
+func (b *KeyBindings) triggers(a Action) []Trigger {
+	var out []Trigger
+	if a < numActions {
+		out = append(out, keyTrigger(b[a]))
+	}
+	if button, ok := padBindings[a]; ok {
+		out = append(out, padTrigger(button))
+	}
+	return append(out, fixedTriggers[a]...)
+}
+
+// duration is how many ticks the trigger has been held, counting this one:
+// 1 on the tick it goes down, 0 while it is up
+func (t Trigger) duration() int {
+	switch t.kind {
+	case triggerKey:
+		return inpututil.KeyPressDuration(t.key)
+	case triggerCtrlKey:
+		if !ebiten.IsKeyPressed(ebiten.KeyControl) {
+			return 0
+		}
+		return inpututil.KeyPressDuration(t.key)
+	case triggerMouse:
+		return inpututil.MouseButtonPressDuration(t.mouse)
+	case triggerPad:
+		longest := 0
+		for _, id := range ebiten.AppendGamepadIDs(nil) {
+			if ebiten.IsStandardGamepadLayoutAvailable(id) {
+				longest = max(longest, inpututil.StandardGamepadButtonPressDuration(id, t.pad))
+			}
+		}
+		return longest
+	}
+	return 0
+}
+
+// Input is one tick of the player's input, read as actions through the
+// bindings. The game asks it rather than the keyboard, mouse and gamepads,
+// so rebound keys and gamepads drive it alike, and a tick of input is plain
+// data that could be recorded and played back.
+type Input struct {
+	ticks   [numInputs]int // How long each action has been held, by its longest held trigger
+	just    [numInputs]bool
+	pressed bool        // Did any key or button go down, bound or not
+	Cursor  image.Point // Mouse position on screen
+	Wheel   float64     // Vertical wheel movement; positive scrolls up
+}
+
+// input is this tick's input, read once at the start of every update
+var input Input
+
+// Held keys repeat after keyRepeatDelay ticks, then every keyRepeatInterval
+// ticks, like typing. Both are set from the settings when a run starts.
+var (
+	keyRepeatDelay    = 18
+	keyRepeatInterval = 6
+)
+
+// readInput reads this tick's input from the devices
+func readInput() Input {
+	var in Input
+	for a := Action(0); a < numInputs; a++ {
+		for _, t := range bindings.triggers(a) {
+			d := t.duration()
+			in.ticks[a] = max(in.ticks[a], d)
+			in.just[a] = in.just[a] || d == 1
+		}
+		in.pressed = in.pressed || in.just[a]
+	}
+	in.pressed = in.pressed || len(inpututil.AppendJustPressedKeys(nil)) > 0
+	in.Cursor = image.Pt(ebiten.CursorPosition())
+	_, in.Wheel = ebiten.Wheel()
+	return in
+}
+
+// Held reports whether the action is held down
+func (in *Input) Held(a Action) bool {
+	return in.ticks[a] > 0
+}
+
+// JustPressed reports whether the action was set off this tick
+func (in *Input) JustPressed(a Action) bool {
+	return in.just[a]
+}
+
+// Repeated reports whether the action was set off this tick, counting the
+// repeats of a held key: the first after keyRepeatDelay ticks, then one
+// every keyRepeatInterval
+func (in *Input) Repeated(a Action) bool {
+	held := in.ticks[a] - keyRepeatDelay
+	return in.just[a] || held >= 0 && held%max(1, keyRepeatInterval) == 0
+}
+
+// Pressed reports whether any key or button went down this tick
+func (in *Input) Pressed() bool {
+	return in.pressed
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+// AIState is the behaviour a monster is currently following
+type AIState int
+
+const (
+	AIPatrol      AIState = iota // Walk the precomputed patrol route
+	AIChase                      // Hunt the player down
+	AIFlee                       // Run away from the player while badly hurt
+	AIReturn                     // Lost the player, heading back to the route
+	AIInvestigate                // Heard a noise and is going to look
+	AIBrawl                      // Fighting a monster of a rival faction
+)
+
+func (s AIState) String() string {
+	switch s {
+	case AIPatrol:
+		return "patrolling"
+	case AIChase:
+		return "chasing"
+	case AIFlee:
+		return "fleeing"
+	case AIReturn:
+		return "returning"
+	case AIInvestigate:
+		return "investigating"
+	case AIBrawl:
+		return "brawling"
+	default:
+		return "unknown"
+	}
+}
+
+// AIProfile holds the aggression parameters of a monster tier
+type AIProfile struct {
+	SightBonus  int // Added to the floor's aggro radius
+	FleePercent int // Flee below this share of max health; 0 never flees
+	ChaseMemory int // Turns a monster keeps chasing after losing sight
+}
+
+// fleeRegen is the health a fleeing monster regains each turn it is out of
+// the player's sight, so it can come back to the fight
+const fleeRegen = 1
+
+var tierProfiles = map[MonsterTier]AIProfile{
+	TierEasy:   {SightBonus: -1, FleePercent: 50, ChaseMemory: 2},
+	TierMedium: {SightBonus: 0, FleePercent: 30, ChaseMemory: 4},
+	TierHard:   {SightBonus: 1, FleePercent: 15, ChaseMemory: 6},
+	TierBoss:   {SightBonus: 2, FleePercent: 0, ChaseMemory: 10},
+}
+
+const (
+	patrolMinDistance = 4  // Closest a patrol waypoint may be to a monster's home
+	patrolMaxDistance = 10 // Furthest a patrol waypoint may be from a monster's home
+	patrolAttempts    = 20 // Waypoint picks before a monster gives up and stands guard
+)
+
+// MonsterAI is the behaviour component of a monster entity
+type MonsterAI struct {
+	State     AIState
+	Route     []Point        // Closed patrol loop; empty for monsters that just wander
+	RouteStep int            // Index of the route point the monster is heading to
+	Target    Point          // Where the last noise it is investigating came from
+	Rival     *MonsterEntity // Monster of another faction it is fighting
+	memory    int            // Turns left to keep chasing or investigating
+}
+
+// planPatrolRoute precomputes an out-and-back route from the monster's home
+// to a random waypoint nearby.
+func (d *Dungeon) planPatrolRoute(m *MonsterEntity) {
+	home := Point{m.X, m.Y}
+	for i := 0; i < patrolAttempts; i++ {
+		wp := Point{
+			home.x + d.rng.Intn(2*patrolMaxDistance+1) - patrolMaxDistance,
+			home.y + d.rng.Intn(2*patrolMaxDistance+1) - patrolMaxDistance,
+		}
+		if !inBounds(wp.x, wp.y, d.Width, d.Height) || d.Cells[wp.y][wp.x].Type != Empty ||
+			abs(wp.x-home.x)+abs(wp.y-home.y) < patrolMinDistance {
+			continue
+		}
+
+		path := d.FindPathBFS(home, wp)
+		if len(path) < 2 || len(path) > 2*patrolMaxDistance {
+			continue
+		}
+
+		// Walk out to the waypoint and back again
+		route := append([]Point(nil), path...)
+		for j := len(path) - 2; j > 0; j-- {
+			route = append(route, path[j])
+		}
+		m.AI.Route = route
+		m.AI.RouteStep = 1
+		return
+	}
+}
+
+// think updates the monster's AI state for this turn, given the noises made
+// since the last one
+func (m *MonsterEntity) think(d *Dungeon, p *Player, noises []Noise) {
+	profile := tierProfiles[d.Cells[m.Y][m.X].MonsterTier]
+	sees := d.CanSee(Point{m.X, m.Y}, Point{p.X, p.Y}, d.AggroRadius(p)+profile.SightBonus) ||
+		(m.Hunter && d.AlarmActive())
+	noise, heard := m.hears(noises)
+	rival := d.nearestRival(m)
+
+	// A fleeing monster licks its wounds once it has lost the player
+	if m.AI.State == AIFlee && !sees {
+		m.Health = min(m.MaxHealth, m.Health+fleeRegen)
+	}
+
+	switch {
+	case profile.FleePercent > 0 && m.Health*100 < m.MaxHealth*profile.FleePercent:
+		m.AI.State = AIFlee
+	case sees:
+		m.AI.State = AIChase
+		m.AI.memory = profile.ChaseMemory
+	case rival != nil && m.AI.State != AIChase:
+		// Rival factions fight on sight unless the player is the bigger threat
+		m.AI.State = AIBrawl
+		m.AI.Rival = rival
+	case m.AI.State == AIBrawl:
+		m.AI.State = AIReturn
+	case heard && m.AI.State != AIChase:
+		m.AI.State = AIInvestigate
+		m.AI.Target = noise
+		m.AI.memory = 2 * profile.ChaseMemory
+	case m.AI.State == AIInvestigate:
+		m.AI.memory--
+		if m.AI.memory <= 0 || (m.X == m.AI.Target.x && m.Y == m.AI.Target.y) {
+			m.AI.State = AIReturn
+		}
+	case m.AI.State == AIChase:
+		m.AI.memory--
+		if m.AI.memory <= 0 {
+			m.AI.State = AIReturn
+		}
+	case m.AI.State == AIFlee:
+		// Healed past the flee threshold out of sight - head home
+		m.AI.State = AIReturn
+	}
+}
+
+// Aware reports whether the monster has noticed the player
+func (m *MonsterEntity) Aware() bool {
+	return m.AI.State == AIChase || m.AI.State == AIFlee
+}
+
+// act moves the monster according to its current state, drawing any random
+// decisions from the AI stream
+func (m *MonsterEntity) act(d *Dungeon, p *Player, ai *rand.Rand) {
+	switch m.AI.State {
+	case AIChase:
+		m.stepTowards(d, p, Point{p.X, p.Y})
+	case AIFlee:
+		m.stepAway(d, p)
+	case AIInvestigate:
+		m.stepTowards(d, p, m.AI.Target)
+	case AIBrawl:
+		m.stepTowards(d, p, Point{m.AI.Rival.X, m.AI.Rival.Y})
+	case AIPatrol, AIReturn:
+		if len(m.AI.Route) == 0 {
+			if m.Boss == nil {
+				m.wander(d, p, ai)
+			}
+			m.AI.State = AIPatrol
+			return
+		}
+
+		target := m.AI.Route[m.AI.RouteStep]
+		if m.X == target.x && m.Y == target.y {
+			// Reached the route: carry on patrolling from here
+			m.AI.State = AIPatrol
+			m.AI.RouteStep = (m.AI.RouteStep + 1) % len(m.AI.Route)
+			target = m.AI.Route[m.AI.RouteStep]
+		}
+		m.stepTowards(d, p, target)
+	}
+}
+
+// stepTowards moves one tile along the shortest path to target, if that tile is free
+func (m *MonsterEntity) stepTowards(d *Dungeon, p *Player, target Point) {
+	path := d.FindPath(Point{m.X, m.Y}, target)
+	if len(path) > 1 && d.canMonsterEnter(path[1], p) {
+		d.moveMonster(m, path[1])
+	}
+}
+
+// stepAway moves to the free neighbouring tile furthest from the player
+func (m *MonsterEntity) stepAway(d *Dungeon, p *Player) {
+	best := Point{m.X, m.Y}
+	bestDist := (m.X-p.X)*(m.X-p.X) + (m.Y-p.Y)*(m.Y-p.Y)
+	for _, dir := range monsterDirs {
+		next := Point{m.X + dir.x, m.Y + dir.y}
+		if !d.canMonsterEnter(next, p) {
+			continue
+		}
+		if dist := (next.x-p.X)*(next.x-p.X) + (next.y-p.Y)*(next.y-p.Y); dist > bestDist {
+			best, bestDist = next, dist
+		}
+	}
+	if best.x != m.X || best.y != m.Y {
+		d.moveMonster(m, best)
+	}
+}
+
+// wander takes a random step, used by monsters without a patrol route
+func (m *MonsterEntity) wander(d *Dungeon, p *Player, ai *rand.Rand) {
+	if ai.Intn(100) >= wanderChance {
+		return
+	}
+	dir := monsterDirs[ai.Intn(len(monsterDirs))]
+	next := Point{m.X + dir.x, m.Y + dir.y}
+	if d.canMonsterEnter(next, p) {
+		d.moveMonster(m, next)
+	}
+}
+
+// DrawAwareness marks visible monsters that are investigating (?) or have
+// spotted the player (!)
+func (d *Dungeon) DrawAwareness(screen *ebiten.Image, player *Player) {
+	radius := player.EffectiveFOVRadius(d)
+	for _, m := range d.Monsters {
+		if player.FOVEnabled && !d.CanSee(Point{player.X, player.Y}, Point{m.X, m.Y}, radius) {
+			continue
+		}
+		mark := ""
+		switch {
+		case m.Aware():
+			mark = "!"
+		case m.AI.State == AIInvestigate:
+			mark = "?"
+		default:
+			continue
+		}
+		ui.DrawText(screen, mark, m.X*tileSize+tileSize/2-3, m.Y*tileSize-14)
+	}
+}
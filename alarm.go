@@ -0,0 +1,109 @@
+package main
+
+const (
+	alarmDuration     = 60 // Quiet world turns before an alarm dies down
+	alarmAggroBonus   = 4  // Extra notice range while the alarm is raised
+	hunterSquadSize   = 3
+	shriekerChance    = 10 // Percent of monsters that shriek when killed
+	lockedChestChance = 20 // Percent of treasures behind a lock
+)
+
+// AlarmActive reports whether the floor is currently on alert
+func (d *Dungeon) AlarmActive() bool {
+	return d.AlarmTurns > 0
+}
+
+// AggroRadius returns how far monsters on this floor notice the player. A
+// player carries their light with them, so monsters see them as far as it
+// reaches; a dim light or sneaking lets the player get closer.
+func (d *Dungeon) AggroRadius(p *Player) int {
+	radius := p.EffectiveFOVRadius(d)
+	if p.Sneaking {
+		radius /= 2
+	}
+	if d.AlarmActive() {
+		radius += alarmAggroBonus
+	}
+	return radius
+}
+
+// RaiseAlarm puts the floor on alert, or extends an ongoing alert. A fresh
+// alarm sends a hunter squad to the entrance; it returns how many hunters
+// arrived. The tile at avoid (the player) is never used for a hunter.
+// Hunters are drawn from the run's spawn stream, so a floor's generation
+// stream is left as it was when the floor was built.
+func (d *Dungeon) RaiseAlarm(avoid Point, rng *RNG) int {
+	wasActive := d.AlarmActive()
+	d.AlarmTurns = alarmDuration
+	if wasActive {
+		return 0
+	}
+
+	entrance := Point{d.Entrance[0], d.Entrance[1]}
+	spots := d.nearestEmptyCells(entrance, hunterSquadSize, avoid)
+	spawn := rng.Stream(StreamSpawn)
+	for _, p := range spots {
+		level := d.Level + 1
+		tier := monsterTierForLevel(level)
+		d.Cells[p.y][p.x] = Cell{Type: Monster, InteractionLevel: level, MonsterTier: tier,
+			Species: pickSpecies(tier, d.Theme.Name, spawn)}
+		hunter := d.addMonster(p.x, p.y)
+		hunter.Hunter = true
+		hunter.AI.State = AIChase
+	}
+	return len(spots)
+}
+
+// soundAlarm raises the floor alarm after a loud action and reports it in the message log
+func soundAlarm(d *Dungeon, h *InteractionHandler, player *Player) {
+	if hunters := d.RaiseAlarm(Point{player.X, player.Y}, h.RNG); hunters > 0 {
+		msg := tr("An alarm rings out! %d hunters gather at the entrance.", hunters)
+		h.Post(MsgSystem, SeverityWarning, msg)
+		h.Log.Add(LogEntry{Tick: h.Clock.Ticks, Kind: LogEvent, Text: msg})
+	} else {
+		h.Post(MsgSystem, SeverityWarning, tr("The alarm keeps ringing..."))
+	}
+}
+
+// TickAlarm counts down one quiet world turn, reporting whether the alarm just ended
+func (d *Dungeon) TickAlarm() bool {
+	if d.AlarmTurns == 0 {
+		return false
+	}
+	d.AlarmTurns--
+	return d.AlarmTurns == 0
+}
+
+// nearestEmptyCells walks outwards from start and returns up to n empty cells,
+// skipping start itself and the avoid point.
+func (d *Dungeon) nearestEmptyCells(start Point, n int, avoid Point) []Point {
+	visited := make([][]bool, d.Height)
+	for i := range visited {
+		visited[i] = make([]bool, d.Width)
+	}
+	visited[start.y][start.x] = true
+
+	dirs := []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	queue := []Point{start}
+	var found []Point
+
+	for len(queue) > 0 && len(found) < n {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, dir := range dirs {
+			next := Point{current.x + dir.x, current.y + dir.y}
+			if !inBounds(next.x, next.y, d.Width, d.Height) || visited[next.y][next.x] ||
+				d.Cells[next.y][next.x].Type == Wall {
+				continue
+			}
+			visited[next.y][next.x] = true
+			queue = append(queue, next)
+
+			if d.Cells[next.y][next.x].Type == Empty && next != avoid && len(found) < n {
+				found = append(found, next)
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// animTicks is the animation clock. It counts every tick of a run, paused or
+// not, so the dungeon keeps moving while the world waits.
+var animTicks int
+
+// TileAnimation brings a kind of tile to life by cycling its sprite through
+// frames and making its color pulse
+type TileAnimation struct {
+	Frames     []int   // Sprites shown in turn; empty keeps the tile's own sprite
+	FrameTicks int     // Ticks each frame is shown
+	Pulse      float64 // How far brightness dips at the low of a pulse; 0 for none
+	PulseTicks int     // Ticks of one pulse
+}
+
+// tileAnimations are keyed by the sprite the tile would show standing still
+var tileAnimations = map[int]TileAnimation{
+	spriteTorch:      {Frames: []int{spriteTorch, spriteTorch2, spriteTorch3}, FrameTicks: 7, Pulse: 0.15, PulseTicks: 23},
+	spriteFountain:   {Frames: []int{spriteFountain, spriteFountain2, spriteFountain3}, FrameTicks: 14},
+	spriteStairsDown: {Pulse: 0.45, PulseTicks: 70},
+	spriteShrine:     {Pulse: 0.2, PulseTicks: 110},
+
+	// Monsters breathe and sway while they wait, the stronger ones more
+	// restlessly
+	spriteMonsterEasy:   {Frames: []int{spriteMonsterEasy, spriteMonsterEasy2, spriteMonsterEasy, spriteMonsterEasy3}, FrameTicks: 20},
+	spriteMonsterMedium: {Frames: []int{spriteMonsterMedium, spriteMonsterMedium2, spriteMonsterMedium, spriteMonsterMedium3}, FrameTicks: 17},
+	spriteMonsterHard:   {Frames: []int{spriteMonsterHard, spriteMonsterHard2, spriteMonsterHard, spriteMonsterHard3}, FrameTicks: 14},
+	spriteMonsterBoss:   {Frames: []int{spriteMonsterBoss, spriteMonsterBoss2, spriteMonsterBoss, spriteMonsterBoss3}, FrameTicks: 11},
+}
+
+// animate returns the sprite and color the tile at (x, y) shows at this
+// point of the animation clock. Each tile is put out of step by its
+// position, so torches don't flicker in unison.
+func animate(sprite, x, y int, clr color.RGBA) (int, color.RGBA) {
+	a, ok := tileAnimations[sprite]
+	if !ok {
+		return sprite, clr
+	}
+	t := animTicks + 7*x + 13*y
+	if len(a.Frames) > 0 {
+		sprite = a.Frames[t/a.FrameTicks%len(a.Frames)]
+	}
+	if a.Pulse > 0 {
+		wave := 0.5 + 0.5*math.Cos(2*math.Pi*float64(t%a.PulseTicks)/float64(a.PulseTicks))
+		clr = shadeColor(clr, 1-a.Pulse*(1-wave))
+	}
+	return sprite, clr
+}
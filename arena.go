@@ -0,0 +1,103 @@
+package main
+
+import "fmt"
+
+const (
+	arenaOpenness    = 85 // Percent of maze walls knocked through on the arena floor
+	arenaFirstWave   = 10 // World turns of calm before the first wave
+	arenaWaveTurns   = 45 // World turns between waves
+	arenaBaseWave    = 3  // Monsters in the first wave
+	arenaWaveGrowth  = 2  // Extra monsters each later wave brings
+	arenaLevelEvery  = 2  // Waves between rises in monster level
+	arenaPotionEvery = 2  // Waves between healing potions appearing
+)
+
+// Arena tracks an endless horde run: one open floor, no exit, and waves of
+// monsters that keep coming until the player falls.
+type Arena struct {
+	Wave      int
+	StartTick int
+	EndTick   int // Tick the player fell on; 0 while still alive
+	timer     int
+}
+
+func NewArena(startTick int) *Arena {
+	return &Arena{StartTick: startTick, timer: arenaFirstWave}
+}
+
+// NewArenaSpec builds the spec of the single arena floor
+func NewArenaSpec(seed int64, level, width, height int) FloorSpec {
+	spec := NewFloorSpec(seed, level, width, height)
+	spec.Modifier = ModifierNone
+	spec.Openness = arenaOpenness
+	spec.Arena = true
+	return spec
+}
+
+// Multiplier is what score gains are multiplied by during the current wave
+func (a *Arena) Multiplier() int {
+	return max(1, a.Wave)
+}
+
+// SurvivalTicks is how long the player has lasted in the arena
+func (a *Arena) SurvivalTicks(now int) int {
+	if a.EndTick > 0 {
+		now = a.EndTick
+	}
+	return now - a.StartTick
+}
+
+// SurvivalTime formats the survival time as mm:ss
+func (a *Arena) SurvivalTime(now int) string {
+	return formatSurvival(a.SurvivalTicks(now))
+}
+
+// formatSurvival formats a survival time in ticks as mm:ss
+func formatSurvival(ticks int) string {
+	secs := ticks / ticksPerSecond
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
+
+// updateArena counts down to the next wave, calling it early once the
+// current one is wiped out
+func (g *Game) updateArena() {
+	a := g.arena
+	a.timer--
+	if a.timer > 0 && (a.Wave == 0 || len(g.dungeon.Monsters) > 0) {
+		return
+	}
+	a.timer = arenaWaveTurns
+	a.Wave++
+	g.interactionHandler.ScoreMultiplier = a.Multiplier()
+
+	d, p := g.dungeon, g.player
+	rng := g.rng.Stream(StreamSpawn)
+	level := d.Level + (a.Wave-1)/arenaLevelEvery
+	spawned := 0
+	for i := 0; i < arenaBaseWave+(a.Wave-1)*arenaWaveGrowth && len(d.Monsters) < maxFloorMonsters; i++ {
+		spot, ok := d.pickSpawnPoint(p, rng)
+		if !ok {
+			break
+		}
+		monsterLevel := max(1, level+rng.Intn(3)-1)
+		tier := monsterTierForLevel(monsterLevel)
+		d.Cells[spot.y][spot.x] = Cell{Type: Monster, InteractionLevel: monsterLevel, MonsterTier: tier,
+			Species: pickSpecies(tier, d.Theme.Name, rng)}
+		if rng.Intn(100) < eliteChance {
+			d.Cells[spot.y][spot.x].Elite = EliteAffix(1 + rng.Intn(int(numAffixes)-1))
+		}
+		d.addMonster(spot.x, spot.y)
+		spawned++
+	}
+
+	if a.Wave%arenaPotionEvery == 0 {
+		if spot, ok := d.pickSpawnPoint(p, rng); ok {
+			d.Cells[spot.y][spot.x] = NewTreasureCell(10*level, TreasurePotion)
+		}
+	}
+
+	// The horn calls the whole wave straight to the player
+	d.MakeNoise(Point{p.X, p.Y}, d.Width+d.Height)
+	g.interactionHandler.Record(LogEvent,
+		tr("Wave %d: %d monsters pour in! Score x%d", a.Wave, spawned, a.Multiplier()), 0)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// bindingsFile stores the player's rebound keys between sessions
+const bindingsFile = "keybindings.json"
+
+// Action is something the player does in the dungeon with a rebindable key
+type Action int
+
+const (
+	ActionMoveUp Action = iota
+	ActionMoveDown
+	ActionMoveLeft
+	ActionMoveRight
+	ActionInteract
+	ActionToggleFOV
+	ActionInventory
+	ActionCharacter
+	ActionQuestLog
+	ActionCombatLog
+	ActionMessages
+	ActionSneak
+	ActionPause
+	ActionDebug
+	ActionHelp
+	ActionZoomIn
+	ActionZoomOut
+	ActionGrid
+	ActionRecenter
+	ActionLook
+	ActionLoot
+	ActionHotbar1
+	ActionHotbar2
+	ActionHotbar3
+	ActionHotbar4
+	ActionHotbar5
+	numActions
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionMoveUp:
+		return "Move Up"
+	case ActionMoveDown:
+		return "Move Down"
+	case ActionMoveLeft:
+		return "Move Left"
+	case ActionMoveRight:
+		return "Move Right"
+	case ActionInteract:
+		return "Interact"
+	case ActionToggleFOV:
+		return "Toggle FOV"
+	case ActionInventory:
+		return "Inventory"
+	case ActionCharacter:
+		return "Character"
+	case ActionQuestLog:
+		return "Quest Log"
+	case ActionCombatLog:
+		return "Combat Log"
+	case ActionMessages:
+		return "Messages"
+	case ActionSneak:
+		return "Sneak"
+	case ActionPause:
+		return "Pause"
+	case ActionDebug:
+		return "Debug Overlay"
+	case ActionHelp:
+		return "Help"
+	case ActionZoomIn:
+		return "Zoom In"
+	case ActionZoomOut:
+		return "Zoom Out"
+	case ActionGrid:
+		return "Grid"
+	case ActionRecenter:
+		return "Center View"
+	case ActionLook:
+		return "Look"
+	case ActionLoot:
+		return "Go to Loot"
+	case ActionHotbar1:
+		return "Hotbar 1"
+	case ActionHotbar2:
+		return "Hotbar 2"
+	case ActionHotbar3:
+		return "Hotbar 3"
+	case ActionHotbar4:
+		return "Hotbar 4"
+	case ActionHotbar5:
+		return "Hotbar 5"
+	default:
+		return "Unknown"
+	}
+}
+
+// KeyBindings maps every action to the key that triggers it
+type KeyBindings [numActions]ebiten.Key
+
+var defaultBindings = KeyBindings{
+	ActionMoveUp:    ebiten.KeyArrowUp,
+	ActionMoveDown:  ebiten.KeyArrowDown,
+	ActionMoveLeft:  ebiten.KeyArrowLeft,
+	ActionMoveRight: ebiten.KeyArrowRight,
+	ActionInteract:  ebiten.KeyE,
+	ActionToggleFOV: ebiten.KeyF,
+	ActionInventory: ebiten.KeyI,
+	ActionCharacter: ebiten.KeyC,
+	ActionQuestLog:  ebiten.KeyQ,
+	ActionCombatLog: ebiten.KeyL,
+	ActionMessages:  ebiten.KeyM,
+	ActionSneak:     ebiten.KeyS,
+	ActionPause:     ebiten.KeyP,
+	ActionDebug:     ebiten.KeyF3,
+	ActionHelp:      ebiten.KeyH,
+	ActionZoomIn:    ebiten.KeyEqual,
+	ActionZoomOut:   ebiten.KeyMinus,
+	ActionGrid:      ebiten.KeyG,
+	ActionRecenter:  ebiten.KeyV,
+	ActionLook:      ebiten.KeyX,
+	ActionLoot:      ebiten.KeyT,
+	ActionHotbar1:   ebiten.Key1,
+	ActionHotbar2:   ebiten.Key2,
+	ActionHotbar3:   ebiten.Key3,
+	ActionHotbar4:   ebiten.Key4,
+	ActionHotbar5:   ebiten.Key5,
+}
+
+// bindings are the keys in effect, defaults overlaid by the bindings file
+var bindings = defaultBindings
+
+// Key returns the key bound to an action, or for a fixed action the first
+// key that sets it off
+func (b *KeyBindings) Key(a Action) ebiten.Key {
+	if a < numActions {
+		return b[a]
+	}
+	for _, t := range fixedTriggers[a] {
+		if t.kind == triggerKey {
+			return t.key
+		}
+	}
+	return ebiten.KeyEscape
+}
+
+// Bind sets the key of an action. An action already on that key swaps to
+// the old key, so no two actions ever share one.
+func (b *KeyBindings) Bind(a Action, key ebiten.Key) {
+	for other := range b {
+		if b[other] == key {
+			b[other] = b[a]
+		}
+	}
+	b[a] = key
+}
+
+// loadBindings reads the bindings file over the defaults. A missing file is
+// not an error; actions it leaves out keep their default keys.
+func loadBindings() (KeyBindings, error) {
+	b := defaultBindings
+	file := configPath(bindingsFile)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return b, err
+	}
+
+	var saved map[string]ebiten.Key
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return b, fmt.Errorf("parse %s: %w", file, err)
+	}
+	for a := Action(0); a < numActions; a++ {
+		if key, ok := saved[a.String()]; ok {
+			b.Bind(a, key)
+		}
+	}
+	return b, nil
+}
+
+// saveBindings writes the bindings file, keyed by action name
+func saveBindings(b KeyBindings) error {
+	saved := make(map[string]ebiten.Key, numActions)
+	for a := Action(0); a < numActions; a++ {
+		saved[a.String()] = b[a]
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath(bindingsFile), data, 0o644)
+}
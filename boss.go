@@ -0,0 +1,116 @@
+package main
+
+const (
+	bossFloorInterval    = 3 // Every third floor has a boss guarding the exit
+	bossLevelBonus       = 2 // Boss level above the floor level
+	bossHealthMultiplier = 3
+	bossAreaRadius       = 2 // Tiles an area attack reaches
+	bossAreaInterval     = 3 // World turns between area attacks
+	bossEnragePercent    = 50
+)
+
+// BossPhase is one stage of a boss fight. A boss enters the phase once its
+// health drops to HealthPercent of its maximum.
+type BossPhase struct {
+	Name          string
+	HealthPercent int
+	SummonAdds    int  // Minions summoned on entering the phase
+	Enraged       bool // Blows deal bossEnragePercent more damage
+	AreaAttack    bool // Periodically hits everything around the boss
+}
+
+var bossPhases = []BossPhase{
+	{Name: "Awakened", HealthPercent: 100},
+	{Name: "Summoning", HealthPercent: 66, SummonAdds: 2},
+	{Name: "Enraged", HealthPercent: 33, Enraged: true, AreaAttack: true},
+}
+
+// BossState is attached to monster entities that are bosses
+type BossState struct {
+	Name      string
+	Phase     int // Index into bossPhases
+	areaTimer int // World turns until the next area attack
+}
+
+// CurrentPhase returns the phase the boss is in
+func (b *BossState) CurrentPhase() BossPhase {
+	return bossPhases[b.Phase]
+}
+
+// placeBoss puts a boss next to the exit on boss floors
+func (d *Dungeon) placeBoss() {
+	if d.Level%bossFloorInterval != 0 {
+		return
+	}
+	spots := d.nearestEmptyCells(Point{d.Exit[0], d.Exit[1]}, 1, Point{d.Entrance[0], d.Entrance[1]})
+	if len(spots) == 0 {
+		return
+	}
+
+	p := spots[0]
+	species := pickSpecies(TierBoss, d.Theme.Name, d.rng)
+	d.Cells[p.y][p.x] = Cell{Type: Monster, InteractionLevel: d.Level + bossLevelBonus,
+		MonsterTier: TierBoss, Species: species}
+	boss := d.addMonster(p.x, p.y)
+	boss.Boss = &BossState{Name: tr("%s Lord", species.Title())}
+	boss.resetHealth(d.Cells[p.y][p.x])
+}
+
+// ActiveBoss returns the boss the player is currently fighting, or nil
+func (d *Dungeon) ActiveBoss() *MonsterEntity {
+	for _, m := range d.Monsters {
+		if m.Boss != nil && (m.AI.State == AIChase || m.Health < m.MaxHealth) {
+			return m
+		}
+	}
+	return nil
+}
+
+// updateBoss moves a boss into later phases as it is hurt and runs the
+// behaviour of its current phase.
+func (g *Game) updateBoss(m *MonsterEntity) {
+	b := m.Boss
+	for b.Phase+1 < len(bossPhases) && m.Health*100 <= m.MaxHealth*bossPhases[b.Phase+1].HealthPercent {
+		b.Phase++
+		phase := b.CurrentPhase()
+		g.interactionHandler.Record(LogCombat, tr("The %s enters its %s phase!", b.Name, tr(phase.Name)), 0)
+		if phase.SummonAdds > 0 {
+			g.summonAdds(m, phase.SummonAdds)
+		}
+	}
+
+	if !b.CurrentPhase().AreaAttack {
+		return
+	}
+	if b.areaTimer > 0 {
+		b.areaTimer--
+		return
+	}
+	b.areaTimer = bossAreaInterval - 1
+	if isWithinFOV(g.player.X, g.player.Y, m.X, m.Y, bossAreaRadius) {
+		fight := NewMonsterInteraction(g.dungeon.Cells[m.Y][m.X], m)
+		damage := fight.Blow(g.player)
+		g.damagePlayer(fight, damage)
+		g.stopTravel()
+		g.interactionHandler.Record(LogCombat,
+			tr("The %s unleashes a shockwave for %d damage!", b.Name, damage), -damage)
+	}
+}
+
+// summonAdds calls minions onto the tiles around a boss
+func (g *Game) summonAdds(m *MonsterEntity, n int) {
+	d := g.dungeon
+	level := max(1, d.Level-1)
+	tier := monsterTierForLevel(level)
+	// Minions come from the boss's own faction so they don't turn on it
+	species := d.Cells[m.Y][m.X].Species.Info().Summons
+	spots := d.nearestEmptyCells(Point{m.X, m.Y}, n, Point{g.player.X, g.player.Y})
+	for _, p := range spots {
+		d.Cells[p.y][p.x] = Cell{Type: Monster, InteractionLevel: level, MonsterTier: tier, Species: species}
+		add := d.addMonster(p.x, p.y)
+		add.AI.State = AIChase
+	}
+	if len(spots) > 0 {
+		g.interactionHandler.Record(LogCombat, tr("The %s summons %d minions!", m.Boss.Name, len(spots)), 0)
+	}
+}
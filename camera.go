@@ -0,0 +1,261 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// cameraDeadZone is how far the player may wander from the middle of the
+	// view before the camera follows, as a fraction of the view's size
+	cameraDeadZone = 0.25
+	cameraPanSpeed = 4 // Screen pixels the view pans per tick with Shift + move keys
+
+	minZoom  = 0.5
+	maxZoom  = 3.0
+	zoomStep = 1.25 // Zoom factor of one key press or wheel notch
+	zoomEase = 0.3  // Share of the way to the zoom goal covered per tick, on a log scale
+
+	// A new floor starts with the camera gliding over to the exit and back,
+	// so the player knows where they are headed
+	exitTourTicks = 100 // Ticks the camera spends on the tour
+	exitTourHold  = 60  // Of those, ticks it stays pointed at the exit
+)
+
+// Camera is the part of the dungeon shown on screen. Everything drawn in
+// dungeon space goes through its transform, so a floor larger than the
+// window scrolls with the player instead of being clipped, and can be
+// zoomed in or out whatever the tile size. The view eases towards where the
+// camera wants to be rather than jumping there, and towards a new zoom level
+// the same way.
+type Camera struct {
+	View       image.Rectangle // Screen area the dungeon is drawn in
+	X, Y       float64         // Dungeon pixel the camera wants at the top-left of the view
+	PanX, PanY float64         // Offset added by panning and zooming at the cursor; cleared when the player moves
+	Zoom       float64         // Screen pixels per dungeon pixel
+	zoomGoal   float64         // Zoom the camera is easing towards
+	zoomX      float64         // Screen position that stays put while zooming
+	zoomY      float64
+	Easing     float64 // Fraction of the way to its goal the view moves per tick; 1 jumps straight there
+	Still      bool    // Reduced motion: always jump, and skip the exit tour
+	shownX     float64 // Dungeon pixel at the top-left of the view as drawn
+	shownY     float64
+	followX    int // Tile the camera last followed
+	followY    int
+	snap       bool  // Jump to the goal on the next Follow, as on a new floor
+	tour       Point // Tile the exit tour looks at
+	tourTicks  int   // Ticks left of the exit tour; 0 when not touring
+}
+
+// NewCamera returns a camera at normal zoom that eases at the given rate
+func NewCamera(easing float64) Camera {
+	return Camera{Zoom: 1, zoomGoal: 1, Easing: easing, snap: true}
+}
+
+// EnterFloor puts the camera straight on the player of a new floor
+func (c *Camera) EnterFloor() {
+	c.PanX, c.PanY = 0, 0
+	c.snap = true
+	c.tourTicks = 0
+}
+
+// TourTo glides the camera over to the tile at (tx, ty) and back, motion
+// allowing
+func (c *Camera) TourTo(tx, ty int) {
+	if !c.Still {
+		c.tour, c.tourTicks = Point{tx, ty}, exitTourTicks
+	}
+}
+
+// Follow keeps the tile at (tx, ty) inside the dead zone in the middle of
+// view, scrolling no further than the dungeon's edges. A dungeon smaller than
+// the view stays in its top-left corner. It runs every tick, moving the view
+// a step closer to where the camera wants to be.
+func (c *Camera) Follow(view image.Rectangle, tx, ty int, d *Dungeon) {
+	c.View, c.followX, c.followY = view, tx, ty
+	if c.Zoom != c.zoomGoal {
+		zoom := c.Zoom * math.Pow(c.zoomGoal/c.Zoom, zoomEase)
+		if math.Abs(zoom/c.zoomGoal-1) < 0.002 {
+			zoom = c.zoomGoal
+		}
+		c.zoomTo(zoom, d)
+	}
+	c.track(d)
+	goalX, goalY := c.X+c.PanX, c.Y+c.PanY
+	if c.snap {
+		c.shownX, c.shownY, c.snap = goalX, goalY, false
+	}
+
+	// During the tour the exit is centered instead, once the tour has had
+	// time to get there and until it is time to head back
+	if c.tourTicks > 0 {
+		if c.tourTicks > exitTourTicks-exitTourHold {
+			viewW, viewH := c.viewSize()
+			goalX = clampCamera(float64(c.tour.x*tileSize+tileSize/2)-viewW/2, viewW, float64(d.Width*tileSize))
+			goalY = clampCamera(float64(c.tour.y*tileSize+tileSize/2)-viewH/2, viewH, float64(d.Height*tileSize))
+		}
+		c.tourTicks--
+	}
+
+	ease := c.Easing
+	if c.Still || ease <= 0 || ease > 1 {
+		ease = 1
+	}
+	c.shownX += (goalX - c.shownX) * ease
+	c.shownY += (goalY - c.shownY) * ease
+}
+
+// StopTour ends the exit tour early, as when the player starts moving
+func (c *Camera) StopTour() {
+	c.tourTicks = 0
+}
+
+// track works out where the camera wants to be for the tile it follows
+func (c *Camera) track(d *Dungeon) {
+	tx, ty := c.followX, c.followY
+	viewW, viewH := c.viewSize()
+	worldW, worldH := float64(d.Width*tileSize), float64(d.Height*tileSize)
+	c.X = followAxis(c.X, float64(tx*tileSize+tileSize/2), viewW, worldW)
+	c.Y = followAxis(c.Y, float64(ty*tileSize+tileSize/2), viewH, worldH)
+
+	// Panning can look around but not past the edges either
+	c.PanX = clampCamera(c.X+c.PanX, viewW, worldW) - c.X
+	c.PanY = clampCamera(c.Y+c.PanY, viewH, worldH) - c.Y
+}
+
+// viewSize is the size of the view in dungeon pixels
+func (c *Camera) viewSize() (w, h float64) {
+	return float64(c.View.Dx()) / c.Zoom, float64(c.View.Dy()) / c.Zoom
+}
+
+// followAxis moves the camera along one axis just far enough to bring target
+// back inside the dead zone
+func followAxis(pos, target, view, world float64) float64 {
+	inset := view * cameraDeadZone
+	if target < pos+inset {
+		pos = target - inset
+	} else if target > pos+view-inset {
+		pos = target - view + inset
+	}
+	return clampCamera(pos, view, world)
+}
+
+// clampCamera keeps a camera position along one axis within the dungeon
+func clampCamera(pos, view, world float64) float64 {
+	if world <= view {
+		return 0
+	}
+	return max(0, min(pos, world-view))
+}
+
+// Panned tells whether the view has been moved off the player
+func (c *Camera) Panned() bool {
+	return c.PanX != 0 || c.PanY != 0
+}
+
+// Recenter drops any panning, so the view eases back to the player
+func (c *Camera) Recenter() {
+	c.PanX, c.PanY = 0, 0
+}
+
+// Reveal pans the view just far enough to bring the tile at (tx, ty) into it
+func (c *Camera) Reveal(tx, ty int) {
+	x, y := c.WorldToScreen(tx, ty)
+	size := c.TileSize()
+	view := c.View
+	if over := x + size - float64(view.Max.X); over > 0 {
+		c.PanX += over / c.Zoom
+	} else if under := x - float64(view.Min.X); under < 0 {
+		c.PanX += under / c.Zoom
+	}
+	if over := y + size - float64(view.Max.Y); over > 0 {
+		c.PanY += over / c.Zoom
+	} else if under := y - float64(view.Min.Y); under < 0 {
+		c.PanY += under / c.Zoom
+	}
+}
+
+// Pan scrolls the view by the given number of screen pixels
+func (c *Camera) Pan(dx, dy int) {
+	c.PanX += float64(dx) / c.Zoom
+	c.PanY += float64(dy) / c.Zoom
+	c.tourTicks = 0
+}
+
+// ZoomAt multiplies the zoom by factor, within its limits, keeping the
+// dungeon pixel under the screen position (sx, sy) where it is. The zoom
+// eases over to the new level over the next few ticks, or jumps there with
+// reduced motion.
+func (c *Camera) ZoomAt(factor, sx, sy float64, d *Dungeon) {
+	goal := max(minZoom, min(c.zoomGoal*factor, maxZoom))
+	if goal == c.zoomGoal {
+		return
+	}
+	c.zoomGoal, c.zoomX, c.zoomY = goal, sx, sy
+	c.tourTicks = 0
+	if c.Still {
+		c.zoomTo(goal, d)
+	}
+}
+
+// zoomTo sets the zoom, keeping the dungeon pixel under the zoom's anchor
+// on screen where it is
+func (c *Camera) zoomTo(zoom float64, d *Dungeon) {
+	offX, offY := c.zoomX-float64(c.View.Min.X), c.zoomY-float64(c.View.Min.Y)
+	ox, oy := c.origin()
+	anchorX, anchorY := ox+offX/c.Zoom, oy+offY/c.Zoom
+	c.Zoom = zoom
+
+	// Let the camera settle for the new view size, then pan the anchor back
+	// under the cursor. The view jumps there so the anchor doesn't drift.
+	c.PanX, c.PanY = 0, 0
+	c.track(d)
+	c.PanX = anchorX - offX/c.Zoom - c.X
+	c.PanY = anchorY - offY/c.Zoom - c.Y
+	c.track(d)
+	c.shownX, c.shownY = c.X+c.PanX, c.Y+c.PanY
+}
+
+// origin is the dungeon pixel at the top-left corner of the view as drawn
+func (c *Camera) origin() (x, y float64) {
+	return c.shownX, c.shownY
+}
+
+// TileSize is the size tiles are shown at on screen
+func (c *Camera) TileSize() float64 {
+	return float64(tileSize) * c.Zoom
+}
+
+// WorldToScreen returns the screen position of the top-left of tile (tx, ty)
+func (c *Camera) WorldToScreen(tx, ty int) (x, y float64) {
+	ox, oy := c.origin()
+	return float64(c.View.Min.X) + (float64(tx*tileSize)-ox)*c.Zoom,
+		float64(c.View.Min.Y) + (float64(ty*tileSize)-oy)*c.Zoom
+}
+
+// ScreenToTile returns the tile under the screen position (x, y), or false
+// when it is outside the view
+func (c *Camera) ScreenToTile(x, y int) (tx, ty int, ok bool) {
+	if !image.Pt(x, y).In(c.View) {
+		return -1, -1, false
+	}
+	ox, oy := c.origin()
+	wx := ox + float64(x-c.View.Min.X)/c.Zoom
+	wy := oy + float64(y-c.View.Min.Y)/c.Zoom
+	if wx < 0 || wy < 0 {
+		return -1, -1, false
+	}
+	return int(math.Floor(wx)) / tileSize, int(math.Floor(wy)) / tileSize, true
+}
+
+// GeoM is the transform from dungeon pixels to the screen
+func (c *Camera) GeoM() ebiten.GeoM {
+	var m ebiten.GeoM
+	ox, oy := c.origin()
+	m.Translate(-ox, -oy)
+	m.Scale(c.Zoom, c.Zoom)
+	m.Translate(float64(c.View.Min.X), float64(c.View.Min.Y))
+	return m
+}
@@ -0,0 +1,41 @@
+package main
+
+// ticksPerSecond matches ebiten's default fixed update rate (TPS)
+const ticksPerSecond = 60
+
+// GameClock counts fixed-timestep simulation ticks. Anything that expires over
+// time (messages, status effects, cooldowns) is measured against it instead of
+// the wall clock, so pausing the clock pauses all of them together.
+type GameClock struct {
+	Ticks  int
+	Paused bool
+}
+
+func NewGameClock() *GameClock {
+	return &GameClock{}
+}
+
+// Advance moves the clock forward by one tick and reports whether the
+// simulation should run this frame.
+func (c *GameClock) Advance() bool {
+	if c.Paused {
+		return false
+	}
+	c.Ticks++
+	return true
+}
+
+// TogglePause flips the paused state of the clock
+func (c *GameClock) TogglePause() {
+	c.Paused = !c.Paused
+}
+
+// Seconds returns the simulated time elapsed since the clock started
+func (c *GameClock) Seconds() float64 {
+	return float64(c.Ticks) / ticksPerSecond
+}
+
+// SecondsSince returns the simulated time elapsed since the given tick
+func (c *GameClock) SecondsSince(tick int) float64 {
+	return float64(c.Ticks-tick) / ticksPerSecond
+}
@@ -0,0 +1,81 @@
+package main
+
+import "fmt"
+
+// LogKind groups combat log entries by what produced them
+type LogKind int
+
+const (
+	LogEvent  LogKind = iota // Anything else worth keeping: alarms, traps, drops
+	LogCombat                // Damage exchanged with monsters
+	LogPickup                // Items, gold and potions picked up
+	LogFloor                 // Level transitions
+)
+
+func (k LogKind) String() string {
+	switch k {
+	case LogCombat:
+		return "combat"
+	case LogPickup:
+		return "pickup"
+	case LogFloor:
+		return "floor"
+	default:
+		return "event"
+	}
+}
+
+// LogEntry is one structured line of the combat log
+type LogEntry struct {
+	Tick         int // Game clock tick the entry was recorded on
+	Kind         LogKind
+	Text         string
+	HealthChange int // Net change to the player's health, 0 if none
+	ScoreChange  int
+}
+
+// String formats the entry for the log panel
+func (e LogEntry) String() string {
+	secs := e.Tick / ticksPerSecond
+	line := fmt.Sprintf("[%02d:%02d] %s", secs/60, secs%60, e.Text)
+	if e.HealthChange != 0 {
+		line += fmt.Sprintf(" (%+d HP)", e.HealthChange)
+	}
+	return line
+}
+
+const (
+	combatLogCapacity = 500 // Oldest entries are dropped past this
+	combatLogPageSize = 16  // Entries visible in the log panel at once
+)
+
+// CombatLog keeps every entry of the run, unlike the fading message toasts
+type CombatLog struct {
+	Entries []LogEntry
+	Scroll  int // Entries scrolled back from the newest
+}
+
+func NewCombatLog() *CombatLog {
+	return &CombatLog{Entries: make([]LogEntry, 0, combatLogCapacity)}
+}
+
+// Add appends an entry, keeping the view pinned if the player scrolled back
+func (l *CombatLog) Add(e LogEntry) {
+	l.Entries = append(l.Entries, e)
+	if len(l.Entries) > combatLogCapacity {
+		l.Entries = l.Entries[len(l.Entries)-combatLogCapacity:]
+	} else if l.Scroll > 0 {
+		l.Scroll++
+	}
+}
+
+// ScrollBy moves the view back (positive) or forward (negative) in time
+func (l *CombatLog) ScrollBy(n int) {
+	l.Scroll = max(0, min(l.Scroll+n, len(l.Entries)-combatLogPageSize))
+}
+
+// Page returns the entries currently in view, oldest first
+func (l *CombatLog) Page() []LogEntry {
+	end := len(l.Entries) - l.Scroll
+	return l.Entries[max(0, end-combatLogPageSize):end]
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	cageChance           = 35 // Percent of floors with a caged companion
+	companionReviveTurns = 3  // Turns the player must stay beside a downed companion
+	companionFollowDist  = 2  // Path steps the companion lets the player get ahead
+)
+
+// CompanionKind holds the stats of a kind of companion
+type CompanionKind struct {
+	Name         string
+	Health       int
+	Damage       int
+	MoveInterval int // World turns between steps
+	Color        color.RGBA
+}
+
+// LocalName is the companion's name in the player's language
+func (k CompanionKind) LocalName() string {
+	return tr(k.Name)
+}
+
+var companionKinds = []CompanionKind{
+	{"dog", 30, 5, 1, color.RGBA{200, 150, 90, 255}},
+	{"golem", 60, 8, 2, color.RGBA{130, 140, 160, 255}},
+}
+
+// Companion is a friendly creature that follows the player and fights
+// monsters next to it. A companion brought to 0 health is downed rather than
+// killed; the player can revive it by staying beside it.
+type Companion struct {
+	Kind      CompanionKind
+	X, Y      int
+	Health    int
+	MaxHealth int
+	Downed    bool
+	revive    int // Turns the player has spent reviving it
+	cooldown  int
+}
+
+func NewCompanion(kind CompanionKind, x, y int) *Companion {
+	return &Companion{Kind: kind, X: x, Y: y, Health: kind.Health, MaxHealth: kind.Health}
+}
+
+// Occupies reports whether the companion stands on p
+func (c *Companion) Occupies(p Point) bool {
+	return c != nil && c.X == p.x && c.Y == p.y
+}
+
+// TakeDamage hurts the companion, downing it at 0 health. It reports whether
+// this blow downed it.
+func (c *Companion) TakeDamage(damage int) bool {
+	c.Health -= damage
+	if c.Health > 0 || c.Downed {
+		return false
+	}
+	c.Health, c.Downed, c.revive = 0, true, 0
+	return true
+}
+
+func (c *Companion) Draw(screen *ebiten.Image) {
+	clr := c.Kind.Color
+	if c.Downed {
+		clr = darkenColor(clr)
+	}
+	inset := float32(tileSize) / 5
+	vector.DrawFilledRect(screen, float32(c.X*tileSize)+inset, float32(c.Y*tileSize)+inset,
+		float32(tileSize)-2*inset, float32(tileSize)-2*inset, clr, false)
+}
+
+// --- Cage Interaction ---
+
+type CageInteraction struct {
+	At Point
+}
+
+func NewCageInteraction(at Point) *CageInteraction {
+	return &CageInteraction{At: at}
+}
+
+func (c *CageInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	if player.Companion != nil {
+		return InteractionResult{Message: tr("Your %s growls at the cage. One companion is enough.", player.Companion.Kind.LocalName())}
+	}
+
+	kind := companionKinds[rng.Stream(StreamLoot).Intn(len(companionKinds))]
+	player.Companion = NewCompanion(kind, c.At.x, c.At.y)
+	return InteractionResult{
+		Message:       tr("You free a caged %s! It follows you.", kind.LocalName()),
+		Kind:          LogEvent,
+		RemoveEntity:  true,
+		EntityRemoved: Cage,
+	}
+}
+
+// --- Companion AI ---
+
+// updateCompanion runs one world turn for the player's companion
+func (g *Game) updateCompanion() {
+	c, p, d := g.player.Companion, g.player, g.dungeon
+	if c == nil {
+		return
+	}
+
+	if c.Downed {
+		if !isAdjacent(c.X, c.Y, p.X, p.Y) {
+			c.revive = 0
+			return
+		}
+		c.revive++
+		if c.revive >= companionReviveTurns {
+			c.Downed = false
+			c.Health = c.MaxHealth / 2
+			g.interactionHandler.Record(LogEvent, tr("Your %s is back on its feet!", c.Kind.LocalName()), 0)
+		}
+		return
+	}
+
+	if c.cooldown > 0 {
+		c.cooldown--
+		return
+	}
+	c.cooldown = c.Kind.MoveInterval - 1
+
+	// Fight first, follow otherwise
+	for _, dir := range monsterDirs {
+		if m := d.MonsterAt(c.X+dir.x, c.Y+dir.y); m != nil {
+			g.companionAttack(m)
+			return
+		}
+	}
+
+	path := d.FindPath(Point{c.X, c.Y}, Point{p.X, p.Y})
+	if len(path) > companionFollowDist+1 {
+		next := path[1]
+		if d.Cells[next.y][next.x].Type == Empty && d.MonsterAt(next.x, next.y) == nil {
+			c.X, c.Y = next.x, next.y
+		}
+	}
+}
+
+// companionAttack has the companion strike an adjacent monster
+func (g *Game) companionAttack(m *MonsterEntity) {
+	c := g.player.Companion
+	cell := g.dungeon.Cells[m.Y][m.X]
+	hit := cell.Resistances().Apply(c.Kind.Damage, DamagePhysical)
+	m.Health -= hit
+	g.dungeon.MakeNoise(Point{m.X, m.Y}, noiseCombat)
+
+	if m.Health > 0 {
+		g.interactionHandler.Record(LogCombat,
+			tr("Your %s bites the %s for %d (%d/%d HP).", c.Kind.LocalName(), cell.MonsterName(), hit, m.Health, m.MaxHealth), 0)
+		return
+	}
+	score := 5 + cell.InteractionLevel*3
+	g.player.Score += score
+	g.interactionHandler.Record(LogCombat, tr("Your %s defeats the %s! (+%d points)", c.Kind.LocalName(), cell.MonsterName(), score), 0)
+	if msg := g.dungeon.killMonster(m, g.rng); msg != "" {
+		g.interactionHandler.Record(LogEvent, msg, 0)
+	}
+	g.monsterKilled(cell, m)
+}
+
+// monsterAttackCompanion lets a monster strike the player's companion
+func (g *Game) monsterAttackCompanion(m *MonsterEntity) {
+	c := g.player.Companion
+	cell := g.dungeon.Cells[m.Y][m.X]
+	damage := cell.Species.Info().Damage + cell.InteractionLevel*monsterDamagePerLvl
+	g.dungeon.MakeNoise(Point{c.X, c.Y}, noiseCombat)
+	if c.TakeDamage(damage) {
+		g.interactionHandler.Record(LogCombat,
+			tr("Your %s is downed! Stay beside it to revive it.", c.Kind.LocalName()), 0)
+		return
+	}
+	g.interactionHandler.Record(LogCombat,
+		tr("The %s hits your %s for %d.", cell.MonsterName(), c.Kind.LocalName(), damage), 0)
+}
+
+// bringCompanion moves a standing companion to the new floor next to the
+// player. A downed companion is left behind.
+func (g *Game) bringCompanion() {
+	c := g.player.Companion
+	if c == nil {
+		return
+	}
+	if c.Downed {
+		g.player.Companion = nil
+		g.interactionHandler.Record(LogEvent, tr("You left your %s behind.", c.Kind.LocalName()), 0)
+		return
+	}
+	spots := g.dungeon.nearestEmptyCells(Point{g.player.X, g.player.Y}, 1, Point{g.player.X, g.player.Y})
+	if len(spots) == 0 {
+		c.X, c.Y = g.player.X, g.player.Y
+		return
+	}
+	c.X, c.Y = spots[0].x, spots[0].y
+}
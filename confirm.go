@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+// ConfirmPrompt asks before the player steps into a dangerous tile, or does
+// something that can't be undone. While it is open it takes all input and
+// the world waits.
+type ConfirmPrompt struct {
+	Target Point  // Tile the player clicked; moving there again is what gets confirmed
+	Action func() // What gets confirmed instead of the move, if set
+	Text   string
+}
+
+// dangerPrompt returns the question to ask before stepping into next, or ""
+// if the tile is safe. A monster already being fought doesn't ask again.
+func (g *Game) dangerPrompt(next Point) string {
+	cell := g.dungeon.Cells[next.y][next.x]
+	switch cell.Type {
+	case Monster:
+		m := g.dungeon.MonsterAt(next.x, next.y)
+		if m == nil || m.Health < m.MaxHealth {
+			return ""
+		}
+		fight := NewMonsterInteraction(cell, m)
+		hit := max(1, cell.Resistances().Apply(g.player.AttackDamage(), g.player.AttackType()))
+		blows := (m.Health + hit - 1) / hit
+		return tr("Attack the %s? It hits back for about %d.\nYou need about %d blows (%d HP to go).",
+			cell.MonsterName(), fight.Strike(g.player), blows, m.Health)
+	case Exit:
+		return tr("Leave this floor for level %d?\nYou can't come back.", cell.InteractionLevel)
+	}
+	return ""
+}
+
+// updateConfirm handles input while the confirmation prompt is open
+func (g *Game) updateConfirm() {
+	switch {
+	case input.JustPressed(ActionYes) || input.JustPressed(ActionConfirm):
+		target, action := g.confirm.Target, g.confirm.Action
+		g.closePanel(PanelConfirm)
+		if action != nil {
+			action()
+		} else if g.player.MoveTo(target.x, target.y, g.dungeon, g.interactionHandler) {
+			g.turns.PlayerActed()
+		}
+	case input.JustPressed(ActionNo) || input.JustPressed(ActionCancel):
+		g.closePanel(PanelConfirm)
+	}
+}
+
+// drawConfirm draws the confirmation prompt in the middle of the screen
+func (g *Game) drawConfirm(screen *ebiten.Image) {
+	lines := append(strings.Split(g.confirm.Text, "\n"), "", tr("Y/Enter: go ahead   N/Esc: stay"))
+	panelW, panelH := 360, 30+16*len(lines)
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := screen.Bounds().Dy()/2 - panelH/2
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	ui.DrawText(screen, tr("Are you sure?"), panelX+6, panelY+4)
+	for i, line := range lines {
+		ui.DrawText(screen, line, panelX+6, panelY+24+16*i)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math/rand"
+)
+
+const (
+	cursedChance  = 20 // Percent of carried finds that turn out cursed
+	blessedChance = 15 // Percent of carried finds that turn out blessed
+	curseDefense  = 5  // Defense lost per cursed item carried
+	curseLuck     = 5  // Luck lost per cursed item carried
+	blessDefense  = 3  // Defense gained per blessed item carried
+	blessLuck     = 3  // Luck gained per blessed item carried
+)
+
+// Blessing is the hidden state of a found item, only learned once it's picked up
+type Blessing int
+
+const (
+	Unblessed Blessing = iota
+	Blessed
+	Cursed
+)
+
+func (b Blessing) String() string {
+	switch b {
+	case Blessed:
+		return "blessed"
+	case Cursed:
+		return "cursed"
+	default:
+		return ""
+	}
+}
+
+// rollBlessing decides whether an unidentified find is cursed, blessed or plain
+func rollBlessing(rng *rand.Rand) Blessing {
+	roll := rng.Intn(100)
+	switch {
+	case roll < cursedChance:
+		return Cursed
+	case roll < cursedChance+blessedChance:
+		return Blessed
+	default:
+		return Unblessed
+	}
+}
+
+// identify names the blessing of a freshly picked up item and says what it does
+func (item *Item) identify() string {
+	switch item.Blessing {
+	case Cursed:
+		item.Name = "cursed " + item.Name
+		return tr(" It's cursed! -%d defense, -%d luck until a shrine lifts it.", curseDefense, curseLuck)
+	case Blessed:
+		item.Name = "blessed " + item.Name
+		return tr(" It's blessed! +%d defense, +%d luck.", blessDefense, blessLuck)
+	}
+	return ""
+}
+
+// blessingCount returns how many carried items have the given blessing
+func (p *Player) blessingCount(b Blessing) int {
+	count := 0
+	for _, item := range p.Inventory {
+		if item.Blessing == b {
+			count++
+		}
+	}
+	return count
+}
+
+// EffectiveDefense is the player's defense after blessed and cursed items
+func (p *Player) EffectiveDefense() int {
+	return max(0, p.Defense+blessDefense*p.blessingCount(Blessed)-curseDefense*p.blessingCount(Cursed))
+}
+
+// EffectiveLuck is the player's luck after blessed and cursed items
+func (p *Player) EffectiveLuck() int {
+	return p.Luck + blessLuck*p.blessingCount(Blessed) - curseLuck*p.blessingCount(Cursed)
+}
+
+// liftCurses cleanses every cursed item in the inventory and reports how many there were
+func (p *Player) liftCurses() int {
+	lifted := 0
+	for i := range p.Inventory {
+		item := &p.Inventory[i]
+		if item.Blessing == Cursed {
+			item.Blessing = Unblessed
+			item.Name = item.Name[len("cursed "):]
+			lifted++
+		}
+	}
+	return lifted
+}
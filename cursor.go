@@ -0,0 +1,69 @@
+package main
+
+// Look mode points at tiles with the keyboard instead of the mouse. The tile
+// under its cursor gets the same highlight, path preview and tooltip as a
+// hovered one, and Enter travels there like a double click. Whichever of the mouse
+// and the keys moved last does the pointing, so the two never disagree.
+
+// moveSteps are the movement actions and the step each one takes
+var moveSteps = []struct {
+	action Action
+	dx, dy int
+}{
+	{ActionMoveUp, 0, -1},
+	{ActionMoveDown, 0, 1},
+	{ActionMoveLeft, -1, 0},
+	{ActionMoveRight, 1, 0},
+}
+
+// updatePointer works out the tile pointed at this tick: the look cursor's,
+// or the one under the mouse once it moves
+func (g *Game) updatePointer() {
+	mouse := input.Cursor
+	if mouse != g.lastMouse {
+		g.looking = false
+	}
+	g.lastMouse = mouse
+	if g.looking {
+		return
+	}
+	if tx, ty, ok := g.camera.ScreenToTile(mouse.X, mouse.Y); ok {
+		g.hoverX, g.hoverY = tx, ty
+	} else {
+		g.hoverX, g.hoverY = -1, -1
+	}
+}
+
+// startLook puts the look cursor on the player, or on the hovered tile if
+// there is one. It takes over from any selected tile.
+func (g *Game) startLook() {
+	g.looking = true
+	g.selected = nil
+	if !inBounds(g.hoverX, g.hoverY, g.dungeon.Width, g.dungeon.Height) {
+		g.hoverX, g.hoverY = g.player.X, g.player.Y
+	}
+}
+
+// updateLook moves the look cursor with the movement keys and travels to it
+// with Enter. The look key or Escape leaves look mode.
+// It reports whether look mode took the input.
+func (g *Game) updateLook() bool {
+	if !g.looking {
+		return false
+	}
+	if input.JustPressed(ActionLook) || input.JustPressed(ActionCancel) {
+		g.looking = false
+		return true
+	}
+	for _, s := range moveSteps {
+		if input.Repeated(s.action) {
+			g.hoverX = max(0, min(g.hoverX+s.dx, g.dungeon.Width-1))
+			g.hoverY = max(0, min(g.hoverY+s.dy, g.dungeon.Height-1))
+			g.camera.Reveal(g.hoverX, g.hoverY)
+		}
+	}
+	if !g.clock.Paused && input.JustPressed(ActionConfirm) {
+		g.startTravel(Point{g.hoverX, g.hoverY})
+	}
+	return true
+}
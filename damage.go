@@ -0,0 +1,92 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// DamageType is the kind of harm a blow, shot or spell deals
+type DamageType int
+
+const (
+	DamagePhysical DamageType = iota
+	DamageFire
+	DamagePoison
+	DamageMagic
+	numDamageTypes
+)
+
+func (t DamageType) String() string {
+	switch t {
+	case DamagePhysical:
+		return "physical"
+	case DamageFire:
+		return "fire"
+	case DamagePoison:
+		return "poison"
+	case DamageMagic:
+		return "magic"
+	default:
+		return "unknown"
+	}
+}
+
+// LocalName is the damage type's name in the player's language
+func (t DamageType) LocalName() string {
+	return tr(t.String())
+}
+
+// weaponNames names the weapon or spell focus that deals each damage type
+var weaponNames = [numDamageTypes]string{
+	DamagePhysical: "war axe",
+	DamageFire:     "fire wand",
+	DamagePoison:   "venom dagger",
+	DamageMagic:    "arcane staff",
+}
+
+// Resistances maps damage types to a percentage taken off incoming damage.
+// Negative values are weaknesses that add damage instead.
+type Resistances map[DamageType]int
+
+// Apply scales damage of the given type by the resistance to it
+func (r Resistances) Apply(damage int, t DamageType) int {
+	return max(0, damage*(100-r[t])/100)
+}
+
+// Describe lists weaknesses and resistances for hover info, "" if there are none
+func (r Resistances) Describe() string {
+	var weak, resists []string
+	for t := DamageType(0); t < numDamageTypes; t++ {
+		switch {
+		case r[t] < 0:
+			weak = append(weak, t.String())
+		case r[t] > 0:
+			resists = append(resists, t.String())
+		}
+	}
+	sort.Strings(weak)
+	sort.Strings(resists)
+
+	var parts []string
+	if len(weak) > 0 {
+		parts = append(parts, "weak: "+strings.Join(weak, ", "))
+	}
+	if len(resists) > 0 {
+		parts = append(parts, "resists: "+strings.Join(resists, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// effectiveness describes how well a damage type worked against r, for messages
+func (r Resistances) effectiveness(t DamageType) string {
+	switch {
+	case r[t] < 0:
+		return tr(" It's weak to %s!", t.LocalName())
+	case r[t] >= 100:
+		return tr(" It's immune to %s!", t.LocalName())
+	case r[t] > 0:
+		return tr(" It resists %s.", t.LocalName())
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+// debugSmoothing is the weight a new frame time gets in the running average,
+// so the overlay shows a steady figure instead of flickering every frame
+const debugSmoothing = 0.1
+
+// DebugOverlay is the F3 panel of performance and world stats, for diagnosing
+// performance reports
+type DebugOverlay struct {
+	Visible  bool
+	drawTime float64 // Smoothed time Game.Draw takes, in milliseconds
+}
+
+// RecordDraw folds the time the frame that started at start took to draw
+// into the running average
+func (o *DebugOverlay) RecordDraw(start time.Time) {
+	ms := float64(time.Since(start).Microseconds()) / 1000
+	if o.drawTime == 0 {
+		o.drawTime = ms
+		return
+	}
+	o.drawTime += (ms - o.drawTime) * debugSmoothing
+}
+
+// drawDebugOverlay draws the debug panel in the top right corner
+func (g *Game) drawDebugOverlay(screen *ebiten.Image) {
+	if !g.debug.Visible {
+		return
+	}
+	lines := []string{
+		fmt.Sprintf("FPS %.1f   TPS %.1f", ebiten.ActualFPS(), ebiten.ActualTPS()),
+		fmt.Sprintf("Draw %.2f ms", g.debug.drawTime),
+		fmt.Sprintf("Screen %dx%d at %gx, tiles %dpx", g.screenW, g.screenH, deviceScale(), tileSize),
+		fmt.Sprintf("Run seed %d", g.runSeed),
+		fmt.Sprintf("Floor seed %d", g.dungeon.Seed),
+		fmt.Sprintf("Floor %d: %dx%d tiles", g.dungeon.Level, g.dungeon.Width, g.dungeon.Height),
+		fmt.Sprintf("Player tile (%d, %d)", g.player.X, g.player.Y),
+		fmt.Sprintf("Monsters %d   Projectiles %d", len(g.dungeon.Monsters), len(g.projectiles)),
+		fmt.Sprintf("Room events %d   Messages %d", len(g.dungeon.RoomEvents), len(g.interactionHandler.Messages)),
+		fmt.Sprintf("Tick %d   Turn %d", g.clock.Ticks, g.turns.Turn),
+	}
+
+	const lineHeight = 16
+	panelW, panelH := 220, 10+lineHeight*len(lines)
+	panelX, panelY := screen.Bounds().Dx()-panelW-10, 50
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+	for i, line := range lines {
+		ui.Text.Draw(screen, line, panelX+8, panelY+5+lineHeight*i,
+			ui.TextStyle{Size: ui.SizeSmall, Color: color.RGBA{140, 255, 140, 255}})
+	}
+}
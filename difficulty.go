@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// difficultyFile holds player-tuned difficulty curves, keyed by difficulty label
+const difficultyFile = "difficulty.json"
+
+// ScalingCurve turns a dungeon level into a multiplier:
+//
+//	Base + Slope * (level-1)^Exponent, clamped to [Min, Max]
+//
+// An Exponent above 1 keeps early floors close to Base while later floors
+// ramp up faster; a negative Slope makes the multiplier shrink with depth.
+type ScalingCurve struct {
+	Base     float64 `json:"base"`
+	Slope    float64 `json:"slope"`
+	Exponent float64 `json:"exponent"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+}
+
+// At returns the curve's multiplier on the given dungeon level
+func (c ScalingCurve) At(level int) float64 {
+	depth := float64(max(level-1, 0))
+	v := c.Base + c.Slope*math.Pow(depth, c.Exponent)
+	if c.Max > 0 {
+		v = math.Min(v, c.Max)
+	}
+	return math.Max(v, c.Min)
+}
+
+// DifficultyCurve scales monster strength and treasure value with depth
+type DifficultyCurve struct {
+	Monster  ScalingCurve `json:"monster"`
+	Treasure ScalingCurve `json:"treasure"`
+}
+
+// defaultDifficultyCurves all start near 1.0 on the first floor, so even
+// Nightmare is winnable early, and spread apart the deeper the player goes.
+var defaultDifficultyCurves = map[string]DifficultyCurve{
+	"Easy": {
+		Monster:  ScalingCurve{Base: 0.8, Slope: 0.02, Exponent: 1, Min: 0.5, Max: 1.2},
+		Treasure: ScalingCurve{Base: 1.2, Slope: 0.02, Exponent: 1, Min: 1, Max: 1.5},
+	},
+	"Normal": {
+		Monster:  ScalingCurve{Base: 1.0, Slope: 0.04, Exponent: 1, Min: 1, Max: 1.6},
+		Treasure: ScalingCurve{Base: 1.0, Slope: 0, Exponent: 1, Min: 1, Max: 1},
+	},
+	"Hard": {
+		Monster:  ScalingCurve{Base: 1.05, Slope: 0.05, Exponent: 1.2, Min: 1, Max: 2.2},
+		Treasure: ScalingCurve{Base: 0.9, Slope: -0.015, Exponent: 1, Min: 0.7, Max: 0.9},
+	},
+	"Nightmare": {
+		Monster:  ScalingCurve{Base: 1.1, Slope: 0.06, Exponent: 1.4, Min: 1, Max: 3},
+		Treasure: ScalingCurve{Base: 0.85, Slope: -0.02, Exponent: 1, Min: 0.6, Max: 0.85},
+	},
+}
+
+// difficultyCurves are the curves in effect, defaults overlaid by the config file
+var difficultyCurves = defaultDifficultyCurves
+
+// curveFor returns the curve for a difficulty label, falling back to Normal
+func curveFor(label string) DifficultyCurve {
+	if c, ok := difficultyCurves[label]; ok {
+		return c
+	}
+	return defaultDifficultyCurves["Normal"]
+}
+
+// loadDifficultyCurves reads the config file over the defaults. A missing
+// file is not an error; difficulties it leaves out keep their defaults.
+func loadDifficultyCurves() (map[string]DifficultyCurve, error) {
+	curves := make(map[string]DifficultyCurve, len(defaultDifficultyCurves))
+	for label, c := range defaultDifficultyCurves {
+		curves[label] = c
+	}
+
+	file := configPath(difficultyFile)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return curves, nil
+	}
+	if err != nil {
+		return curves, err
+	}
+
+	var custom map[string]DifficultyCurve
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return curves, fmt.Errorf("parse %s: %w", file, err)
+	}
+	for label, c := range custom {
+		curves[label] = c
+	}
+	return curves, nil
+}
+
+// applyDifficulty scales the monsters and treasures generated on the current
+// floor by the curve's multipliers for its level
+func (g *Game) applyDifficulty() {
+	d := g.dungeon
+	monsterMod := g.difficulty.Monster.At(d.Level)
+	treasureMod := g.difficulty.Treasure.At(d.Level)
+	for y := 0; y < d.Height; y++ {
+		for x := 0; x < d.Width; x++ {
+			cell := &d.Cells[y][x]
+			if cell.Type == Monster {
+				cell.InteractionLevel = int(float64(cell.InteractionLevel) * monsterMod)
+				if cell.InteractionLevel < 1 {
+					cell.InteractionLevel = 1
+				}
+				if m := d.MonsterAt(x, y); m != nil {
+					m.resetHealth(*cell)
+				}
+			} else if cell.Type == Treasure {
+				cell.InteractionLevel = int(float64(cell.InteractionLevel) * treasureMod)
+				if cell.InteractionLevel < 5 {
+					cell.InteractionLevel = 5 // Minimum treasure value
+				}
+				cell.Interaction = NewTreasureInteraction(cell.InteractionLevel, cell.TreasureType)
+			}
+		}
+	}
+	g.spawner.Interval = spawnInterval(monsterMod)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// windowRoom is how much of the monitor, in device-independent pixels, is
+// left around the window for the title bar and taskbar
+const windowRoom = 80
+
+// deviceScale is how many device pixels make up one screen pixel on the
+// current monitor: 1 on ordinary displays, 2 or a fraction like 1.5 on
+// high-DPI ones. The screen is laid out in device-independent pixels, so the
+// game keeps its size on any display and ebiten scales the frame up.
+func deviceScale() float64 {
+	if m := ebiten.Monitor(); m != nil && m.DeviceScaleFactor() > 0 {
+		return m.DeviceScaleFactor()
+	}
+	return 1
+}
+
+// fitWindowSize shrinks a window size to fit the monitor, keeping its aspect
+// ratio, so a large resolution doesn't open a window hanging off a small or
+// heavily scaled display
+func fitWindowSize(w, h int) (int, int) {
+	m := ebiten.Monitor()
+	if m == nil {
+		return w, h
+	}
+	monitorW, monitorH := m.Size()
+	if monitorW <= windowRoom || monitorH <= windowRoom {
+		return w, h
+	}
+	scale := min(1, float64(monitorW-windowRoom)/float64(w), float64(monitorH-windowRoom)/float64(h))
+	return int(float64(w) * scale), int(float64(h) * scale)
+}
+
+// crispTileSize rounds a tile size down so that tiles cover a whole number of
+// device pixels. At a fractional scale like 1.5 an odd tile size would land
+// on half pixels and blur the tileset. Scales no nearby size suits leave
+// the size as it is.
+func crispTileSize(size int) int {
+	scale := deviceScale()
+	for s := size; s >= max(minTileSize, size*3/4); s-- {
+		if px := float64(s) * scale; math.Abs(px-math.Round(px)) < 0.01 {
+			return s
+		}
+	}
+	return size
+}
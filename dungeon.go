@@ -2,10 +2,8 @@ package main
 
 import (
 	"image/color"
+	"math"
 	"math/rand"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 type CellType int
@@ -17,6 +15,15 @@ const (
 	Treasure
 	Entrance
 	Exit
+	Satchel
+	Trap
+	Cage
+	Shrine
+	Merchant
+	Lever
+	Fountain
+	Altar
+	Scripted // Defined by a script in the scripts directory
 )
 
 func (ct CellType) String() string {
@@ -33,11 +40,53 @@ func (ct CellType) String() string {
 		return "Entrance"
 	case Exit:
 		return "Exit"
+	case Satchel:
+		return "Satchel"
+	case Trap:
+		return "Trap"
+	case Cage:
+		return "Cage"
+	case Shrine:
+		return "Shrine"
+	case Merchant:
+		return "Merchant"
+	case Lever:
+		return "Lever"
+	case Fountain:
+		return "Fountain"
+	case Altar:
+		return "Altar"
+	case Scripted:
+		return "Scripted"
 	default:
 		return "Unknown"
 	}
 }
 
+// IsInteractive reports whether stepping into the cell triggers an interaction
+func (ct CellType) IsInteractive() bool {
+	return ct == Monster || ct == Treasure || ct == Exit || ct == Satchel || ct == Cage || ct == Shrine || ct == Merchant || ct == Lever ||
+		ct == Fountain || ct == Altar || ct == Scripted
+}
+
+// StopsMovement reports whether paths halt in front of the cell instead of walking over it.
+// Hidden traps don't stop anything; walking onto one sets it off.
+func (c Cell) StopsMovement() bool {
+	if c.Type == Trap {
+		return c.Revealed
+	}
+	return c.Type == Monster || c.Type == Treasure || c.Type == Satchel || c.Type == Cage || c.Type == Shrine || c.Type == Merchant ||
+		c.Type == Lever || c.Type == Fountain || c.Type == Altar || c.Type == Scripted
+}
+
+// VisibleType is the cell type the player perceives, hiding undiscovered traps
+func (c Cell) VisibleType() CellType {
+	if c.Type == Trap && !c.Revealed {
+		return Empty
+	}
+	return c.Type
+}
+
 // Optional: More structured data for monster & treasure classification
 type TreasureType string
 
@@ -46,6 +95,10 @@ const (
 	TreasureGems     TreasureType = "gems"
 	TreasureArtifact TreasureType = "artifact"
 	TreasurePotion   TreasureType = "potion"
+	TreasureTorch    TreasureType = "torch"
+	TreasureLantern  TreasureType = "lantern"
+	TreasureWeapon   TreasureType = "weapon"
+	TreasureAmulet   TreasureType = "amulet" // Only found on floors whose shrine asks for it
 )
 
 type MonsterTier int
@@ -62,6 +115,22 @@ type Cell struct {
 	InteractionLevel int          // Difficulty (monster) or value (treasure)
 	TreasureType     TreasureType // Specific treasure variant
 	MonsterTier      MonsterTier  // Optional: Add more scaling/behavior if needed
+	Species          SpeciesID    // Kind of monster, chosen by tier and floor theme
+	Elite            EliteAffix   // Elite monsters carry an affix; AffixNone otherwise
+	Revealed         bool         // Traps stay hidden until spotted
+	Shrieker         bool         // Monster raises the alarm when killed
+	Locked           bool         // Treasure raises the alarm when its lock is broken
+	Gate             bool         // Wall that a lever opens
+	Switched         bool         // Lever has been pulled
+	Guard            GuardKind    // How the treasure's guardian protects it
+	ScriptID         string       // Which script a Scripted cell runs
+	Interaction      Interactable `json:"-"` // What touching this cell does; nil for terrain and monsters
+}
+
+// NewTreasureCell builds a treasure cell carrying its own interaction
+func NewTreasureCell(value int, kind TreasureType) Cell {
+	return Cell{Type: Treasure, InteractionLevel: value, TreasureType: kind,
+		Interaction: NewTreasureInteraction(value, kind)}
 }
 
 type Dungeon struct {
@@ -71,6 +140,24 @@ type Dungeon struct {
 	Exit          [2]int
 	Visited       [][]bool
 	Level         int
+	Darkness      int // How many tiles the base FOV radius shrinks on this floor
+	Seed          int64
+	AlarmTurns    int // Quiet turns left before the alarm dies down; 0 when calm
+	Monsters      []*MonsterEntity
+	Noises        []Noise  // Sounds monsters will hear on the next world turn
+	Impacts       []Impact // Heavy blows that shake the screen, taken by the effects each tick
+	Cage          *Point   // Where a companion waits to be freed, nil if none
+	Shrine        *Point   // Where a shrine hands out this floor's quest, nil if none
+	Merchant      *Point   // Where a merchant trades, nil if none
+	RoomEvents    []*RoomEvent
+	Theme         FloorTheme
+	Modifier      FloorModifier
+	ExitTaken     bool      // The player walked into the exit and is on the way down
+	Spec          FloorSpec // What the floor was generated from, so a save can generate it again
+
+	rng   *rand.Rand               // Generation stream derived from Seed
+	sight map[sightKey]*sightField // Fields of view worked out this tick
+	paths pathBuffers              // Scratch grids for FindPath
 }
 
 const (
@@ -78,14 +165,21 @@ const (
 	NumTreasures = 10
 )
 
-// Modify the NewDungeon function to initialize monsters and treasures with levels
-func NewDungeon(width, height int, level int) *Dungeon {
+// NewDungeon generates the floor described by spec, initializing monsters and treasures with levels
+func NewDungeon(spec FloorSpec) *Dungeon {
+	width, height, level := spec.Width, spec.Height, spec.Level
 	d := &Dungeon{
-		Cells:   make([][]Cell, height),
-		Width:   width,
-		Height:  height,
-		Visited: make([][]bool, height),
-		Level:   level,
+		Cells:    make([][]Cell, height),
+		Width:    width,
+		Height:   height,
+		Visited:  make([][]bool, height),
+		Level:    level,
+		Darkness: spec.Darkness(),
+		Seed:     spec.Seed,
+		Spec:     spec,
+		Theme:    spec.Theme,
+		Modifier: spec.Modifier,
+		rng:      streamRand(spec.Seed, "generation"),
 	}
 	// initialize Cells and Visited
 	for y := 0; y < height; y++ {
@@ -99,11 +193,17 @@ func NewDungeon(width, height int, level int) *Dungeon {
 
 	// Generate maze with proper paths
 	d.generateMaze()
+	d.openUp(spec.Openness)
 
 	// Place entrance
 	entranceX, entranceY := d.placeRandomFeature(Empty, Entrance)
 	d.Entrance = [2]int{entranceX, entranceY}
 
+	// The arena has no exit and starts empty; monsters arrive in waves
+	if spec.Arena {
+		return d
+	}
+
 	// Find dead ends that are far from the entrance
 	deadEnds := d.findDeadEnds()
 
@@ -134,52 +234,131 @@ func NewDungeon(width, height int, level int) *Dungeon {
 			d.Cells[exitY][exitX] = Cell{Type: Empty}
 		}
 	}
+	d.Cells[d.Exit[1]][d.Exit[0]].Interaction = NewExitInteraction(level + 1)
+
+	// Some floors hide bonus loot behind a gate opened by a lever elsewhere
+	if d.rng.Intn(100) < vaultChance {
+		d.placeVault(level)
+	}
+
+	numMonsters, numTreasures := NumMonsters, NumTreasures
+	switch spec.Modifier {
+	case ModifierInfested:
+		numMonsters = NumMonsters * 3 / 2
+	case ModifierHoard:
+		numTreasures = NumTreasures * 3 / 2
+	}
 
 	// Place monsters with varying levels and tiers based on dungeon level
-	for i := 0; i < NumMonsters; i++ {
+	for i := 0; i < numMonsters; i++ {
 		x, y := d.placeRandomFeature(Empty, Monster)
 
 		// Monster level and tier logic
-		monsterLevel := level + rand.Intn(3) - 1
+		monsterLevel := level + d.rng.Intn(3) - 1
 		if monsterLevel < 1 {
 			monsterLevel = 1
 		}
 
-		var tier MonsterTier
-		switch {
-		case monsterLevel <= 2:
-			tier = TierEasy
-		case monsterLevel <= 4:
-			tier = TierMedium
-		case monsterLevel <= 6:
-			tier = TierHard
-		default:
-			tier = TierBoss
-		}
-
 		d.Cells[y][x].InteractionLevel = monsterLevel
-		d.Cells[y][x].MonsterTier = tier
+		d.Cells[y][x].MonsterTier = monsterTierForLevel(monsterLevel)
+		d.Cells[y][x].Species = pickSpecies(d.Cells[y][x].MonsterTier, d.Theme.Name, d.rng)
+		d.Cells[y][x].Shrieker = d.rng.Intn(100) < shriekerChance
+		if d.rng.Intn(100) < eliteChance {
+			d.Cells[y][x].Elite = EliteAffix(1 + d.rng.Intn(int(numAffixes)-1))
+		}
+		d.addMonster(x, y)
 	}
 
 	// Place treasures with type-safe treasure types
-	treasureTypes := []TreasureType{TreasureGold, TreasureGems, TreasureArtifact, TreasurePotion}
-	for i := 0; i < NumTreasures; i++ {
+	treasureTypes := []TreasureType{TreasureGold, TreasureGems, TreasureArtifact, TreasurePotion, TreasureTorch, TreasureWeapon}
+	for i := 0; i < numTreasures; i++ {
 		x, y := d.placeRandomFeature(Empty, Treasure)
 
-		treasureValue := level*10 + rand.Intn(20) - 10
+		treasureValue := level*10 + d.rng.Intn(20) - 10
 		if treasureValue < 10 {
 			treasureValue = 10
 		}
 
-		treasureType := treasureTypes[rand.Intn(len(treasureTypes))]
+		treasureType := treasureTypes[d.rng.Intn(len(treasureTypes))]
+		// One floor in three hides a lantern upgrade
+		if i == 0 && d.rng.Intn(3) == 0 {
+			treasureType = TreasureLantern
+		}
+
+		// Locked chests are worth more, but breaking them open is loud
+		locked := d.rng.Intn(100) < lockedChestChance
+		if locked {
+			treasureValue = treasureValue * 3 / 2
+		}
+
+		d.Cells[y][x] = NewTreasureCell(treasureValue, treasureType)
+		d.Cells[y][x].Locked = locked
+	}
+	d.placeGuards()
+
+	// Some floors hold a caged companion
+	if d.rng.Intn(100) < cageChance {
+		x, y := d.placeRandomFeature(Empty, Cage)
+		d.Cells[y][x].Interaction = NewCageInteraction(Point{x, y})
+		d.Cage = &Point{x, y}
+	}
+
+	// Some floors hold a shrine; the game gives it a quest on arrival
+	if d.rng.Intn(100) < shrineChance {
+		x, y := d.placeInDeadEnd(Shrine)
+		d.Shrine = &Point{x, y}
+	}
 
-		d.Cells[y][x].InteractionLevel = treasureValue
-		d.Cells[y][x].TreasureType = treasureType
+	// Some floors hold a merchant; the game stocks it on arrival
+	if d.rng.Intn(100) < shopChance {
+		x, y := d.placeInDeadEnd(Merchant)
+		d.Merchant = &Point{x, y}
+	}
+
+	// Healing spots: fountains refill over time, altars work once
+	if d.rng.Intn(100) < fountainChance {
+		x, y := d.placeInDeadEnd(Fountain)
+		d.Cells[y][x].Interaction = NewFountainInteraction()
+	}
+	if d.rng.Intn(100) < altarChance {
+		x, y := d.placeInDeadEnd(Altar)
+		d.Cells[y][x].Interaction = NewAltarInteraction()
+	}
+
+	// Hide traps whose damage scales with the dungeon level
+	for i := 0; i < NumTraps; i++ {
+		x, y := d.placeRandomFeature(Empty, Trap)
+		d.Cells[y][x].InteractionLevel = trapDamage(level)
+	}
+
+	d.placeBoss()
+	d.placeRoomEvents()
+
+	// Plan patrols once the map is final, so routes only cross bare floor.
+	// Bosses and treasure guardians stand guard instead.
+	for _, m := range d.Monsters {
+		if m.Boss == nil && m.Guarding == nil {
+			d.planPatrolRoute(m)
+		}
 	}
 
 	return d
 }
 
+// monsterTierForLevel buckets a monster level into its tier
+func monsterTierForLevel(level int) MonsterTier {
+	switch {
+	case level <= 2:
+		return TierEasy
+	case level <= 4:
+		return TierMedium
+	case level <= 6:
+		return TierHard
+	default:
+		return TierBoss
+	}
+}
+
 // Find all dead ends in the dungeon (empty cells with only one neighboring empty cell)
 func (d *Dungeon) findDeadEnds() [][2]int {
 	// Directions for checking neighbors (up, right, down, left)
@@ -234,7 +413,7 @@ func (d *Dungeon) sortDeadEndsByDistance(deadEnds [][2]int, point [2]int) {
 // Helper function to place a feature in a random empty cell
 func (d *Dungeon) placeRandomFeature(requiredType, newType CellType) (int, int) {
 	for {
-		x, y := rand.Intn(d.Width-2)+1, rand.Intn(d.Height-2)+1
+		x, y := d.rng.Intn(d.Width-2)+1, d.rng.Intn(d.Height-2)+1
 		if d.Cells[y][x].Type == requiredType {
 			d.Cells[y][x] = Cell{Type: newType}
 			return x, y
@@ -270,7 +449,7 @@ func (d *Dungeon) generateMaze() {
 		neighbors := d.getEmptyNeighbors(wall, dirs)
 		if len(neighbors) > 0 {
 			// Connect the wall with a randomly chosen neighbor
-			neighbor := neighbors[rand.Intn(len(neighbors))]
+			neighbor := neighbors[d.rng.Intn(len(neighbors))]
 			d.carvePath(wall, neighbor)
 
 			// Add adjacent walls of the current wall to the list
@@ -279,6 +458,26 @@ func (d *Dungeon) generateMaze() {
 	}
 }
 
+// openUp knocks through the given percentage of inner walls that separate two
+// corridors, turning the maze into more open ground
+func (d *Dungeon) openUp(percent int) {
+	if percent <= 0 {
+		return
+	}
+	for y := 1; y < d.Height-1; y++ {
+		for x := 1; x < d.Width-1; x++ {
+			if d.Cells[y][x].Type != Wall {
+				continue
+			}
+			between := (d.Cells[y][x-1].Type == Empty && d.Cells[y][x+1].Type == Empty) ||
+				(d.Cells[y-1][x].Type == Empty && d.Cells[y+1][x].Type == Empty)
+			if between && d.rng.Intn(100) < percent {
+				d.Cells[y][x].Type = Empty
+			}
+		}
+	}
+}
+
 // Fill the entire dungeon with walls.
 func (d *Dungeon) fillWithWalls() {
 	for y := 0; y < d.Height; y++ {
@@ -307,7 +506,7 @@ func (d *Dungeon) getInitialWalls(start Point, dirs []Point) []Point {
 
 // Randomly select and remove a wall from the list.
 func (d *Dungeon) randomWall(walls *[]Point) Point {
-	idx := rand.Intn(len(*walls))
+	idx := d.rng.Intn(len(*walls))
 	wall := (*walls)[idx]
 	*walls = removeAt(*walls, idx) // Remove selected wall
 	return wall
@@ -362,13 +561,33 @@ func isWithinFOV(px, py, x, y, radius int) bool {
 	return dx*dx+dy*dy <= radius*radius // Circular FOV
 }
 
-func (d *Dungeon) Draw(screen *ebiten.Image, player *Player) {
+// CellView is how one cell shows to the player this frame
+type CellView struct {
+	X, Y       int
+	Cell       Cell
+	Color      color.RGBA                  // The cell's flat color, lit and animated
+	Shade      func(color.RGBA) color.RGBA // Lights anything else drawn on the cell
+	InSight    bool                        // Within the player's field of view
+	Sensed     bool                        // A monster out of sight, sensed with an artifact
+	Remembered bool                        // Explored but out of sight; drawn under the fog
+}
+
+// View works out how each cell the player knows of shows this frame, and
+// marks the cells in sight as visited
+func (d *Dungeon) View(player *Player) []CellView {
+	radius := player.EffectiveFOVRadius(d)
+	senseRadius := player.MonsterSenseRadius(d)
+	var cells []CellView
 	for y, row := range d.Cells {
 		for x, cell := range row {
-			withinFOV := isWithinFOV(player.X, player.Y, x, y, player.FOVRadius)
+			withinFOV := d.CanSee(Point{player.X, player.Y}, Point{x, y}, radius)
+
+			// Monsters beyond the light or behind walls can still be sensed with the right artifact
+			sensed := cell.Type == Monster && !withinFOV && senseRadius > 0 &&
+				isWithinFOV(player.X, player.Y, x, y, senseRadius)
 
 			// Skip drawing if not visible and never visited
-			if player.FOVEnabled && !withinFOV && !d.Visited[y][x] {
+			if player.FOVEnabled && !withinFOV && !d.Visited[y][x] && !sensed {
 				continue
 			}
 
@@ -377,32 +596,81 @@ func (d *Dungeon) Draw(screen *ebiten.Image, player *Player) {
 				d.Visited[y][x] = true
 			}
 
-			clr := getCellColor(cell.Type, withinFOV)
+			// Explored tiles out of view keep their objects, drawn under the
+			// fog; monsters may have moved on, so they are left out
+			remembered := player.FOVEnabled && !withinFOV && !sensed
+			seen := withinFOV || (remembered && cell.Type != Monster)
 
-			// Darken tile if seen before but not in current FOV
-			if player.FOVEnabled && !withinFOV {
-				clr = darkenColor(clr)
+			clr := getCellColor(cell.VisibleType(), seen)
+			if cell.Type == Wall {
+				clr = d.Theme.WallColor
+			}
+			if sensed {
+				clr = getCellColor(Monster, true)
+			}
+			if withinFOV && cell.Type == Monster {
+				clr = cell.Species.Info().Color
+			}
+			if withinFOV && cell.Type == Monster && cell.Shrieker {
+				clr = shriekerColor
+			}
+			if withinFOV && cell.Type == Treasure && cell.Locked {
+				clr = lockedChestColor
+			}
+			if withinFOV && cell.Type == Treasure && cell.Guard == GuardSealed {
+				clr = sealedChestColor
+			}
+			if cell.Type == Wall && cell.Gate {
+				clr = gateColor
+			}
+			if withinFOV && cell.Type == Lever && cell.Switched {
+				clr = pulledLeverColor
 			}
 
-			vector.DrawFilledRect(
-				screen,
-				float32(x*tileSize),
-				float32(y*tileSize),
-				float32(tileSize),
-				float32(tileSize),
-				clr,
-				false,
-			)
+			// Light fades with distance from the player; sensed monsters are
+			// drawn dark and explored tiles out of view drained of color, to be
+			// dimmed by the fog
+			shade := func(c color.RGBA) color.RGBA { return c }
+			switch {
+			case !player.FOVEnabled:
+			case withinFOV:
+				light := lightFalloff(math.Hypot(float64(x-player.X), float64(y-player.Y)), float64(radius))
+				shade = func(c color.RGBA) color.RGBA { return shadeColor(c, light) }
+			case sensed:
+				shade = darkenColor
+			default:
+				shade = rememberedColor
+			}
+			clr = shade(clr)
+
+			// Tiles in sight move with the animation clock
+			if sprite, ok := cellSprite(cell); ok && withinFOV {
+				_, clr = animate(sprite, x, y, clr)
+			}
+
+			cells = append(cells, CellView{X: x, Y: y, Cell: cell, Color: clr, Shade: shade,
+				InSight: withinFOV, Sensed: sensed, Remembered: remembered})
 		}
 	}
+	return cells
 }
+
+// Tints for cells in a special state, drawn over their type's usual color
+var (
+	shriekerColor    = color.RGBA{255, 90, 170, 255}
+	lockedChestColor = color.RGBA{190, 140, 20, 255}
+	sealedChestColor = color.RGBA{150, 120, 60, 255}
+	gateColor        = color.RGBA{110, 80, 50, 255}
+	pulledLeverColor = color.RGBA{120, 120, 120, 255}
+)
+
 func getCellColor(cellType CellType, visible bool) color.RGBA {
 	dimColor := color.RGBA{30, 30, 30, 255}
 
 	if !visible {
 		// Return dimmed default for hidden tiles
 		switch cellType {
-		case Monster, Treasure, Exit:
+		case Monster, Treasure, Exit, Satchel, Trap, Cage, Shrine, Merchant, Lever, Fountain, Altar, Scripted:
 			return dimColor
 		}
 	}
@@ -420,6 +688,24 @@ func getCellColor(cellType CellType, visible bool) color.RGBA {
 		return color.RGBA{0, 255, 0, 255}
 	case Exit:
 		return color.RGBA{0, 0, 255, 255}
+	case Satchel:
+		return color.RGBA{160, 90, 40, 255}
+	case Trap:
+		return color.RGBA{170, 60, 200, 255}
+	case Cage:
+		return color.RGBA{110, 110, 130, 255}
+	case Shrine:
+		return color.RGBA{200, 230, 255, 255}
+	case Merchant:
+		return color.RGBA{60, 200, 170, 255}
+	case Lever:
+		return color.RGBA{230, 130, 40, 255}
+	case Fountain:
+		return color.RGBA{70, 140, 255, 255}
+	case Altar:
+		return color.RGBA{255, 240, 180, 255}
+	case Scripted:
+		return color.RGBA{180, 90, 220, 255}
 	default:
 		return color.RGBA{255, 255, 255, 255} // fallback
 	}
@@ -434,7 +720,14 @@ func darkenColor(c color.RGBA) color.RGBA {
 	}
 }
 
-func (d *Dungeon) FindPath(start, goal Point) []Point {
+// NextFloorSpec previews the floor reached by taking this floor's exit
+func (d *Dungeon) NextFloorSpec() FloorSpec {
+	return NextFloorSpec(d.Seed, d.Level)
+}
+
+// FindPathBFS finds the path with the fewest steps, ignoring terrain costs.
+// It is kept alongside FindPath for reachability checks and step counting.
+func (d *Dungeon) FindPathBFS(start, goal Point) []Point {
 	type Node struct {
 		Pos   Point
 		Steps int
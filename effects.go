@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	hitFlashTicks  = 12 // Ticks a struck tile flashes for
+	shakeTicks     = 15 // Ticks the screen shakes after a big hit
+	shakeMagnitude = 4  // Pixels of screen shake from a big hit on the player
+	bossShake      = 3  // Pixels of screen shake from landing a hit on a boss
+	bigHitPercent  = 15 // A hit taking this share of max health shakes the screen
+	floatTicks     = 45 // Ticks a floating number lasts
+	floatRise      = 24 // Pixels a floating number drifts up over its life
+	floatStack     = 10 // Pixels between numbers that appear on one tile together
+	deathTicks     = 18 // Ticks a killed monster takes to shrink away
+	pickupTicks    = 24 // Ticks picked-up treasure takes to rise and fade
+)
+
+// Colors of floating numbers
+var (
+	floatDamage = color.RGBA{255, 70, 70, 255}
+	floatHeal   = color.RGBA{90, 230, 90, 255}
+	floatScore  = color.RGBA{255, 210, 60, 255}
+)
+
+// hitEffect is a timed highlight of a struck tile
+type hitEffect struct {
+	At    Point
+	Ticks int
+	Color color.RGBA // White for monsters, red for the player
+}
+
+// fadeEffect is something leaving a tile: a monster shrinking away as it
+// dies, or treasure rising off the floor as it is picked up
+type fadeEffect struct {
+	At     Point
+	Sprite int
+	Color  color.RGBA
+	Size   float64 // Share of the tile it filled on the map
+	Ticks  int
+	Rise   bool // Picked up rather than killed
+}
+
+// floatingNumber is a damage, heal or score number drifting up from a tile
+type floatingNumber struct {
+	At    Point
+	Text  string
+	Color color.RGBA
+	Ticks int
+	Lift  int // Starting height above the tile, so numbers on one tile don't overlap
+}
+
+// trackedHealth is what the renderer last saw of an entity
+type trackedHealth struct {
+	Health int
+	At     Point
+	Sprite int
+	Color  color.RGBA
+	Size   float64
+}
+
+// trackedTreasure is what the renderer last saw of a treasure cell
+type trackedTreasure struct {
+	Sprite int
+	Color  color.RGBA
+}
+
+// Effects watches entity health and turns damage into short visual effects.
+// It only observes the simulation, so combat code never has to know about it.
+type Effects struct {
+	hits     []hitEffect
+	floaters []floatingNumber
+	fades    []fadeEffect
+	shake    int     // Ticks of screen shake left
+	strength float64 // Pixels the screen shook by when the shake started
+	seen     map[*MonsterEntity]trackedHealth
+	treasure map[Point]trackedTreasure
+	player   int
+	score    int
+	enabled  bool    // Screen shake is off in reduced motion mode
+	scale    float64 // Screen shake intensity setting; 0 turns it off
+}
+
+func NewEffects(reducedMotion bool, shakeScale float64) *Effects {
+	return &Effects{seen: make(map[*MonsterEntity]trackedHealth), treasure: make(map[Point]trackedTreasure),
+		enabled: !reducedMotion, scale: shakeScale}
+}
+
+// Update compares health with the last tick, starts effects for any damage
+// and ages running effects. It runs once per simulated tick.
+func (e *Effects) Update(d *Dungeon, p *Player) {
+	seen := make(map[*MonsterEntity]trackedHealth, len(d.Monsters))
+	for _, m := range d.Monsters {
+		cell := d.Cells[m.Y][m.X]
+		now := trackedHealth{Health: m.Health, At: Point{m.X, m.Y}, Sprite: monsterSprites[cell.MonsterTier],
+			Color: cell.Species.Info().Color, Size: tierLooks[cell.MonsterTier].Size}
+		if before, ok := e.seen[m]; ok && now.Health < before.Health {
+			e.hits = append(e.hits, hitEffect{At: now.At, Ticks: hitFlashTicks, Color: color.RGBA{255, 255, 255, 255}})
+			e.float(now.At, fmt.Sprintf("-%d", before.Health-now.Health), floatDamage)
+			if m.Boss != nil {
+				e.Shake(bossShake)
+			}
+		}
+		seen[m] = now
+	}
+	// Monsters that vanished since last tick were killed: flash where they
+	// fell and shrink away
+	for m, before := range e.seen {
+		if _, ok := seen[m]; !ok {
+			e.hits = append(e.hits, hitEffect{At: before.At, Ticks: hitFlashTicks, Color: color.RGBA{255, 255, 255, 255}})
+			e.fades = append(e.fades, fadeEffect{At: before.At, Sprite: before.Sprite, Color: before.Color,
+				Size: before.Size, Ticks: deathTicks})
+			if before.Health > 0 {
+				e.float(before.At, fmt.Sprintf("-%d", before.Health), floatDamage)
+			}
+		}
+	}
+	e.seen = seen
+
+	// Treasure that vanished since last tick was picked up: it rises away
+	treasure := make(map[Point]trackedTreasure, len(e.treasure))
+	for y, row := range d.Cells {
+		for x, cell := range row {
+			if cell.Type == Treasure {
+				sprite, _ := cellSprite(cell)
+				treasure[Point{x, y}] = trackedTreasure{Sprite: sprite, Color: getCellColor(Treasure, true)}
+			}
+		}
+	}
+	for at, before := range e.treasure {
+		if _, ok := treasure[at]; !ok {
+			e.fades = append(e.fades, fadeEffect{At: at, Sprite: before.Sprite, Color: before.Color,
+				Size: 1, Ticks: pickupTicks, Rise: true})
+		}
+	}
+	e.treasure = treasure
+
+	for _, impact := range d.takeImpacts() {
+		e.Shake(impact.Strength)
+	}
+
+	at := Point{p.X, p.Y}
+	tracking := e.player > 0 // Nothing to compare against on the first tick
+	if lost := e.player - p.Health; e.player > 0 && lost > 0 {
+		e.hits = append(e.hits, hitEffect{At: at, Ticks: hitFlashTicks, Color: color.RGBA{255, 40, 40, 255}})
+		e.float(at, fmt.Sprintf("-%d", lost), floatDamage)
+		if lost*100 >= p.MaxHealth*bigHitPercent {
+			e.Shake(shakeMagnitude)
+		}
+	} else if tracking && lost < 0 {
+		e.float(at, fmt.Sprintf("+%d", -lost), floatHeal)
+	}
+	e.player = p.Health
+	if gained := p.Score - e.score; tracking && gained > 0 {
+		e.float(at, fmt.Sprintf("+%d", gained), floatScore)
+	}
+	e.score = p.Score
+
+	running := e.hits[:0]
+	for _, h := range e.hits {
+		if h.Ticks--; h.Ticks > 0 {
+			running = append(running, h)
+		}
+	}
+	e.hits = running
+
+	fading := e.fades[:0]
+	for _, f := range e.fades {
+		if f.Ticks--; f.Ticks > 0 {
+			fading = append(fading, f)
+		}
+	}
+	e.fades = fading
+
+	floating := e.floaters[:0]
+	for _, f := range e.floaters {
+		if f.Ticks--; f.Ticks > 0 {
+			floating = append(floating, f)
+		}
+	}
+	e.floaters = floating
+	if e.shake > 0 {
+		e.shake--
+	}
+}
+
+// Reset forgets tracked entities, used when the floor changes so departed
+// monsters don't flash as if killed
+func (e *Effects) Reset() {
+	e.seen = make(map[*MonsterEntity]trackedHealth)
+	e.treasure = make(map[Point]trackedTreasure)
+	e.hits = nil
+	e.fades = nil
+	e.floaters = nil
+}
+
+// float starts a number drifting up from a tile, above any that just
+// appeared there
+func (e *Effects) float(at Point, text string, clr color.RGBA) {
+	lift := 0
+	for _, f := range e.floaters {
+		if f.At == at && floatTicks-f.Ticks < floatTicks/3 {
+			lift = max(lift, f.Lift+floatStack)
+		}
+	}
+	e.floaters = append(e.floaters, floatingNumber{At: at, Text: text, Color: clr, Ticks: floatTicks, Lift: lift})
+}
+
+// Shake starts shaking the screen by up to the given number of pixels,
+// scaled by the intensity setting. A shake already running only grows.
+func (e *Effects) Shake(magnitude float64) {
+	if !e.enabled || e.scale <= 0 {
+		return
+	}
+	current := e.strength * float64(e.shake) / shakeTicks
+	e.strength = max(current, magnitude*e.scale)
+	e.shake = shakeTicks
+}
+
+// ShakeOffset returns the current screen shake in pixels: a random jitter
+// that dies down as the shake runs out
+func (e *Effects) ShakeOffset() (float64, float64) {
+	if e.shake == 0 {
+		return 0, 0
+	}
+	strength := e.strength * float64(e.shake) / shakeTicks
+	return strength * (2*rand.Float64() - 1), strength * (2*rand.Float64() - 1)
+}
+
+// Draw overlays the running hit effects that the player can see
+func (e *Effects) Draw(screen *ebiten.Image, d *Dungeon, p *Player) {
+	radius := p.EffectiveFOVRadius(d)
+	for _, h := range e.hits {
+		if p.FOVEnabled && !d.CanSee(Point{p.X, p.Y}, h.At, radius) {
+			continue
+		}
+		clr := h.Color
+		clr.A = uint8(200 * h.Ticks / hitFlashTicks)
+		vector.DrawFilledRect(screen, float32(h.At.x*tileSize), float32(h.At.y*tileSize),
+			float32(tileSize), float32(tileSize), premultiply(clr), false)
+	}
+
+	// Killed monsters shrink into the middle of their tile; picked-up
+	// treasure rises off it. Both fade as they go.
+	for _, f := range e.fades {
+		if p.FOVEnabled && !d.CanSee(Point{p.X, p.Y}, f.At, radius) {
+			continue
+		}
+		total := deathTicks
+		if f.Rise {
+			total = pickupTicks
+		}
+		left := float64(f.Ticks) / float64(total)
+		size, rise := f.Size*left, 0.0
+		if f.Rise {
+			size, rise = f.Size*(0.6+0.4*left), 0.75*(1-left)
+		}
+		clr := f.Color
+		clr.A = uint8(255 * left)
+		drawFading(screen, f.Sprite, f.At, size, rise, premultiply(clr))
+	}
+
+	// Numbers rise from the top of their tile and fade out over their last half
+	for _, f := range e.floaters {
+		if p.FOVEnabled && !d.CanSee(Point{p.X, p.Y}, f.At, radius) {
+			continue
+		}
+		age := floatTicks - f.Ticks
+		clr := f.Color
+		clr.A = uint8(255 * min(1, 2*float64(f.Ticks)/floatTicks))
+		x := f.At.x*tileSize + tileSize/2
+		y := f.At.y*tileSize - f.Lift - floatRise*age/floatTicks - ui.SizeSmall/2
+		ui.Text.Draw(screen, f.Text, x, y, ui.TextStyle{Size: ui.SizeSmall, Color: premultiply(clr), Align: ui.AlignCenter, Bold: true, Shadow: true})
+	}
+}
+
+// premultiply converts a straight-alpha color to the premultiplied form ebiten expects
+func premultiply(c color.RGBA) color.RGBA {
+	return color.RGBA{
+		R: uint8(uint16(c.R) * uint16(c.A) / 255),
+		G: uint8(uint16(c.G) * uint16(c.A) / 255),
+		B: uint8(uint16(c.B) * uint16(c.A) / 255),
+		A: c.A,
+	}
+}
+
+// drawFading draws a sprite, or a square in the flat-color mode, centered on
+// a tile at the given share of its size and raised by rise tiles
+func drawFading(screen *ebiten.Image, sprite int, at Point, size, rise float64, clr color.RGBA) {
+	side := float64(tileSize) * size
+	x := float64(at.x*tileSize) + (float64(tileSize)-side)/2
+	y := float64(at.y*tileSize) + (float64(tileSize)-side)/2 - rise*float64(tileSize)
+	if !spriteTiles {
+		vector.DrawFilledRect(screen, float32(x), float32(y), float32(side), float32(side), clr, false)
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(side/spriteSize, side/spriteSize)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(clr)
+	screen.DrawImage(sprites[sprite], op)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"image/color"
+)
+
+// EliteAffix marks a monster promoted to an elite and the twist it brings
+type EliteAffix int
+
+const (
+	AffixNone      EliteAffix = iota
+	AffixFast                 // Moves and strikes every turn
+	AffixArmored              // Shrugs off part of every hit and has more health
+	AffixVampiric             // Heals from the damage it deals
+	AffixSplitting            // Splits into two weaker copies when killed
+	numAffixes
+)
+
+func (a EliteAffix) String() string {
+	switch a {
+	case AffixFast:
+		return "fast"
+	case AffixArmored:
+		return "armored"
+	case AffixVampiric:
+		return "vampiric"
+	case AffixSplitting:
+		return "splitting"
+	default:
+		return ""
+	}
+}
+
+const (
+	eliteChance        = 12 // Percent of generated monsters promoted to elites
+	eliteArmorPercent  = 30 // Damage armored elites shrug off
+	eliteHealthPercent = 50 // Extra health of armored elites
+	eliteVampPercent   = 50 // Share of dealt damage vampiric elites heal
+	eliteXPMultiplier  = 3
+	eliteDropValue     = 15 // Treasure value per level dropped by elites
+)
+
+// eliteMarkerColor outlines elites so they stand out from their species
+var eliteMarkerColor = color.RGBA{255, 200, 40, 255}
+
+// eliteDrops are the treasure types elites can leave behind
+var eliteDrops = []TreasureType{TreasureGems, TreasureArtifact, TreasureWeapon}
+
+// MonsterName is the monster's species name, prefixed by its elite affix
+func (c Cell) MonsterName() string {
+	if c.Elite == AffixNone {
+		return c.Species.LocalName()
+	}
+	return tr("%s %s", tr(c.Elite.String()), c.Species.LocalName())
+}
+
+// MoveInterval returns the world turns between the monster's steps
+func (c Cell) MoveInterval() int {
+	if c.Elite == AffixFast {
+		return 1
+	}
+	return c.Species.Info().MoveInterval
+}
+
+// Resistances returns the monster's resistances, including elite armor
+func (c Cell) Resistances() Resistances {
+	species := c.Species.Info().Resist
+	if c.Elite != AffixArmored {
+		return species
+	}
+	armored := Resistances{}
+	for t := DamageType(0); t < numDamageTypes; t++ {
+		armored[t] = min(100, species[t]+eliteArmorPercent)
+	}
+	return armored
+}
+
+// monsterXP is the experience for killing a monster of the given cell
+func monsterXP(c Cell) int {
+	xp := 5 * c.InteractionLevel
+	if c.Elite != AffixNone {
+		xp *= eliteXPMultiplier
+	}
+	return xp
+}
+
+// killMonster removes a slain monster. Splitting elites leave two weaker
+// copies behind, other elites drop treasure. It returns a message describing
+// what was left, or "".
+func (d *Dungeon) killMonster(m *MonsterEntity, rng *RNG) string {
+	cell := d.Cells[m.Y][m.X]
+	d.RemoveMonsterAt(m.X, m.Y)
+	released := d.releaseGuard(m)
+
+	switch {
+	case cell.Elite == AffixSplitting:
+		spawn := Cell{Type: Monster, InteractionLevel: max(1, cell.InteractionLevel-1), Species: cell.Species}
+		spawn.MonsterTier = monsterTierForLevel(spawn.InteractionLevel)
+		spots := append([]Point{{m.X, m.Y}}, d.nearestEmptyCells(Point{m.X, m.Y}, 1, Point{m.X, m.Y})...)
+		for _, p := range spots {
+			d.Cells[p.y][p.x] = spawn
+			d.addMonster(p.x, p.y).AI.State = AIChase
+		}
+		return tr("The %s splits in two!", cell.Species.LocalName())
+	case cell.Elite != AffixNone:
+		drop := eliteDrops[rng.Stream(StreamLoot).Intn(len(eliteDrops))]
+		d.Cells[m.Y][m.X] = NewTreasureCell(eliteDropValue*cell.InteractionLevel, drop)
+		return tr("The %s drops some %s!", cell.MonsterName(), tr(string(drop)))
+	}
+	return released
+}
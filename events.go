@@ -0,0 +1,99 @@
+package main
+
+// EventKind names something that happened as the outcome of an interaction
+type EventKind int
+
+const (
+	EventMonsterKilled EventKind = iota
+	EventTreasureCollected
+	EventLevelDescended
+	EventPlayerDamaged
+	EventRoomEntered
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventMonsterKilled:
+		return "MonsterKilled"
+	case EventTreasureCollected:
+		return "TreasureCollected"
+	case EventLevelDescended:
+		return "LevelDescended"
+	case EventPlayerDamaged:
+		return "PlayerDamaged"
+	case EventRoomEntered:
+		return "RoomEntered"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is published on the bus once an interaction has been resolved, or
+// when the world does the same thing on its own, as when a monster hits the
+// player or a companion makes a kill. Source is the interaction that produced
+// it, so subscribers can type-switch for details such as which monster died
+// or what treasure was found; it is nil for a trap.
+type Event struct {
+	Kind   EventKind
+	Tick   int
+	Source Interactable
+	Result InteractionResult
+}
+
+// EventBus delivers events to the systems that subscribed to them, so
+// achievements, quests, audio and stats can react to interactions without
+// the interaction code knowing they exist.
+type EventBus struct {
+	subscribers map[EventKind][]func(Event)
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[EventKind][]func(Event))}
+}
+
+// Subscribe calls fn for every future event of the given kind
+func (b *EventBus) Subscribe(kind EventKind, fn func(Event)) {
+	b.subscribers[kind] = append(b.subscribers[kind], fn)
+}
+
+// Publish hands the event to its subscribers in the order they subscribed
+func (b *EventBus) Publish(e Event) {
+	for _, fn := range b.subscribers[e.Kind] {
+		fn(e)
+	}
+}
+
+// damagePlayer takes damage off the player's health and publishes it.
+// Source is the monster that dealt it, or nil.
+func (g *Game) damagePlayer(source Interactable, damage int) {
+	g.player.Health -= damage
+	g.interactionHandler.Publish(EventPlayerDamaged, source, InteractionResult{HealthChange: -damage})
+}
+
+// monsterKilled publishes the death of a monster killed by something other
+// than the player. Cell is the monster's cell from before it died.
+func (g *Game) monsterKilled(cell Cell, m *MonsterEntity) {
+	g.interactionHandler.Publish(EventMonsterKilled, NewMonsterInteraction(cell, m),
+		InteractionResult{RemoveEntity: true, EntityRemoved: Monster})
+}
+
+// interactionEvents lists the events an interaction outcome amounts to
+func interactionEvents(source Interactable, result InteractionResult) []EventKind {
+	var kinds []EventKind
+	switch source.(type) {
+	case *MonsterInteraction:
+		if result.RemoveEntity {
+			kinds = append(kinds, EventMonsterKilled)
+		}
+	case *TreasureInteraction:
+		kinds = append(kinds, EventTreasureCollected)
+	case *ExitInteraction:
+		kinds = append(kinds, EventLevelDescended)
+	case *RoomEvent:
+		kinds = append(kinds, EventRoomEntered)
+	}
+	if result.HealthChange < 0 {
+		kinds = append(kinds, EventPlayerDamaged)
+	}
+	return kinds
+}
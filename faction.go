@@ -0,0 +1,94 @@
+package main
+
+// Faction groups species that tolerate each other. Monsters of different
+// factions fight on sight.
+type Faction int
+
+const (
+	FactionUndead Faction = iota
+	FactionBeasts
+	FactionCultists
+)
+
+func (f Faction) String() string {
+	switch f {
+	case FactionUndead:
+		return "undead"
+	case FactionBeasts:
+		return "beasts"
+	case FactionCultists:
+		return "cultists"
+	default:
+		return "unknown"
+	}
+}
+
+const rivalSightRadius = 4 // How far monsters notice monsters of other factions
+
+// Faction returns the faction of the monster standing on the cell
+func (c Cell) Faction() Faction {
+	return c.Species.Info().Faction
+}
+
+// hostileTo reports whether two monsters belong to rival factions
+func (d *Dungeon) hostileTo(a, b *MonsterEntity) bool {
+	return d.Cells[a.Y][a.X].Faction() != d.Cells[b.Y][b.X].Faction()
+}
+
+// nearestRival returns the closest visible monster of another faction, or nil
+func (d *Dungeon) nearestRival(m *MonsterEntity) *MonsterEntity {
+	var best *MonsterEntity
+	bestDist := 0
+	for _, other := range d.Monsters {
+		if other == m || !d.hostileTo(m, other) ||
+			!d.CanSee(Point{m.X, m.Y}, Point{other.X, other.Y}, rivalSightRadius) {
+			continue
+		}
+		if dist := manhattan(Point{m.X, m.Y}, Point{other.X, other.Y}); best == nil || dist < bestDist {
+			best, bestDist = other, dist
+		}
+	}
+	return best
+}
+
+// adjacentRival returns a monster of another faction next to m, or nil
+func (d *Dungeon) adjacentRival(m *MonsterEntity) *MonsterEntity {
+	for _, dir := range monsterDirs {
+		if other := d.MonsterAt(m.X+dir.x, m.Y+dir.y); other != nil && d.hostileTo(m, other) {
+			return other
+		}
+	}
+	return nil
+}
+
+// monsterClash resolves one blow of a monster against a rival
+func (g *Game) monsterClash(attacker, target *MonsterEntity) {
+	d := g.dungeon
+	a, t := d.Cells[attacker.Y][attacker.X], d.Cells[target.Y][target.X]
+	species := a.Species.Info()
+	damage := t.Resistances().Apply(species.Damage+a.InteractionLevel*monsterDamagePerLvl, species.Attack)
+	target.Health -= damage
+	d.MakeNoise(Point{target.X, target.Y}, noiseCombat)
+
+	// The rival fights back from now on
+	target.AI.State = AIBrawl
+	target.AI.Rival = attacker
+
+	visible := d.CanSee(Point{g.player.X, g.player.Y}, Point{target.X, target.Y}, g.player.EffectiveFOVRadius(d))
+	if target.Health > 0 {
+		if visible {
+			g.interactionHandler.Record(LogCombat,
+				tr("The %s hits the %s for %d.", a.MonsterName(), t.MonsterName(), damage), 0)
+		}
+		return
+	}
+
+	msg := d.killMonster(target, g.rng)
+	g.monsterKilled(t, target)
+	if visible {
+		g.interactionHandler.Record(LogCombat, tr("The %s kills the %s!", a.MonsterName(), t.MonsterName()), 0)
+		if msg != "" {
+			g.interactionHandler.Record(LogEvent, msg, 0)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+)
+
+// FloorTheme gives a floor its name and wall tint
+type FloorTheme struct {
+	Name      string
+	WallColor color.RGBA
+}
+
+// LocalName is the theme's name in the player's language
+func (t FloorTheme) LocalName() string {
+	return tr(t.Name)
+}
+
+var floorThemes = []FloorTheme{
+	{"Catacombs", color.RGBA{0, 0, 0, 255}},
+	{"Flooded Caves", color.RGBA{5, 15, 30, 255}},
+	{"Fungal Grotto", color.RGBA{10, 25, 10, 255}},
+	{"Scorched Halls", color.RGBA{30, 10, 5, 255}},
+}
+
+// FloorModifier is an optional twist applied to a whole floor
+type FloorModifier int
+
+const (
+	ModifierNone     FloorModifier = iota
+	ModifierDark                   // One step darker than the depth alone would make it
+	ModifierInfested               // Half again as many monsters
+	ModifierHoard                  // Half again as much treasure
+)
+
+func (m FloorModifier) String() string {
+	switch m {
+	case ModifierNone:
+		return "None"
+	case ModifierDark:
+		return "Pitch Dark"
+	case ModifierInfested:
+		return "Infested"
+	case ModifierHoard:
+		return "Treasure Hoard"
+	default:
+		return "Unknown"
+	}
+}
+
+// FloorSpec holds everything needed to generate a floor. It is derived from
+// the floor's seed alone, so the next floor can be previewed before it exists.
+type FloorSpec struct {
+	Seed          int64
+	Level         int
+	Width, Height int
+	Theme         FloorTheme
+	Modifier      FloorModifier
+	Openness      int  // Percent of inner maze walls knocked through
+	Arena         bool // A single open floor with no exit, filled by waves instead
+}
+
+// NewFloorSpec builds the spec for a floor with fixed dimensions (the first
+// floor uses the size picked in the menu).
+func NewFloorSpec(seed int64, level, width, height int) FloorSpec {
+	// Use a stream separate from the maze generator so the spec rolls don't
+	// shift the layout
+	rng := streamRand(seed, "floor-spec")
+	spec := FloorSpec{
+		Seed:   seed,
+		Level:  level,
+		Width:  width,
+		Height: height,
+		Theme:  floorThemes[rng.Intn(len(floorThemes))],
+	}
+
+	// Seven floors in ten have no modifier
+	switch rng.Intn(10) {
+	case 7:
+		spec.Modifier = ModifierDark
+	case 8:
+		spec.Modifier = ModifierInfested
+	case 9:
+		spec.Modifier = ModifierHoard
+	}
+	return spec
+}
+
+// NextFloorSpec derives the spec of the floor below one with the given seed,
+// including its randomised dimensions.
+func NextFloorSpec(seed int64, level int) FloorSpec {
+	nextSeed := nextFloorSeed(seed, level)
+	rng := streamRand(nextSeed, "floor-size")
+	width := 40 + rng.Intn(30) // 40–69
+	height := 12 + rng.Intn(8) // 12–19
+	return NewFloorSpec(nextSeed, level+1, width, height)
+}
+
+// nextFloorSeed chains floor seeds so a whole run follows from its first seed
+func nextFloorSeed(seed int64, level int) int64 {
+	return seed*6364136223846793005 + 1442695040888963407 + int64(level)
+}
+
+// Darkness returns the FOV radius reduction for the floor
+func (s FloorSpec) Darkness() int {
+	darkness := darknessForLevel(s.Level)
+	if s.Modifier == ModifierDark {
+		darkness++
+	}
+	return darkness
+}
+
+// SizeLabel describes the floor area in broad terms
+func (s FloorSpec) SizeLabel() string {
+	area := s.Width * s.Height
+	switch {
+	case area < 600:
+		return tr("Small")
+	case area < 1000:
+		return tr("Medium")
+	default:
+		return tr("Large")
+	}
+}
+
+// DangerRating rates the floor from 1 (safe) to 5 (deadly)
+func (s FloorSpec) DangerRating() int {
+	rating := 1 + s.Level/2
+	if s.Modifier == ModifierInfested || s.Modifier == ModifierDark {
+		rating++
+	}
+	if rating > 5 {
+		rating = 5
+	}
+	return rating
+}
+
+// Forecast returns a multi-line summary of the floor for the exit tooltip
+func (s FloorSpec) Forecast() string {
+	danger := strings.Repeat("*", s.DangerRating()) + strings.Repeat(".", 5-s.DangerRating())
+	return tr("Theme: %s\nSize: %s (%dx%d)\nModifier: %s\nDanger: %s",
+		s.Theme.LocalName(), s.SizeLabel(), s.Width, s.Height, tr(s.Modifier.String()), danger)
+}
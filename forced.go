@@ -0,0 +1,150 @@
+package main
+
+const (
+	knockbackPercent  = 35 // A hit taking this share of the target's max health knocks it back
+	knockbackDistance = 1  // Tiles a heavy hit pushes
+	bossKnockback     = 2  // Bosses hit hard enough to push further
+	wallSlamDamage    = 4  // Bonus damage for being knocked into something solid
+	blinkRange        = 6  // Tiles a blinking monster can teleport across
+	abilityCooldown   = 5  // World turns between uses of a monster ability
+)
+
+// Ability is a special move some species use on the player
+type Ability int
+
+const (
+	AbilityNone  Ability = iota
+	AbilityPull          // Shots drag the player a tile closer
+	AbilityBlink         // Teleports next to the player
+)
+
+// stepToward returns the orthogonal unit step that best heads from a to b
+func stepToward(a, b Point) Point {
+	dx, dy := b.x-a.x, b.y-a.y
+	switch {
+	case dx == 0 && dy == 0:
+		return Point{}
+	case abs(dx) >= abs(dy):
+		return Point{sign(dx), 0}
+	default:
+		return Point{0, sign(dy)}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// forcedMove slides something from start up to dist tiles in dir. It stops in
+// front of walls and anything that can't be entered, and reports where it
+// ended up and whether it slammed into an obstacle on the way.
+func (d *Dungeon) forcedMove(start, dir Point, dist int, canEnter func(Point) bool) (Point, bool) {
+	pos := start
+	for i := 0; i < dist; i++ {
+		next := Point{pos.x + dir.x, pos.y + dir.y}
+		if !inBounds(next.x, next.y, d.Width, d.Height) || !canEnter(next) {
+			return pos, true
+		}
+		pos = next
+	}
+	return pos, false
+}
+
+// playerCanBeForcedInto reports whether the player can be pushed or pulled onto p
+func (d *Dungeon) playerCanBeForcedInto(p Point, player *Player) bool {
+	t := d.Cells[p.y][p.x].Type
+	return (t == Empty || t == Entrance) && !player.Companion.Occupies(p)
+}
+
+// knockMonster pushes a monster away from the player after a heavy hit. A
+// monster slammed into something takes wallSlamDamage; it returns a message
+// describing the knockback.
+func (d *Dungeon) knockMonster(m *MonsterEntity, player *Player, dist int) string {
+	dir := stepToward(Point{player.X, player.Y}, Point{m.X, m.Y})
+	to, slammed := d.forcedMove(Point{m.X, m.Y}, dir, dist, func(p Point) bool {
+		return d.canMonsterEnter(p, player)
+	})
+	name := d.Cells[m.Y][m.X].MonsterName()
+	if to.x != m.X || to.y != m.Y {
+		d.moveMonster(m, to)
+	}
+	if !slammed {
+		return tr("The %s is knocked back!", name)
+	}
+	m.Health -= wallSlamDamage
+	d.Impact(to, slamImpact)
+	return tr("The %s is slammed into the wall for %d!", name, wallSlamDamage)
+}
+
+// knockPlayer pushes the player away from a monster after a heavy blow
+func (g *Game) knockPlayer(m *MonsterEntity, dist int) {
+	p := g.player
+	dir := stepToward(Point{m.X, m.Y}, Point{p.X, p.Y})
+	to, slammed := g.dungeon.forcedMove(Point{p.X, p.Y}, dir, dist, func(pt Point) bool {
+		return g.dungeon.playerCanBeForcedInto(pt, p)
+	})
+	p.X, p.Y = to.x, to.y
+	p.Path = nil
+	if slammed {
+		g.damagePlayer(NewMonsterInteraction(g.dungeon.Cells[m.Y][m.X], m), wallSlamDamage)
+		g.dungeon.Impact(to, slamImpact)
+		g.interactionHandler.Record(LogCombat, tr("You are slammed into the wall for %d!", wallSlamDamage), -wallSlamDamage)
+		return
+	}
+	g.interactionHandler.Record(LogCombat, tr("You are knocked back!"), 0)
+}
+
+// useAbility lets a monster with a special move use it when it is ready. It
+// reports whether the monster spent its turn on it.
+func (g *Game) useAbility(m *MonsterEntity) bool {
+	d, p := g.dungeon, g.player
+	cell := d.Cells[m.Y][m.X]
+	if cell.Species.Info().Ability != AbilityBlink || m.AI.State != AIChase || isAdjacent(m.X, m.Y, p.X, p.Y) {
+		return false
+	}
+	if m.ability > 0 {
+		m.ability--
+		return false
+	}
+	if !d.CanSee(Point{m.X, m.Y}, Point{p.X, p.Y}, blinkRange) {
+		return false
+	}
+
+	for _, dir := range monsterDirs {
+		to := Point{p.X + dir.x, p.Y + dir.y}
+		if d.canMonsterEnter(to, p) {
+			m.ability = abilityCooldown
+			d.moveMonster(m, to)
+			g.interactionHandler.Record(LogCombat, tr("The %s blinks next to you!", cell.MonsterName()), 0)
+			return true
+		}
+	}
+	return false
+}
+
+// pullPlayer drags the player a tile towards a monster
+func (g *Game) pullPlayer(m *MonsterEntity) {
+	p := g.player
+	dir := stepToward(Point{p.X, p.Y}, Point{m.X, m.Y})
+	to, _ := g.dungeon.forcedMove(Point{p.X, p.Y}, dir, 1, func(pt Point) bool {
+		return g.dungeon.playerCanBeForcedInto(pt, p)
+	})
+	if to.x == p.X && to.y == p.Y {
+		return
+	}
+	p.X, p.Y = to.x, to.y
+	p.Path = nil
+	g.interactionHandler.Record(LogCombat,
+		tr("The %s drags you closer!", g.dungeon.Cells[m.Y][m.X].MonsterName()), 0)
+}
+
+// heavyHit reports whether damage is big enough to knock back a target
+func heavyHit(damage, maxHealth int) bool {
+	return damage*100 >= maxHealth*knockbackPercent
+}
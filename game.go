@@ -1,12 +1,14 @@
 package main
 
 import (
-	"fmt"
+	"image"
 	"image/color"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
 )
 
 type Game struct {
@@ -15,62 +17,246 @@ type Game struct {
 	hoverX, hoverY     int
 	pathToHover        [][2]int
 	interactionHandler *InteractionHandler
+	clock              *GameClock
+	rng                *RNG
+	turns              *TurnScheduler
+	spawner            *Spawner
 	marginX            int
 	marginY            int
+	autoTileSize       bool // Refit tileSize to the screen whenever the floor changes size
+	runSeed            int64
+	settings           GameSettings // What the run was started with, kept for saving it
+	autosaveTurns      int          // World turns between autosaves; 0 saves only on new floors
+	lastAutosave       int          // World turn the run last autosaved on
+	floorSeed          int64        // Seed of the floor enterFloor last ran for
+	casualMode         bool
+	gameOver           bool
+	won                bool // The run ended by escaping the deepest floor
+	stats              *RunStats
+	restartRequested   bool // Asks MainGame to start a fresh run
+	quitRequested      bool // Asks MainGame to go back to the main menu
+	pause              *PauseMenu
+	reducedMotion      bool // Replace timing minigames with dice rolls
+	disarm             *DisarmMinigame
+	inventory          *InventoryMenu // Open inventory screen; the world waits while it is up
+	panels             PanelStack     // Open panels and menus, the last opened on top
+	projectiles        []*Projectile
+	effects            *Effects
+	renderer           *Renderer // Layers the frame is drawn in
+	post               *PostFX   // Optional shader effects
+	difficulty         DifficultyCurve
+	arena              *Arena // Endless horde state; nil in a normal dungeon run
+	quests             *QuestLog
+	showGrid           bool           // Are grid lines drawn over the dungeon
+	dragging           bool           // Is the mouse dragging the view around
+	dragFrom           image.Point    // Cursor position at the last tick of the drag
+	looking            bool           // Is the look cursor pointing at tiles instead of the mouse
+	lastMouse          image.Point    // Mouse position last tick, to notice it moving
+	selected           *Point         // Tile picked with a click, whose path is previewed until travel starts
+	lastClick          int            // Tick of the last click on the dungeon, to spot double clicks
+	travel             *Point         // Tile the player is auto-travelling to; nil when not travelling
+	travelSeen         int            // Monsters in sight when the travel set off; one more stops it
+	shop               *ShopMenu      // Open merchant menu; the world waits while it is up
+	confirmDanger      bool           // Ask before stepping into a monster or the exit
+	confirm            *ConfirmPrompt // Open danger prompt; the world waits while it is up
+	interactKey        bool           // Non-combat interactions wait for E instead of a bump
+	debug              DebugOverlay
+	highScoreRank      int              // Place the finished run took in the high score table; 0 if none
+	transition         *FloorTransition // Fade to the next floor while it generates; the world waits
+	screenW, screenH   int              // Screen size from the last Layout
+	camera             Camera
+	world              *ebiten.Image // Offscreen image the dungeon is drawn on, kept between frames
 }
 
-func NewGame(width, height int) *Game {
-	dungeon := NewDungeon(width, height, 1)
-	player := NewPlayer(dungeon.Entrance)
+const (
+	defaultMarginX = 20
+	defaultMarginY = 40
+
+	// autoTileSize is the tile size option that fits the dungeon to the window
+	autoTileSize    = 0
+	minTileSize     = 4
+	maxAutoTileSize = 48
+)
+
+// fitTileSize returns the largest tile size that fits a dungeon of the given
+// dimensions into the screen, leaving room for the margins around it.
+func fitTileSize(screenW, screenH, dungeonW, dungeonH int) int {
+	if dungeonW <= 0 || dungeonH <= 0 {
+		return minTileSize
+	}
+	size := min((screenW-2*defaultMarginX)/dungeonW, (screenH-2*defaultMarginY)/dungeonH)
+	return crispTileSize(max(minTileSize, min(size, maxAutoTileSize)))
+}
+
+// startFloorSpec is the spec of the first floor of a run. Every floor of the
+// run follows from runSeed.
+func startFloorSpec(settings GameSettings, runSeed int64) FloorSpec {
+	if settings.Arena {
+		return NewArenaSpec(runSeed, settings.StartLevel, settings.DungeonWidth, settings.DungeonHeight)
+	}
+	return NewFloorSpec(runSeed, settings.StartLevel, settings.DungeonWidth, settings.DungeonHeight)
+}
 
-	// Create the interaction handler
-	interactionHandler := NewInteractionHandler()
+// NewGame starts a run with the given settings on its first floor, generated
+// from startFloorSpec
+func NewGame(settings GameSettings, runSeed int64, dungeon *Dungeon) *Game {
+	g := newGame(settings, runSeed, dungeon)
+	g.enterFloor()
+	return g
+}
 
-	// Register interactions for different cell types
-	interactionHandler.Register(Monster, NewMonsterInteraction(1))            // Default level 1
-	interactionHandler.Register(Treasure, NewTreasureInteraction(10, "gold")) // Default 10 gold
-	interactionHandler.Register(Exit, NewExitInteraction(2))                  // Go to level 2
+// newGame sets up a run on the given floor without entering it, which a
+// loaded save does its own way
+func newGame(settings GameSettings, runSeed int64, dungeon *Dungeon) *Game {
+	player := NewPlayer(dungeon.Entrance)
+	player.FOVEnabled = settings.EnableFOV
+	player.Name = settings.PlayerName
+	player.Color = settings.PlayerColor
+	clock := NewGameClock()
+	rng := NewRNG(runSeed)
 
-	return &Game{
+	// Create the interaction handler with difficulty modifiers
+	turns := NewTurnScheduler(settings.TurnBased)
+	interactionHandler := NewInteractionHandler(clock, turns, rng)
+
+	g := &Game{
 		dungeon:            dungeon,
 		player:             player,
 		interactionHandler: interactionHandler,
-		marginX:            20,
-		marginY:            40,
+		clock:              clock,
+		rng:                rng,
+		turns:              turns,
+		spawner:            NewSpawner(settings.Difficulty.Monster.At(dungeon.Level)),
+		difficulty:         settings.Difficulty,
+		quests:             NewQuestLog(interactionHandler.Events),
+		stats:              NewRunStats(interactionHandler.Events),
+		effects:            NewEffects(settings.ReducedMotion, settings.ShakeIntensity),
+		post:               NewPostFX(settings),
+		renderer:           newGameRenderer(),
+		marginX:            defaultMarginX,
+		marginY:            defaultMarginY,
+		autoTileSize:       settings.AutoTileSize,
+		runSeed:            runSeed,
+		settings:           settings,
+		autosaveTurns:      settings.AutosaveTurns,
+		casualMode:         settings.CasualMode,
+		confirmDanger:      settings.ConfirmDanger,
+		interactKey:        settings.InteractKey,
+		reducedMotion:      settings.ReducedMotion,
+		screenW:            screenWidth,
+		screenH:            screenHeight,
+		camera:             NewCamera(settings.CameraEasing),
+	}
+	g.camera.Still = settings.ReducedMotion
+	if settings.Arena {
+		g.arena = NewArena(clock.Ticks)
 	}
+	return g
 }
 
-// You'll also need to adjust the Update method to account for the margins when calculating hover position
+// enterFloor runs once whenever a new floor becomes the current dungeon
+func (g *Game) enterFloor() {
+	g.floorSeed = g.dungeon.Seed
+	if g.arena == nil && g.dungeon.Level > victoryDepth {
+		g.win()
+		return
+	}
+	g.applyDifficulty()
+	g.spawner.Reset()
+	g.effects.Reset()
+	g.camera.EnterFloor()
+	if g.arena == nil {
+		g.camera.TourTo(g.dungeon.Exit[0], g.dungeon.Exit[1]) // Show where the floor leads
+	}
+	g.bringCompanion()
+	g.quests.EnterFloor(g.dungeon.Seed)
+	g.setUpShrine()
+	g.setUpShop()
+	g.attachScripts()
+	g.interactionHandler.Log.Add(LogEntry{
+		Tick: g.clock.Ticks,
+		Kind: LogFloor,
+		Text: tr("Entered dungeon level %d: %s", g.dungeon.Level, g.dungeon.Theme.LocalName()),
+	})
+
+	if !g.casualMode {
+		return
+	}
 
-func (g *Game) Update() error {
+	// Put back the satchel lost on this floor by an earlier run
+	satchel, err := loadLostSatchel()
+	if err != nil {
+		g.interactionHandler.AddMessage(tr("Could not load lost satchel: %v", err))
+		return
+	}
+	if satchel == nil || satchel.FloorSeed != g.dungeon.Seed {
+		return
+	}
+	if !inBounds(satchel.X, satchel.Y, g.dungeon.Width, g.dungeon.Height) ||
+		g.dungeon.Cells[satchel.Y][satchel.X].Type != Empty {
+		return
+	}
 
-	mouseX, mouseY := ebiten.CursorPosition()
+	g.dungeon.Cells[satchel.Y][satchel.X] = Cell{Type: Satchel, InteractionLevel: satchel.Gold,
+		Interaction: NewSatchelInteraction(satchel)}
+	g.interactionHandler.AddMessage(tr("You sense your lost satchel somewhere on this floor..."))
+}
 
-	// Adjust mouse coordinates to account for margins
-	adjustedMouseX := mouseX - g.marginX
-	adjustedMouseY := mouseY - g.marginY
+// die ends the run. In casual mode part of the loot stays behind in a satchel.
+func (g *Game) die() {
+	g.gameOver = true
+	g.player.Path = nil
+	g.recordHighScore()
+	g.forgetSave()
+
+	if g.arena != nil {
+		g.arena.EndTick = g.clock.Ticks
+		g.interactionHandler.Record(LogEvent, tr("Survived %d waves in %s.",
+			g.arena.Wave, g.arena.SurvivalTime(g.clock.Ticks)), 0)
+		return
+	}
+	if !g.casualMode {
+		return
+	}
 
-	// Convert to tile coordinates (if within the valid area)
-	if adjustedMouseX >= 0 && adjustedMouseY >= 0 {
-		g.hoverX, g.hoverY = adjustedMouseX/tileSize, adjustedMouseY/tileSize
-	} else {
-		// Mouse is in the margin area
-		g.hoverX, g.hoverY = -1, -1
+	satchel := NewLostSatchel(g.runSeed, g.dungeon, g.player)
+	if err := saveLostSatchel(satchel); err != nil {
+		g.interactionHandler.AddMessage(tr("Could not save lost satchel: %v", err))
+		return
 	}
+	g.interactionHandler.AddMessage(tr("You dropped a satchel with %d gold and %d items.",
+		satchel.Gold, len(satchel.Items)))
+}
 
-	// Calculate path to hover position
-	if g.hoverX >= 0 && g.hoverX < g.dungeon.Width && g.hoverY >= 0 && g.hoverY < g.dungeon.Height {
-		// Get the path from player position to hover position
-		path := g.dungeon.FindPath(Point{g.player.X, g.player.Y}, Point{g.hoverX, g.hoverY})
+// viewport is the screen area the dungeon is shown in, inside the margins
+func (g *Game) viewport() image.Rectangle {
+	return image.Rect(g.marginX, g.marginY, g.screenW-g.marginX, g.screenH-g.marginY)
+}
+
+func (g *Game) Update() error {
+	animTicks++
+	g.camera.Follow(g.viewport(), g.player.X, g.player.Y, g.dungeon)
+	g.dungeon.ResetSight()
+	if g.transition != nil {
+		g.updateTransition()
+		return nil
+	}
+
+	// Find the tile pointed at with the mouse or the look cursor
+	g.updatePointer()
+
+	// Calculate path to the selected or hovered tile
+	if target := g.pathTarget(); inBounds(target.x, target.y, g.dungeon.Width, g.dungeon.Height) {
+		// Get the path from player position to the target
+		path := g.dungeon.FindPath(Point{g.player.X, g.player.Y}, target)
 
 		// Convert path to [][2]int format for rendering
 		g.pathToHover = nil
 		if path != nil {
 			for i := 1; i < len(path); i++ { // Skip the first point (player's position)
 				point := path[i]
-				// Check if we should stop at this point (monster or treasure)
-				if g.dungeon.Cells[point.y][point.x].Type == Monster ||
-					g.dungeon.Cells[point.y][point.x].Type == Treasure {
+				// Check if we should stop at this point (monster, treasure, ...)
+				if g.dungeon.Cells[point.y][point.x].StopsMovement() {
 					// Add this point to the path (so it's highlighted)
 					g.pathToHover = append(g.pathToHover, [2]int{point.x, point.y})
 					break
@@ -82,126 +268,205 @@ func (g *Game) Update() error {
 		g.pathToHover = nil
 	}
 
+	HandleInput(g, g.player)
+	if g.dungeon.ExitTaken {
+		// The next floor, including its random dimensions, follows from this floor's seed
+		g.transition = newFloorTransition(g.dungeon.NextFloorSpec())
+		return nil
+	}
+	if g.panels.Modal() {
+		return nil
+	}
+
+	// Nothing below runs while the clock is paused, which freezes movement
+	// cooldowns and message expiry along with it
+	if !g.clock.Advance() {
+		return nil
+	}
+
 	// Update the message timestamps
 	g.interactionHandler.UpdateMessages()
 
-	HandleInput(g, g.player)
+	if g.disarm != nil {
+		g.disarm.Update()
+	}
+	g.updateProjectiles()
+
+	prevX, prevY := g.player.X, g.player.Y
 	g.player.Update(g.dungeon)
 
-	// Update interaction logic for cell types that change each level
-	// This ensures that when a new level is generated, the interaction
-	// system has the correct values for each cell type
-	for y := 0; y < g.dungeon.Height; y++ {
-		for x := 0; x < g.dungeon.Width; x++ {
-			cell := g.dungeon.Cells[y][x]
-
-			switch cell.Type {
-			case Monster:
-				g.interactionHandler.Register(Monster, NewMonsterInteraction(cell.InteractionLevel))
-			case Treasure:
-				g.interactionHandler.Register(Treasure, NewTreasureInteraction(cell.InteractionLevel, cell.TreasureType))
-			case Exit:
-				g.interactionHandler.Register(Exit, NewExitInteraction(g.dungeon.Level+1))
-			}
+	// Hidden traps go off when stepped on; a step also gives a chance to spot nearby ones
+	if g.player.X != prevX || g.player.Y != prevY {
+		g.turns.PlayerActed()
+		g.camera.Recenter()
+		g.camera.StopTour()
+		if g.dungeon.Cells[g.player.Y][g.player.X].Type == Trap {
+			g.triggerTrap(g.player.X, g.player.Y)
 		}
+		g.spotAdjacentTraps()
+	}
+
+	for n := g.turns.Tick(); n > 0; n-- {
+		g.advanceWorld()
+	}
+
+	if g.dungeon.Seed != g.floorSeed {
+		g.enterFloor()
+	}
+	g.effects.Update(g.dungeon, g.player)
+	g.stats.ObserveGold(g.player.Gold)
+	if g.player.Health <= 0 && !g.gameOver {
+		g.die()
+	}
+	if g.autosaveTurns > 0 && !g.gameOver && g.turns.Turn-g.lastAutosave >= g.autosaveTurns {
+		g.autosave()
 	}
 
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	defer g.debug.RecordDraw(time.Now())
+	screenW, screenH := screen.Bounds().Dx(), screen.Bounds().Dy()
 
-	// Create a rendering context with translation for the margins
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(float64(g.marginX), float64(g.marginY))
-
-	// Use a sub-screen approach to implement the margin
-	dungeonScreen := ebiten.NewImage(screenWidth-2*g.marginX, screenHeight-2*g.marginY)
-
-	// Draw dungeon to the sub-screen
-	g.dungeon.Draw(dungeonScreen, g.player)
-
-	// Draw path to hover before drawing the player
-	if len(g.pathToHover) > 0 {
-		for i, p := range g.pathToHover {
-			// Reverse the gradient calculation, the closer tiles are more visible
-			gradient := float32(len(g.pathToHover)-i) / float32(len(g.pathToHover)) // Closer tiles have higher gradient
-			shade := uint8(60 + 40*gradient)
-			// Gradually fade the alpha as the path goes further
-			alpha := uint8(120 - 50*gradient)
-
-			pathColor := color.RGBA{
-				shade,
-				shade,
-				uint8(shade + 10),
-				alpha,
-			}
+	// Whole-screen effects need the frame drawn offscreen first
+	out := screen
+	screen = g.post.Frame(out, g.player)
 
-			vector.DrawFilledRect(
-				dungeonScreen,
-				float32(p[0]*tileSize),
-				float32(p[1]*tileSize),
-				float32(tileSize),
-				float32(tileSize),
-				pathColor,
-				false,
-			)
-		}
+	// Later floors have random dimensions, so keep refitting in Auto mode
+	if g.autoTileSize {
+		tileSize = fitTileSize(screenW, screenH, g.dungeon.Width, g.dungeon.Height)
 	}
 
-	// Draw player on the sub-screen
-	g.player.Draw(dungeonScreen)
+	// The dungeon layers are drawn whole in the dungeon's own pixels, then
+	// placed on screen through the camera and clipped to the viewport. The
+	// UI layer goes on top in screen pixels.
+	worldW, worldH := max(1, g.dungeon.Width*tileSize), max(1, g.dungeon.Height*tileSize)
+	if g.world == nil || g.world.Bounds().Dx() != worldW || g.world.Bounds().Dy() != worldH {
+		g.world = ebiten.NewImage(worldW, worldH)
+	}
+	g.world.Clear()
+	frame := &Frame{Game: g, Cells: g.dungeon.View(g.player)}
+	g.renderer.DrawLayers(g.world, frame, LayerTerrain, LayerEffects)
 
-	// Draw the sub-screen to the main screen with margins
-	screen.DrawImage(dungeonScreen, op)
+	op := &ebiten.DrawImageOptions{GeoM: g.camera.GeoM()}
+	op.GeoM.Translate(g.effects.ShakeOffset())
+	g.post.DrawWorld(screen.SubImage(g.camera.View).(*ebiten.Image), g.world, op, g.player, g.dungeon)
+	g.renderer.DrawLayers(screen, frame, LayerUI, LayerUI)
+
+	g.post.Present(out, screen, g.player)
+	g.drawDebugOverlay(out)
+}
 
-	// Highlight the hovered tile (needs to be adjusted for margins)
-	if g.hoverX < g.dungeon.Width && g.hoverY < g.dungeon.Height {
+// drawHover outlines the tile under the mouse
+func (g *Game) drawHover(screen *ebiten.Image) {
+	if inBounds(g.hoverX, g.hoverY, g.dungeon.Width, g.dungeon.Height) {
+		hoverX, hoverY := g.camera.WorldToScreen(g.hoverX, g.hoverY)
+		size := float32(g.camera.TileSize())
+		clr, thickness := color.RGBA{255, 255, 255, 180}, float32(1.5)
+		if g.looking {
+			clr, thickness = ui.ColorFocus, 2.5 // The look cursor stands out like focused widgets
+		}
 		vector.StrokeRect(
 			screen,
-			float32(g.hoverX*tileSize+g.marginX),
-			float32(g.hoverY*tileSize+g.marginY),
-			float32(tileSize),
-			float32(tileSize),
-			1.5, // thickness
-			color.RGBA{255, 255, 255, 180},
+			float32(hoverX),
+			float32(hoverY),
+			size,
+			size,
+			thickness,
+			clr,
 			false,
 		)
+	}
+}
 
-		// Show info about the hovered cell (adjusted for margins)
-		if g.hoverX >= 0 && g.hoverY >= 0 && g.hoverX < g.dungeon.Width && g.hoverY < g.dungeon.Height {
-			cell := g.dungeon.Cells[g.hoverY][g.hoverX]
-			var cellInfo string
-
-			switch cell.Type {
-			case Monster:
-				cellInfo = fmt.Sprintf("Monster (Level %d)", cell.InteractionLevel)
-			case Treasure:
-				cellInfo = fmt.Sprintf("%s (Value %d)", cell.TreasureType, cell.InteractionLevel)
-			case Exit:
-				cellInfo = fmt.Sprintf("Exit to Level %d", cell.InteractionLevel)
-			case Entrance:
-				cellInfo = "Entrance"
-			case Empty:
-				cellInfo = "Empty"
-			case Wall:
-				cellInfo = "Wall"
-			}
-
-			ebitenutil.DebugPrintAt(screen, cellInfo, g.hoverX*tileSize+g.marginX, g.hoverY*tileSize+g.marginY-10)
-		}
+// drawPanIndicator points the way back to the player while the view is
+// panned away from them, and names the key that brings it back
+func (g *Game) drawPanIndicator(screen *ebiten.Image) {
+	if !g.camera.Panned() {
+		return
 	}
+	view := g.camera.View
+	x, y := g.camera.WorldToScreen(g.player.X, g.player.Y)
+	size := g.camera.TileSize()
+	if cx, cy := x+size/2, y+size/2; !image.Pt(int(cx), int(cy)).In(view) {
+		drawEdgeArrow(screen, view, cx, cy, g.player.Color)
+	}
+	ui.Text.Draw(screen, tr("%s: back to the player", bindings.Key(ActionRecenter)), (view.Min.X+view.Max.X)/2, view.Max.Y-20,
+		ui.TextStyle{Size: ui.SizeSmall, Align: ui.AlignCenter, Shadow: true})
+}
+
+// drawHUD draws the player's stats along the top of the screen, with the
+// alarm, arena and boss bars and the pause banner
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	screenW := screen.Bounds().Dx()
 
 	// Display player stats (at the top with some padding)
 	statY := 10
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Health: %d/%d, Score: %d | Dungeon Level: %d",
-		g.player.Health, g.player.MaxHealth, g.player.Score, g.dungeon.Level), 10, statY)
+	statX := g.drawHUDBars(screen, statY)
+	hud := ui.TextStyle{Shadow: true}
+	ui.Text.Draw(screen, tr("%s | Score: %d, Gold: %d | Dungeon Level: %d | Turn: %d",
+		g.player.Name, g.player.Score, g.player.Gold, g.dungeon.Level, g.turns.Turn), statX, statY, hud)
+	ui.Text.Draw(screen, tr("%s: controls and map legend", bindings.Key(ActionHelp)), screenW-10, statY,
+		ui.TextStyle{Size: ui.SizeSmall, Color: color.RGBA{170, 170, 190, 255}, Align: ui.AlignRight, Shadow: true})
 	statY += 20
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Player Level: %d | Defense: %d | Luck: %d",
-		g.player.Level, g.player.Defense, g.player.Luck), 10, statY)
+	if g.dungeon.AlarmActive() {
+		ui.Text.Draw(screen, tr("ALARM! (%d turns)", g.dungeon.AlarmTurns),
+			screen.Bounds().Dx()/2, 26, ui.TextStyle{Color: SeverityDanger.Color(), Align: ui.AlignCenter, Bold: true, Shadow: true})
+	}
+
+	lightInfo := tr("Light: %d", g.player.EffectiveFOVRadius(g.dungeon))
+	if g.player.TorchTurns > 0 {
+		lightInfo += tr(" (torch %d turns)", g.player.TorchTurns)
+	}
+	if g.player.Sneaking {
+		lightInfo += tr(" | Sneaking (%s)", bindings.Key(ActionSneak))
+	}
+	for _, e := range g.player.Effects {
+		lightInfo += " | " + e.String()
+	}
+	ui.Text.Draw(screen, tr("Defense: %d | Luck: %d | %s",
+		g.player.EffectiveDefense(), g.player.EffectiveLuck(), lightInfo), 10, statY, hud)
+
+	if g.arena != nil {
+		ui.Text.Draw(screen, tr("Arena wave %d (score x%d) | Survived %s",
+			g.arena.Wave, g.arena.Multiplier(), g.arena.SurvivalTime(g.clock.Ticks)),
+			screen.Bounds().Dx()/2, 26, ui.TextStyle{Align: ui.AlignCenter, Shadow: true})
+	}
+
+	if boss := g.dungeon.ActiveBoss(); boss != nil {
+		drawBossBar(screen, boss)
+	}
+
+	if g.clock.Paused {
+		ui.Text.Draw(screen, tr("PAUSED - press %s to resume", bindings.Key(ActionPause)),
+			screen.Bounds().Dx()/2, 8, ui.TextStyle{Size: ui.SizeLarge, Align: ui.AlignCenter, Bold: true, Shadow: true})
+	}
+}
+
+// drawOverlays draws the open panels and menus in the order they were
+// opened, so the one on top, which takes the input, is drawn over the rest
+func (g *Game) drawOverlays(screen *ebiten.Image) {
+	if g.interactKey && !g.gameOver {
+		g.drawInteractHint(screen)
+	}
+	for _, k := range g.panels {
+		g.drawOpenPanel(screen, k)
+	}
+	if g.disarm != nil {
+		g.drawDisarm(screen)
+	}
+	if g.gameOver {
+		g.drawGameOver(screen)
+	}
+}
 
-	// Display interaction messages with very subtle transparency
-	messages := g.interactionHandler.GetMessages()
+// drawMessageFeed draws the dialogue box and the recent messages under the HUD
+func (g *Game) drawMessageFeed(screen *ebiten.Image) {
+	statY := 30 // Below the two lines of stats
+	// Display interaction messages with very subtle transparency; dialogue has its own box
+	g.drawDialogue(screen)
+	messages := g.interactionHandler.MessagesIn(MsgSystem, MsgCombat, MsgLoot, MsgTravel)
 	if len(messages) > 0 {
 		// No background box - keep it minimal
 		statY += 15
@@ -226,23 +491,34 @@ func (g *Game) Draw(screen *ebiten.Image) {
 				color.RGBA{0, 0, 0, alpha / 3}, // Very low alpha for the background
 				false,
 			)
+			drawSeverityMark(screen, 6, statY-2, msg.Severity)
 
-			// Draw the message text
-			// Using a lower alpha value for the background
-			// Note: We can't directly control text alpha with DebugPrintAt
-
-			// Use a short prefix for less visual impact
-			ebitenutil.DebugPrintAt(
-				screen,
-				fmt.Sprintf("· %s", msg), // Smaller bullet point
-				12,
-				statY)
+			// Draw the icon and text in the category's color, fading older
+			// messages along with their background
+			clr := msg.Category.Color()
+			drawMessage(screen, msg, 12, statY, color.NRGBA{clr.R, clr.G, clr.B, 155 + alpha})
 			statY += 15 // Reduced line spacing
 			statY += 20
 		}
 	}
 }
 
+// drawHoverTooltip draws the tooltip of whatever is under the mouse
+func (g *Game) drawHoverTooltip(screen *ebiten.Image) {
+	// Tooltips go on top of everything, unless a modal overlay has the player's attention
+	// The look cursor's tooltip hangs off the corner of its tile
+	if g.shop == nil && g.confirm == nil && g.pause == nil && !g.panels.IsOpen(PanelHelp) && !g.gameOver {
+		x, y := input.Cursor.X, input.Cursor.Y
+		if g.looking {
+			tx, ty := g.camera.WorldToScreen(g.hoverX+1, g.hoverY+1)
+			x, y = int(tx), int(ty)
+		}
+		drawTooltip(screen, g.hoverTooltip(screen), x, y)
+	}
+}
+
+// Layout records the screen size, which input hit-tests the HUD panels against
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+	g.screenW, g.screenH = outsideWidth, outsideHeight
+	return outsideWidth, outsideHeight
 }
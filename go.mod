@@ -2,13 +2,20 @@ module github.com/ZDSDD/AI_GAME
 
 go 1.24.1
 
-require github.com/hajimehoshi/ebiten/v2 v2.8.7
+require (
+	github.com/atotto/clipboard v0.1.4
+	github.com/hajimehoshi/ebiten/v2 v2.8.7
+	github.com/yuin/gopher-lua v1.1.1
+	golang.org/x/image v0.25.0
+)
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20240911145611-4856209ac325 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/purego v0.8.0 // indirect
+	github.com/go-text/typesetting v0.2.1 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
-	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/sync v0.12.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 )
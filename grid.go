@@ -0,0 +1,50 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// gridMajor is how many tiles apart the stronger grid lines are, so longer
+// distances can be counted in fives
+const gridMajor = 5
+
+// Colors of the grid lines, faint enough to leave the tiles readable
+var (
+	gridMinorColor = color.RGBA{255, 255, 255, 28}
+	gridMajorColor = color.RGBA{255, 255, 255, 60}
+)
+
+// drawGrid draws lines along the tile edges of the dungeon on the view,
+// through the camera. The stronger lines ring the player's tile and repeat
+// every gridMajor tiles out from it, so distances for ranged attacks and
+// ability radii can be read off them.
+func (g *Game) drawGrid(view *ebiten.Image) {
+	left, top := g.camera.WorldToScreen(0, 0)
+	right, bottom := g.camera.WorldToScreen(g.dungeon.Width, g.dungeon.Height)
+	for x := 0; x <= g.dungeon.Width; x++ {
+		sx, _ := g.camera.WorldToScreen(x, 0)
+		vector.StrokeLine(view, float32(sx), float32(top), float32(sx), float32(bottom), 1,
+			gridColor(x, g.player.X), false)
+	}
+	for y := 0; y <= g.dungeon.Height; y++ {
+		_, sy := g.camera.WorldToScreen(0, y)
+		vector.StrokeLine(view, float32(left), float32(sy), float32(right), float32(sy), 1,
+			gridColor(y, g.player.Y), false)
+	}
+}
+
+// gridColor is the color of the grid line on the left or top edge of tile
+// line, along an axis where the player stands on tile player
+func gridColor(line, player int) color.RGBA {
+	offset := line - player // Edges before the player's tile
+	if offset > 0 {
+		offset-- // Edges after it, counted from its far side
+	}
+	if offset%gridMajor == 0 {
+		return gridMajorColor
+	}
+	return gridMinorColor
+}
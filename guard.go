@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sort"
+)
+
+const (
+	guardedTreasures = 2  // The most valuable chests on a floor get a guardian
+	guardMinValue    = 30 // Chests worth less aren't worth guarding
+	guardLevelBonus  = 1  // Guardians are this many levels above the floor
+	guardAggroRadius = 6  // Monsters this close to a watched chest answer its opening
+)
+
+// GuardKind is how a guardian protects its treasure
+type GuardKind int
+
+const (
+	GuardNone    GuardKind = iota
+	GuardSealed            // The chest won't open while its guardian lives
+	GuardWatched           // Opening the chest sets nearby monsters on the player
+)
+
+// placeGuards links the floor's best treasures to guardian monsters standing
+// beside them. The guardian remembers its chest, so the link survives it moving.
+func (d *Dungeon) placeGuards() {
+	var chests []Point
+	for y := range d.Cells {
+		for x, cell := range d.Cells[y] {
+			if cell.Type == Treasure && !cell.Locked && cell.InteractionLevel >= guardMinValue {
+				chests = append(chests, Point{x, y})
+			}
+		}
+	}
+	sort.Slice(chests, func(i, j int) bool {
+		a, b := chests[i], chests[j]
+		return d.Cells[a.y][a.x].InteractionLevel > d.Cells[b.y][b.x].InteractionLevel
+	})
+
+	for _, chest := range chests[:min(guardedTreasures, len(chests))] {
+		spots := d.nearestEmptyCells(chest, 1, chest)
+		if len(spots) == 0 {
+			continue
+		}
+		spot := spots[0]
+		level := d.Level + guardLevelBonus
+		tier := monsterTierForLevel(level)
+		d.Cells[spot.y][spot.x] = Cell{Type: Monster, InteractionLevel: level, MonsterTier: tier,
+			Species: pickSpecies(tier, d.Theme.Name, d.rng)}
+		m := d.addMonster(spot.x, spot.y)
+		m.Guarding = &Point{chest.x, chest.y}
+		m.AI.Route = []Point{spot} // Its post; it returns here after a chase
+		d.Cells[chest.y][chest.x].Guard = GuardKind(1 + d.rng.Intn(2))
+	}
+}
+
+// guardianOf returns the living monster guarding the chest at p, if any
+func (d *Dungeon) guardianOf(p Point) *MonsterEntity {
+	for _, m := range d.Monsters {
+		if m.Guarding != nil && *m.Guarding == p {
+			return m
+		}
+	}
+	return nil
+}
+
+// releaseGuard unseals the chest of a guardian that just died
+func (d *Dungeon) releaseGuard(m *MonsterEntity) string {
+	if m.Guarding == nil {
+		return ""
+	}
+	chest := &d.Cells[m.Guarding.y][m.Guarding.x]
+	if chest.Type != Treasure || chest.Guard == GuardNone {
+		return ""
+	}
+	chest.Guard = GuardNone
+	return tr("With its guardian slain, the chest it protected lies open.")
+}
+
+// rouseGuards sets the guardian and every monster near a watched chest on the player
+func (d *Dungeon) rouseGuards(chest Point) int {
+	roused := 0
+	for _, m := range d.Monsters {
+		guardian := m.Guarding != nil && *m.Guarding == chest
+		if guardian || isWithinFOV(chest.x, chest.y, m.X, m.Y, guardAggroRadius) {
+			m.AI.State = AIChase
+			roused++
+		}
+	}
+	return roused
+}
+
+// checkGuard is consulted before opening a chest. It reports whether the chest
+// may be opened, explaining why not when it's sealed.
+func (d *Dungeon) checkGuard(chest Point, cell Cell, h *InteractionHandler) bool {
+	if cell.Guard != GuardSealed {
+		return true
+	}
+	m := d.guardianOf(chest)
+	if m == nil {
+		return true
+	}
+	name := d.Cells[m.Y][m.X].MonsterName()
+	h.Post(MsgSystem, SeverityWarning, tr("The chest is sealed. Its guardian, the %s, must fall first.", name))
+	return false
+}
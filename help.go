@@ -0,0 +1,116 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	helpLineHeight = 16
+	helpKeyColumn  = 110 // Width of the key column of the controls list
+)
+
+// helpControl is a control that has no rebindable action, listed after the
+// bound ones
+type helpControl struct {
+	Key, Label string
+}
+
+var fixedControls = []helpControl{
+	{"Click", "Select a tile and show the way there"},
+	{"Double-click", "Travel to a tile or attack; any key stops"},
+	{"Shift + move", "Pan the view"},
+	{"Right or middle drag", "Pan the view"},
+	{"Mouse wheel", "Zoom at the cursor"},
+	{"Enter", "Travel to the selected tile or look cursor"},
+	{"Ctrl + R", "Restart the run"},
+	{"Esc", "Close the top panel, or pause"},
+	{"F1", "Help"},
+}
+
+// helpSymbol explains one color on the map
+type helpSymbol struct {
+	Color   color.RGBA
+	Label   string
+	Outline bool // Drawn as a frame around the tile rather than filling it
+}
+
+// helpSymbols lists the map's colors, taken from the same values the
+// dungeon is drawn with
+func (g *Game) helpSymbols() []helpSymbol {
+	return []helpSymbol{
+		{Color: g.player.Color, Label: "You"},
+		{Color: getCellColor(Entrance, true), Label: "Entrance"},
+		{Color: getCellColor(Exit, true), Label: "Exit"},
+		{Color: getCellColor(Monster, true), Label: "Monster (tinted by species)"},
+		{Color: shriekerColor, Label: "Shrieking monster"},
+		{Color: eliteMarkerColor, Label: "Elite monster", Outline: true},
+		{Color: getCellColor(Treasure, true), Label: "Treasure"},
+		{Color: lockedChestColor, Label: "Locked chest"},
+		{Color: sealedChestColor, Label: "Sealed chest"},
+		{Color: getCellColor(Trap, true), Label: "Known trap"},
+		{Color: getCellColor(Satchel, true), Label: "Your lost satchel"},
+		{Color: getCellColor(Cage, true), Label: "Cage"},
+		{Color: getCellColor(Shrine, true), Label: "Shrine"},
+		{Color: getCellColor(Merchant, true), Label: "Merchant"},
+		{Color: getCellColor(Lever, true), Label: "Lever"},
+		{Color: pulledLeverColor, Label: "Pulled lever"},
+		{Color: gateColor, Label: "Gate"},
+		{Color: getCellColor(Fountain, true), Label: "Fountain"},
+		{Color: getCellColor(Altar, true), Label: "Healing altar"},
+		{Color: getCellColor(Scripted, true), Label: "Strange object"},
+	}
+}
+
+// updateHelp closes the help screen on its own key, F1 or Escape
+func (g *Game) updateHelp() {
+	if input.JustPressed(ActionHelp) || input.JustPressed(ActionCancel) {
+		g.closePanel(PanelHelp)
+	}
+}
+
+// drawHelp draws the controls, generated from the current key bindings,
+// next to a legend of the map's colors
+func (g *Game) drawHelp(screen *ebiten.Image) {
+	symbols := g.helpSymbols()
+	rows := max(int(numActions)+len(fixedControls), len(symbols))
+	panelW, panelH := min(640, screen.Bounds().Dx()-20), 44+helpLineHeight*rows
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := max(10, screen.Bounds().Dy()/2-panelH/2)
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	heading := ui.TextStyle{Bold: true}
+	keyStyle := ui.TextStyle{Color: color.RGBA{255, 220, 120, 255}}
+	left, right, top := panelX+10, panelX+panelW/2+10, panelY+28
+	ui.Text.Draw(screen, tr("Controls (%s / F1: close)", bindings.Key(ActionHelp)), left, panelY+6, heading)
+	ui.Text.Draw(screen, tr("Map"), right, panelY+6, heading)
+
+	row := 0
+	for a := Action(0); a < numActions; a++ {
+		y := top + helpLineHeight*row
+		ui.Text.Draw(screen, bindings.Key(a).String(), left, y, keyStyle)
+		ui.DrawText(screen, tr(a.String()), left+helpKeyColumn, y)
+		row++
+	}
+	for _, c := range fixedControls {
+		y := top + helpLineHeight*row
+		ui.Text.Draw(screen, tr(c.Key), left, y, keyStyle)
+		ui.DrawText(screen, tr(c.Label), left+helpKeyColumn, y)
+		row++
+	}
+
+	const swatch = 12
+	for i, s := range symbols {
+		x, y := float32(right), float32(top+helpLineHeight*i+1)
+		if s.Outline {
+			vector.StrokeRect(screen, x+1, y+1, swatch-2, swatch-2, 2, s.Color, false)
+		} else {
+			vector.DrawFilledRect(screen, x, y, swatch, swatch, s.Color, false)
+		}
+		ui.DrawText(screen, tr(s.Label), right+swatch+8, top+helpLineHeight*i)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	// highScoresFile stores the high score table between sessions
+	highScoresFile = "highscores.json"
+	maxHighScores  = 10
+)
+
+// HighScore is one finished run in the high score table
+type HighScore struct {
+	Name  string    `json:"name"`
+	Score int       `json:"score"`
+	Depth int       `json:"depth"`
+	Won   bool      `json:"won"`
+	Arena bool      `json:"arena"`
+	Seed  int64     `json:"seed"`
+	Date  time.Time `json:"date"`
+
+	SurvivalTicks int `json:"survival_ticks,omitempty"` // How long an arena run lasted
+}
+
+// HighScores is the table, best score first
+type HighScores []HighScore
+
+// highScores is the table loaded at startup and kept up to date as runs end
+var highScores HighScores
+
+// Add enters a run into the table and returns its rank counting from 1, or
+// 0 if it didn't make the cut. Ties go to the earlier run.
+func (hs *HighScores) Add(entry HighScore) int {
+	rank := sort.Search(len(*hs), func(i int) bool { return (*hs)[i].Score < entry.Score })
+	if rank >= maxHighScores {
+		return 0
+	}
+	*hs = append(*hs, HighScore{})
+	copy((*hs)[rank+1:], (*hs)[rank:])
+	(*hs)[rank] = entry
+	if len(*hs) > maxHighScores {
+		*hs = (*hs)[:maxHighScores]
+	}
+	return rank + 1
+}
+
+// loadHighScores reads the table from disk. A missing file is an empty table.
+func loadHighScores() (HighScores, error) {
+	file := dataPath(highScoresFile)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hs HighScores
+	if err := json.Unmarshal(data, &hs); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	sort.SliceStable(hs, func(i, j int) bool { return hs[i].Score > hs[j].Score })
+	if len(hs) > maxHighScores {
+		hs = hs[:maxHighScores]
+	}
+	return hs, nil
+}
+
+// saveHighScores writes the table to disk
+func saveHighScores(hs HighScores) error {
+	data, err := json.MarshalIndent(hs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dataPath(highScoresFile), data, 0o644)
+}
+
+// recordHighScore enters the finished run into the high score table
+func (g *Game) recordHighScore() {
+	entry := HighScore{
+		Name:  g.player.Name,
+		Score: g.player.Score,
+		Depth: g.dungeon.Level,
+		Won:   g.won,
+		Arena: g.arena != nil,
+		Seed:  g.runSeed,
+		Date:  time.Now(),
+	}
+	if g.arena != nil {
+		entry.SurvivalTicks = g.arena.SurvivalTicks(g.clock.Ticks)
+	}
+	g.highScoreRank = highScores.Add(entry)
+	if g.highScoreRank == 0 {
+		return
+	}
+	if err := saveHighScores(highScores); err != nil {
+		g.interactionHandler.Post(MsgSystem, SeverityWarning, tr("Could not save high scores: %v", err))
+	}
+}
+
+// drawHighScores draws the high score table as a full screen page
+func drawHighScores(screen *ebiten.Image, highlight int) {
+	screenW := screen.Bounds().Dx()
+	ui.Text.Draw(screen, tr("High Scores"), screenW/2, 60,
+		ui.TextStyle{Size: ui.SizeTitle, Align: ui.AlignCenter, Bold: true})
+
+	// Column x offsets from the left edge of the table
+	const tableW = 620
+	left := screenW/2 - tableW/2
+	columns := []struct {
+		title string
+		x     int
+		align ui.TextAlign
+	}{
+		{"#", 20, ui.AlignRight},
+		{"Name", 40, ui.AlignLeft},
+		{"Score", 260, ui.AlignRight},
+		{"Depth", 340, ui.AlignRight},
+		{"Time", 420, ui.AlignRight},
+		{"Date", 440, ui.AlignLeft},
+	}
+	header := ui.TextStyle{Bold: true, Color: color.RGBA{160, 160, 180, 255}}
+	for _, c := range columns {
+		header.Align = c.align
+		ui.Text.Draw(screen, tr(c.title), left+c.x, 120, header)
+	}
+
+	if len(highScores) == 0 {
+		ui.Text.Draw(screen, tr("No runs yet. Go make some history!"), screenW/2, 160,
+			ui.TextStyle{Align: ui.AlignCenter})
+		return
+	}
+	for i, hs := range highScores {
+		y := 150 + 26*i
+		style := ui.TextStyle{}
+		if i+1 == highlight {
+			style.Color = floatScore
+			style.Bold = true
+		}
+		depth := fmt.Sprintf("%d", hs.Depth)
+		switch {
+		case hs.Won:
+			depth = tr("escaped")
+		case hs.Arena:
+			depth = tr("arena %d", hs.Depth)
+		}
+		survived := ""
+		if hs.Arena {
+			survived = formatSurvival(hs.SurvivalTicks)
+		}
+		cells := []string{
+			fmt.Sprintf("%d", i+1),
+			hs.Name,
+			fmt.Sprintf("%d", hs.Score),
+			depth,
+			survived,
+			hs.Date.Format("2006-01-02"),
+		}
+		for j, c := range columns {
+			style.Align = c.align
+			ui.Text.Draw(screen, cells[j], left+c.x, y, style)
+		}
+	}
+}
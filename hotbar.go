@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	hotbarSlots    = 5
+	hotbarSlotSize = 36
+	hotbarGap      = 4
+)
+
+// hotbarEmptyColor stands in for an item's color once none are left
+var hotbarEmptyColor = color.RGBA{70, 70, 80, 255}
+
+// Hotbar holds the item type slotted in each quick-use slot, "" for an
+// empty one. A slot uses the first carried item of its type, so it keeps
+// working as items are picked up and used, and shows how many are left.
+type Hotbar [hotbarSlots]TreasureType
+
+// hotbarLayout returns the rectangle of each slot, centered along the bottom
+// of a screen of the given size
+func hotbarLayout(screenW, screenH int) []image.Rectangle {
+	w := hotbarSlots*(hotbarSlotSize+hotbarGap) - hotbarGap
+	x, y := screenW/2-w/2, screenH-hotbarSlotSize-10
+	slots := make([]image.Rectangle, hotbarSlots)
+	for i := range slots {
+		sx := x + i*(hotbarSlotSize+hotbarGap)
+		slots[i] = image.Rect(sx, y, sx+hotbarSlotSize, y+hotbarSlotSize)
+	}
+	return slots
+}
+
+// hotbarSlotPressed returns the slot whose key went down this tick
+func hotbarSlotPressed() (int, bool) {
+	for i := 0; i < hotbarSlots; i++ {
+		if input.JustPressed(ActionHotbar1 + Action(i)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// count returns how many items of a type the player carries
+func (p *Player) count(ttype TreasureType) int {
+	n := 0
+	for _, item := range p.Inventory {
+		if item.Type == ttype {
+			n++
+		}
+	}
+	return n
+}
+
+// slotItem puts the item at index i on a hotbar slot, or takes it off if it
+// is already there
+func (p *Player) slotItem(i, slot int) string {
+	item := p.Inventory[i]
+	if !item.canUse() {
+		return tr("Only usable items go on the hotbar.")
+	}
+	if p.Hotbar[slot] == item.Type {
+		p.Hotbar[slot] = ""
+		return tr("Took %s off hotbar slot %d.", item.Name, slot+1)
+	}
+	for s := range p.Hotbar {
+		if p.Hotbar[s] == item.Type {
+			p.Hotbar[s] = "" // A type only takes one slot
+		}
+	}
+	p.Hotbar[slot] = item.Type
+	return tr("Put %s on hotbar slot %d.", item.Name, slot+1)
+}
+
+// useHotbar uses the first carried item of the slot's type
+func (g *Game) useHotbar(slot int) {
+	p := g.player
+	ttype := p.Hotbar[slot]
+	if ttype == "" {
+		g.interactionHandler.AddMessage(tr("Hotbar slot %d is empty. Slot items from the inventory.", slot+1))
+		return
+	}
+	for i, item := range p.Inventory {
+		if item.Type == ttype {
+			g.interactionHandler.Record(LogEvent, p.useItem(i), 0)
+			return
+		}
+	}
+	g.interactionHandler.AddMessage(tr("You have no %s left.", ttype))
+}
+
+// drawHotbar draws the quick-use slots with their keys, items and how many
+// of each are left; slots whose items have run out are dimmed
+func (g *Game) drawHotbar(screen *ebiten.Image) {
+	p := g.player
+	small := ui.TextStyle{Size: ui.SizeSmall, Shadow: true}
+	for i, r := range hotbarLayout(screen.Bounds().Dx(), screen.Bounds().Dy()) {
+		x, y := float32(r.Min.X), float32(r.Min.Y)
+		vector.DrawFilledRect(screen, x, y, hotbarSlotSize, hotbarSlotSize, color.RGBA{20, 20, 28, 220}, false)
+		if ttype := p.Hotbar[i]; ttype != "" {
+			n := p.count(ttype)
+			clr := itemColors[ttype]
+			if n == 0 {
+				clr = hotbarEmptyColor
+			}
+			vector.DrawFilledRect(screen, x+8, y+8, hotbarSlotSize-16, hotbarSlotSize-16, clr, false)
+			ui.Text.Draw(screen, fmt.Sprint(n), r.Max.X-3, r.Max.Y-14,
+				ui.TextStyle{Size: ui.SizeSmall, Align: ui.AlignRight, Shadow: true})
+		}
+		vector.StrokeRect(screen, x, y, hotbarSlotSize, hotbarSlotSize, 1, color.RGBA{200, 200, 220, 255}, false)
+		key := strings.TrimPrefix(bindings.Key(ActionHotbar1+Action(i)).String(), "Digit")
+		ui.Text.Draw(screen, key, r.Min.X+3, r.Min.Y+1, small)
+	}
+}
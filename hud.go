@@ -0,0 +1,68 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	hudBarHeight   = 16
+	hudBarMinWidth = 120
+	hudBarMaxWidth = 260
+)
+
+var (
+	hudHealthGood = color.RGBA{60, 190, 80, 255}
+	hudHealthLow  = color.RGBA{220, 180, 40, 255}
+	hudHealthCrit = color.RGBA{210, 40, 40, 255}
+	hudXPColor    = color.RGBA{120, 90, 220, 255}
+)
+
+// hudBarWidth scales the stat bars with the screen, within sensible limits
+func hudBarWidth(screenW int) int {
+	return max(hudBarMinWidth, min(hudBarMaxWidth, screenW/6))
+}
+
+// healthColor turns from green to yellow to red as health runs out
+func healthColor(health, maxHealth int) color.RGBA {
+	switch {
+	case health*2 > maxHealth:
+		return hudHealthGood
+	case health*4 > maxHealth:
+		return hudHealthLow
+	default:
+		return hudHealthCrit
+	}
+}
+
+// drawStatBar draws a bordered bar filled to value/maxValue with its label inside
+func drawStatBar(screen *ebiten.Image, x, y, w int, value, maxValue int, fill color.RGBA, label string) {
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), hudBarHeight, color.RGBA{20, 20, 20, 220}, false)
+	if maxValue > 0 && value > 0 {
+		filled := float32(w) * float32(min(value, maxValue)) / float32(maxValue)
+		vector.DrawFilledRect(screen, float32(x), float32(y), filled, hudBarHeight, fill, false)
+	}
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), hudBarHeight, 1, color.RGBA{200, 200, 220, 255}, false)
+	ui.Text.Draw(screen, label, x+4, y+1, ui.TextStyle{Size: ui.SizeSmall, Shadow: true})
+}
+
+// drawHUDBars draws the health and experience bars along the top left and
+// returns the x position just past them
+func (g *Game) drawHUDBars(screen *ebiten.Image, y int) int {
+	p := g.player
+	w := hudBarWidth(screen.Bounds().Dx())
+	x := 10
+
+	drawStatBar(screen, x, y, w, p.Health, p.MaxHealth, healthColor(p.Health, p.MaxHealth),
+		tr("HP %d/%d", p.Health, p.MaxHealth))
+	x += w + 8
+
+	next := p.Level * xpPerLevel
+	drawStatBar(screen, x, y, w, p.Experience, next, hudXPColor,
+		tr("Lv %d  XP %d/%d", p.Level, p.Experience, next))
+	return x + w + 10
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+// Message catalogs map the English text of each user-facing string to its
+// translation, one JSON file per language. English needs no catalog: the
+// source strings are the English text, and anything missing from a catalog
+// falls back to it. Translations of format strings take the same arguments
+// and may reorder them with explicit indexes, as in "%[2]d ... %[1]s".
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+// Language is one of the languages the game can be played in
+type Language struct {
+	Code  string // Catalog file name in locales, without the extension
+	Label string // Name in the language itself, as the menu shows it
+}
+
+var languages = []Language{
+	{"en", "English"},
+	{"es", "Español"},
+}
+
+// catalog holds the translations of the current language; nil for English
+var catalog map[string]string
+
+// setLanguage switches every string looked up with tr to the given language
+func setLanguage(code string) error {
+	if code == "en" {
+		catalog = nil
+		return nil
+	}
+	data, err := locales.ReadFile("locales/" + code + ".json")
+	if err != nil {
+		return err
+	}
+	var c map[string]string
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("parse %s catalog: %w", code, err)
+	}
+	catalog = c
+	return nil
+}
+
+// tr translates a user-facing string into the current language. With
+// arguments the string is a format, filled in after translation.
+func tr(format string, args ...any) string {
+	if t, ok := catalog[format]; ok {
+		format = t
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func init() {
+	// Let the widget toolkit translate its own labels, such as ON and OFF
+	ui.Translate = func(s string) string { return tr(s) }
+}
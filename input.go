@@ -1,53 +1,263 @@
 package main
 
-import (
-	"github.com/hajimehoshi/ebiten/v2"
-)
-
-var prevKeyState bool
+import "math"
 
 // Handle player input and toggle FOV
 func HandleInput(g *Game, player *Player) {
+	// The debug overlay toggles at any time, even over menus
+	if input.JustPressed(ActionDebug) {
+		g.debug.Visible = !g.debug.Visible
+	}
+
+	// A dead player can only start over
+	if g.gameOver {
+		_, lines := g.gameOverText()
+		button := gameOverButton(g.screenW, g.screenH, len(lines))
+		clicked := input.JustPressed(ActionClick) && input.Cursor.In(button)
+		if input.JustPressed(ActionRetry) {
+			g.restartRequested = true
+		} else if input.JustPressed(ActionQuitToMenu) {
+			g.quitRequested = true
+		} else if input.JustPressed(ActionCopySeed) || clicked {
+			g.copyRunSeed()
+		}
+		return
+	}
+
+	// Ctrl+R restarts with the same settings at any time
+	if input.JustPressed(ActionRestart) {
+		g.restartRequested = true
+		return
+	}
+
+	// Any key or click stops an auto-travel; otherwise it takes its next step
+	g.updateTravel()
+
+	// A modal panel on top (the danger prompt, the merchant, the inventory,
+	// the pause menu or help) captures input until it is closed, Escape
+	// included. Otherwise Escape closes the topmost panel, then backs out of
+	// look mode, the disarm minigame or a selected path, and only then
+	// opens the pause menu.
+	if top, ok := g.panels.Top(); ok && top.modal() {
+		g.updatePanel(top)
+		return
+	}
+	if input.JustPressed(ActionHelp) {
+		g.openPanel(PanelHelp)
+		return
+	}
+	if top, ok := g.panels.Top(); ok && input.JustPressed(ActionCancel) {
+		g.closePanel(top)
+		return
+	}
+	if g.disarm == nil && !g.looking && input.JustPressed(ActionCancel) {
+		if g.selected != nil {
+			g.selected = nil
+		} else {
+			g.openPanel(PanelPause)
+		}
+		return
+	}
+
+	// Toggle pause; everything driven by the game clock freezes while paused
+	if input.JustPressed(ActionPause) {
+		g.clock.TogglePause()
+	}
+
+	// Inventory screen
+	if input.JustPressed(ActionInventory) {
+		g.openPanel(PanelInventory)
+		return
+	}
+
+	// Sneak toggle
+	if !g.clock.Paused && input.JustPressed(ActionSneak) {
+		player.Sneaking = !player.Sneaking
+	}
+
+	// Character sheet
+	if input.JustPressed(ActionCharacter) {
+		g.togglePanel(PanelCharacter)
+	}
+
+	// Interact with whatever is next to the player
+	if g.interactKey && !g.clock.Paused && !g.looking && g.selected == nil && input.JustPressed(ActionInteract) {
+		g.interact()
+	}
+
+	// Grid overlay
+	if input.JustPressed(ActionGrid) {
+		g.showGrid = !g.showGrid
+	}
+
+	// Quest log
+	if input.JustPressed(ActionQuestLog) {
+		g.togglePanel(PanelQuests)
+	}
+
+	// Combat log, scrolled with the mouse wheel or Page Up/Down while open
+	if input.JustPressed(ActionCombatLog) {
+		g.togglePanel(PanelCombatLog)
+	}
+	if g.panels.IsOpen(PanelCombatLog) {
+		if input.Wheel > 0 || input.JustPressed(ActionScrollUp) {
+			g.interactionHandler.Log.ScrollBy(3)
+		} else if input.Wheel < 0 || input.JustPressed(ActionScrollDown) {
+			g.interactionHandler.Log.ScrollBy(-3)
+		}
+	}
 
-	// Handle mouse input for movement
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		mouseX, mouseY := ebiten.CursorPosition()
+	// Message history, scrolled the same way
+	if input.JustPressed(ActionMessages) {
+		g.togglePanel(PanelMessages)
+	}
+	if g.panels.IsOpen(PanelMessages) {
+		if input.Wheel > 0 || input.JustPressed(ActionScrollUp) {
+			g.interactionHandler.History.ScrollBy(3)
+		} else if input.Wheel < 0 || input.JustPressed(ActionScrollDown) {
+			g.interactionHandler.History.ScrollBy(-3)
+		}
+	}
+
+	// Zoom with the keys around the player, or with the mouse wheel around
+	// the cursor unless the wheel is scrolling an open log
+	playerX, playerY := g.camera.WorldToScreen(player.X, player.Y)
+	playerX, playerY = playerX+g.camera.TileSize()/2, playerY+g.camera.TileSize()/2
+	if input.JustPressed(ActionZoomIn) {
+		g.camera.ZoomAt(zoomStep, playerX, playerY, g.dungeon)
+	}
+	if input.JustPressed(ActionZoomOut) {
+		g.camera.ZoomAt(1/zoomStep, playerX, playerY, g.dungeon)
+	}
+	if input.Wheel != 0 && !g.panels.IsOpen(PanelCombatLog) && !g.panels.IsOpen(PanelMessages) {
+		g.camera.ZoomAt(math.Pow(zoomStep, input.Wheel), float64(input.Cursor.X), float64(input.Cursor.Y), g.dungeon)
+	}
 
-		// Adjust for margins
-		adjustedMouseX := mouseX - g.marginX
-		adjustedMouseY := mouseY - g.marginY
+	// Dragging with the right or middle mouse button pans the view, for
+	// scouting away from the player; the recenter key brings it back
+	held := input.Held(ActionDragView)
+	if g.dragging && held {
+		drag := g.dragFrom.Sub(input.Cursor)
+		g.camera.Pan(drag.X, drag.Y)
+	}
+	g.dragging = held && (g.dragging || input.Cursor.In(g.camera.View))
+	g.dragFrom = input.Cursor
+	if input.JustPressed(ActionRecenter) {
+		g.camera.Recenter()
+	}
 
-		// Only process if the click is within the dungeon area (after margin adjustment)
-		if adjustedMouseX >= 0 && adjustedMouseY >= 0 {
-			tileX, tileY := adjustedMouseX/tileSize, adjustedMouseY/tileSize
+	// The disarm minigame captures input until it is resolved
+	if g.disarm != nil {
+		if !g.clock.Paused && input.JustPressed(ActionStrike) {
+			g.resolveDisarm(g.disarm.X, g.disarm.Y, g.disarm.Hit())
+		} else if input.JustPressed(ActionCancel) {
+			g.disarm = nil
+		}
+		return
+	}
 
-			if tileX < g.dungeon.Width && tileY < g.dungeon.Height {
-				// Move player to the tile clicked on, using the interaction handler
-				g.player.MoveTo(tileX, tileY, g.dungeon, g.interactionHandler)
+	// Number keys, or clicks on the hotbar, use the slotted items
+	if slot, ok := hotbarSlotPressed(); ok && !g.clock.Paused {
+		g.useHotbar(slot)
+		return
+	}
+	if input.JustPressed(ActionClick) && !g.clock.Paused {
+		for i, r := range hotbarLayout(g.screenW, g.screenH) {
+			if input.Cursor.In(r) {
+				g.useHotbar(i)
+				return
 			}
 		}
 	}
 
-	// Handle keyboard input for toggling FOV
-	keyPressed := ebiten.IsKeyPressed(ebiten.KeyF)
+	// The loot key travels to the nearest treasure the player knows of
+	if !g.clock.Paused && input.JustPressed(ActionLoot) {
+		g.travelToLoot()
+		return
+	}
+
+	// Look mode moves its cursor instead of the player
+	if input.JustPressed(ActionLook) && !g.looking {
+		g.startLook()
+		return
+	}
+	if g.updateLook() {
+		return
+	}
+
+	// A click selects a tile and a double click travels there, as does Enter
+	// once a tile is selected
+	if !g.clock.Paused && input.JustPressed(ActionClick) {
+		// Only process if the click is within the dungeon area
+		if tx, ty, ok := g.camera.ScreenToTile(input.Cursor.X, input.Cursor.Y); ok {
+			g.clickTile(tx, ty)
+		}
+	}
+	if !g.clock.Paused && g.selected != nil && input.JustPressed(ActionConfirm) {
+		g.startTravel(*g.selected)
+		return
+	}
+
+	// Movement keys step one tile, repeating while held; with Shift held they
+	// pan the view instead
+	shift := input.Held(ActionPanHold)
+	for _, s := range moveSteps {
+		if shift && input.Held(s.action) {
+			g.camera.Pan(s.dx*cameraPanSpeed, s.dy*cameraPanSpeed)
+		} else if !shift && !g.clock.Paused && input.Repeated(s.action) {
+			// Only a fresh press walks into danger; repeats stop short of it
+			g.walkTo(player.X+s.dx, player.Y+s.dy, input.JustPressed(s.action))
+		}
+	}
 
-	// Toggle FOV only when transitioning from released -> pressed
-	if keyPressed && !prevKeyState {
+	// Toggle FOV
+	if input.JustPressed(ActionToggleFOV) {
 		player.FOVEnabled = !player.FOVEnabled
 	}
+}
+
+// walkTo takes the player's next step towards a tile. fresh is set when the
+// step comes from a new click or key press rather than an ongoing travel.
+// It reports whether the walk goes on: false once it arrives, finds no way,
+// stops at something or acts on it.
+func (g *Game) walkTo(tileX, tileY int, fresh bool) bool {
+	if tileX < 0 || tileY < 0 || tileX >= g.dungeon.Width || tileY >= g.dungeon.Height {
+		return false
+	}
+	player := g.player
 
-	// Store current key state for next frame
-	prevKeyState = keyPressed
-	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
-		g.marginY++
+	// Stepping towards a known trap starts disarming it instead
+	path := g.dungeon.FindPath(Point{player.X, player.Y}, Point{tileX, tileY})
+	if len(path) < 2 {
+		return false
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
-		g.marginY--
+	next := g.dungeon.Cells[path[1].y][path[1].x]
+	if next.Type == Trap && next.Revealed {
+		g.startDisarm(path[1].x, path[1].y)
+		return false
+	}
+
+	// Chests, shrines and the like wait for the interact key; the walk just stops beside them
+	if g.interactKey && waitsForKey(next) {
+		return false
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
-		g.marginX++
+
+	// Dangerous steps need a fresh click or key and a yes; travel never
+	// walks into them on its own
+	if g.confirmDanger {
+		if text := g.dangerPrompt(path[1]); text != "" {
+			if fresh {
+				g.confirm = &ConfirmPrompt{Target: Point{tileX, tileY}, Text: text}
+				g.panels.Open(PanelConfirm)
+			}
+			return false
+		}
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
-		g.marginX--
+
+	// Move player to the target tile, using the interaction handler
+	if player.MoveTo(tileX, tileY, g.dungeon, g.interactionHandler) {
+		g.turns.PlayerActed()
+		return false
 	}
+	return true
 }
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+// waitsForKey reports whether, with the interact key on, a cell only reacts to
+// E rather than to being bumped. Monsters are still fought on bump and the
+// exit is still walked onto.
+func waitsForKey(c Cell) bool {
+	return c.Type.IsInteractive() && c.Type != Monster && c.Type != Exit
+}
+
+// interactTarget picks the tile E acts on: the hovered tile if it is next to
+// the player, otherwise the first neighbouring tile that waits for the key
+func (g *Game) interactTarget() (Point, bool) {
+	p, d := g.player, g.dungeon
+	if inBounds(g.hoverX, g.hoverY, d.Width, d.Height) && isAdjacent(p.X, p.Y, g.hoverX, g.hoverY) &&
+		waitsForKey(d.Cells[g.hoverY][g.hoverX]) {
+		return Point{g.hoverX, g.hoverY}, true
+	}
+	for _, dir := range []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		x, y := p.X+dir.x, p.Y+dir.y
+		if inBounds(x, y, d.Width, d.Height) && waitsForKey(d.Cells[y][x]) {
+			return Point{x, y}, true
+		}
+	}
+	return Point{}, false
+}
+
+// interact triggers the interaction next to the player, if there is one
+func (g *Game) interact() {
+	target, ok := g.interactTarget()
+	if !ok {
+		return
+	}
+	if g.player.MoveTo(target.x, target.y, g.dungeon, g.interactionHandler) {
+		g.turns.PlayerActed()
+	}
+}
+
+// drawInteractHint tells the player what E would do right now
+func (g *Game) drawInteractHint(screen *ebiten.Image) {
+	target, ok := g.interactTarget()
+	if !ok {
+		return
+	}
+	cell := g.dungeon.Cells[target.y][target.x]
+	x, y := g.camera.WorldToScreen(target.x+1, target.y)
+	ui.DrawText(screen, tr("%s: interact with %s", bindings.Key(ActionInteract), cell.Type), int(x)+4, int(y))
+}
@@ -1,55 +1,153 @@
 package main
 
-import (
-	"fmt"
-	"time"
-)
-
 // --- Message with Timestamp ---
 
 type TimedMessage struct {
 	Text          string
-	CreatedAt     time.Time
+	CreatedAt     int     // Game clock tick the message was added on
 	TotalLifetime float64 // Message lifetime in seconds
 	RemainingTime float64 // Remaining time before message disappears
+	Category      MessageCategory
+	Severity      Severity
 }
 
 // --- Interaction Result ---
 
 type InteractionResult struct {
-	Message       string
-	HealthChange  int
-	ScoreChange   int
-	RemoveEntity  bool
-	EntityRemoved CellType
+	Message          string
+	Kind             LogKind // How the combat log files this result
+	HealthChange     int
+	ScoreChange      int
+	ExperienceChange int
+	GoldChange       int
+	Knockback        int // Tiles a heavy hit pushes the struck monster
+	RemoveEntity     bool
+	EntityRemoved    CellType
+
+	// Payloads the handler and dungeon apply, so interactions never have to
+	// reach into the inventory or the map themselves
+	Items      []Item         // Put into the player's inventory
+	Effects    []StatusEffect // Started on the player
+	MapChanges []CellChange   // Cells replaced on the current floor
+	Reveal     []Point        // Tiles marked as seen (and traps among them spotted)
+
+	Category MessageCategory // Set for dialogue; otherwise derived from Kind
+}
+
+// CellChange replaces the cell at a position on the current floor
+type CellChange struct {
+	At   Point
+	Cell Cell
+}
+
+// ApplyResult carries out the map side of an interaction result
+func (d *Dungeon) ApplyResult(result InteractionResult) {
+	for _, c := range result.MapChanges {
+		if inBounds(c.At.x, c.At.y, d.Width, d.Height) {
+			d.Cells[c.At.y][c.At.x] = c.Cell
+			if c.Cell.Type == Monster {
+				d.addMonster(c.At.x, c.At.y)
+			}
+		}
+	}
+	for _, p := range result.Reveal {
+		if !inBounds(p.x, p.y, d.Width, d.Height) {
+			continue
+		}
+		d.Visited[p.y][p.x] = true
+		if d.Cells[p.y][p.x].Type == Trap {
+			d.Cells[p.y][p.x].Revealed = true
+		}
+	}
 }
 
 // --- Interactable Interface ---
 
 type Interactable interface {
-	Interact(player *Player) InteractionResult
+	Interact(player *Player, rng *RNG) InteractionResult
 }
 
 // --- Monster Interaction ---
 
+// MonsterInteraction is one round of melee against a monster entity. The
+// monster's health lives on the entity, so a fight can be broken off and
+// picked up again over several turns.
 type MonsterInteraction struct {
-	Level int
+	Cell    Cell // The monster's cell: level, species and flags
+	Monster *MonsterEntity
+}
+
+// NewMonsterInteraction sets up a fight with the monster standing on cell
+func NewMonsterInteraction(cell Cell, monster *MonsterEntity) *MonsterInteraction {
+	return &MonsterInteraction{Cell: cell, Monster: monster}
+}
+
+// Strike returns the damage the monster deals to the player in one blow
+func (m *MonsterInteraction) Strike(player *Player) int {
+	damage := (m.Cell.Species.Info().Damage + m.Cell.InteractionLevel*monsterDamagePerLvl) * (100 - player.EffectiveDefense()) / 100
+	if m.Monster.Boss != nil && m.Monster.Boss.CurrentPhase().Enraged {
+		damage += damage * bossEnragePercent / 100
+	}
+	return damage
 }
 
-func NewMonsterInteraction(level int) *MonsterInteraction {
-	return &MonsterInteraction{Level: level}
+// Blow lands one blow on the player and returns its damage. Vampiric elites
+// heal from it.
+func (m *MonsterInteraction) Blow(player *Player) int {
+	damage := m.Strike(player)
+	if m.Cell.Elite == AffixVampiric {
+		m.Monster.Health = min(m.Monster.MaxHealth, m.Monster.Health+damage*eliteVampPercent/100)
+	}
+	return damage
 }
 
-func (m *MonsterInteraction) Interact(player *Player) InteractionResult {
-	damage := (5 + m.Level*2) * (100 - player.Defense) / 100
-	score := 10 + m.Level*5
+func (m *MonsterInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	name, level := m.Cell.MonsterName(), m.Cell.InteractionLevel
+	resist := m.Cell.Resistances()
+	attack := player.AttackType()
+	hit := player.AttackDamage()
+	backstab := ""
+	if !m.Monster.Aware() {
+		hit *= backstabMultiplier
+		backstab = tr("Backstab! ")
+	}
+	hit = resist.Apply(hit, attack)
+	m.Monster.Health -= hit
 
+	effect := resist.effectiveness(attack)
+
+	if m.Monster.Health <= 0 {
+		// Lifesteal artifacts give back part of the killing blow
+		heal := hit * lifestealPercent * player.TraitCount(TraitLifesteal) / 100
+		drained := effect
+		if heal > 0 {
+			drained += tr(" Drained %d health.", heal)
+		}
+		return InteractionResult{
+			Message:          tr("%sDefeated a level %d %s!%s", backstab, level, name, drained),
+			Kind:             LogCombat,
+			HealthChange:     heal,
+			ScoreChange:      10 + level*5,
+			ExperienceChange: monsterXP(m.Cell),
+			RemoveEntity:     true,
+			EntityRemoved:    Monster,
+		}
+	}
+
+	// Still standing - it hits back, which uses up its next attack. A heavy
+	// hit knocks it away afterwards.
+	knockback := 0
+	if m.Monster.Boss == nil && heavyHit(hit, m.Monster.MaxHealth) {
+		knockback = knockbackDistance
+	}
+	damage := m.Blow(player)
+	m.Monster.cooldown = m.Cell.MoveInterval()
 	return InteractionResult{
-		Message:       fmt.Sprintf("Defeated a level %d monster! Took %d damage.", m.Level, damage),
-		HealthChange:  -damage,
-		ScoreChange:   score,
-		RemoveEntity:  true,
-		EntityRemoved: Monster,
+		Message: tr("%sHit the level %d %s for %d %s (%d/%d HP), took %d damage.%s",
+			backstab, level, name, hit, attack.LocalName(), m.Monster.Health, m.Monster.MaxHealth, damage, effect),
+		Kind:         LogCombat,
+		HealthChange: -damage,
+		Knockback:    knockback,
 	}
 }
 
@@ -64,20 +162,54 @@ func NewTreasureInteraction(value int, ttype TreasureType) *TreasureInteraction
 	return &TreasureInteraction{Value: value, Type: ttype}
 }
 
-func (t *TreasureInteraction) Interact(player *Player) InteractionResult {
-	score := t.Value * (100 + player.Luck) / 100
+func (t *TreasureInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	score := t.Value * (100 + player.EffectiveLuck()) / 100
 	health := 0
-
-	if t.Type == TreasurePotion {
+	message := tr("Found %s worth %d points!", t.Type, score)
+	var items []Item
+	var effects []StatusEffect
+
+	switch t.Type {
+	case TreasureGold:
+		player.Gold += score
+		message = tr("Found %d gold!", score)
+	case TreasurePotion:
 		health = 10
+	case TreasureTorch:
+		effects = append(effects, StatusEffect{Kind: StatusTorch})
+		message = tr("Lit a torch! Light radius increased. (+%d points)", score)
+	case TreasureLantern:
+		if player.UpgradeLantern() {
+			message = tr("Found a lantern upgrade! Light level %d. (+%d points)", player.LanternLevel, score)
+		}
+	}
+
+	if item, ok := NewTreasureItem(t.Type); ok {
+		if t.Type == TreasureWeapon {
+			item.Damage = DamageType(rng.Stream(StreamLoot).Intn(int(numDamageTypes)))
+			item.Name = tr(weaponNames[item.Damage])
+			message = tr("Found a %s! Your attacks now deal %s damage. (+%d points)", item.Name, item.Damage.LocalName(), score)
+		}
+		if t.Type == TreasureArtifact {
+			item.Trait = artifactTraits[rng.Stream(StreamLoot).Intn(len(artifactTraits))]
+			item.Name = item.Trait.ArtifactName()
+			message = tr("Found an %s: %s! (+%d points)", item.Name, item.Trait.Description(), score)
+		}
+		// Finds are unidentified until picked up; only then does a curse show
+		item.Blessing = rollBlessing(rng.Stream(StreamLoot))
+		message += item.identify()
+		items = append(items, item)
 	}
 
 	return InteractionResult{
-		Message:       fmt.Sprintf("Found %s worth %d points!", t.Type, score),
+		Message:       message,
+		Kind:          LogPickup,
 		HealthChange:  health,
 		ScoreChange:   score,
 		RemoveEntity:  true,
 		EntityRemoved: Treasure,
+		Items:         items,
+		Effects:       effects,
 	}
 }
 
@@ -91,9 +223,14 @@ func NewExitInteraction(nextLevel int) *ExitInteraction {
 	return &ExitInteraction{NextLevel: nextLevel}
 }
 
-func (e *ExitInteraction) Interact(player *Player) InteractionResult {
+func (e *ExitInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	message := tr("Descending to dungeon level %d!", e.NextLevel)
+	if e.NextLevel > victoryDepth {
+		message = tr("You climb out of the dungeon into daylight!")
+	}
 	return InteractionResult{
-		Message:       fmt.Sprintf("Descending to dungeon level %d!", e.NextLevel),
+		Message:       message,
+		Kind:          LogFloor,
 		HealthChange:  0,
 		ScoreChange:   20,
 		RemoveEntity:  false,
@@ -104,52 +241,134 @@ func (e *ExitInteraction) Interact(player *Player) InteractionResult {
 // --- Interaction Handler ---
 
 type InteractionHandler struct {
-	Interactions map[CellType]Interactable
-	Messages     []TimedMessage
-	MessageLife  float64 // Default lifetime for messages in seconds
+	Messages    []TimedMessage
+	History     *MessageHistory // Every message posted, for the message log panel
+	MessageLife float64         // Default lifetime for messages in seconds
+	Clock       *GameClock      // Simulation clock used to age messages
+	RNG         *RNG            // Run streams handed to interactions
+	Log         *CombatLog      // Permanent record alongside the fading messages
+	Events      *EventBus       // Outcomes of resolved interactions are published here
+	Turns       *TurnScheduler  // World turns that usage cooldowns count in
+
+	ScoreMultiplier int // Every score gain is multiplied by this; arena waves raise it
 }
 
-func NewInteractionHandler() *InteractionHandler {
+func NewInteractionHandler(clock *GameClock, turns *TurnScheduler, rng *RNG) *InteractionHandler {
 	return &InteractionHandler{
-		Interactions: make(map[CellType]Interactable),
-		Messages:     make([]TimedMessage, 0, 5),
-		MessageLife:  3.5, // Default 1 second lifetime
+		Messages:    make([]TimedMessage, 0, 5),
+		History:     NewMessageHistory(),
+		MessageLife: 3.5, // Default 3.5 second lifetime
+		Clock:       clock,
+		Turns:       turns,
+		RNG:         rng,
+		Log:         NewCombatLog(),
+		Events:      NewEventBus(),
+
+		ScoreMultiplier: 1,
 	}
 }
 
-func (h *InteractionHandler) Register(cellType CellType, i Interactable) {
-	h.Interactions[cellType] = i
+// Handle runs the interaction the cell carries. Monsters are fought through
+// their entity instead, see NewMonsterInteraction.
+func (h *InteractionHandler) Handle(cell Cell, player *Player) InteractionResult {
+	if cell.Interaction != nil {
+		return h.Resolve(cell.Interaction, player)
+	}
+
+	return InteractionResult{
+		Message: tr("Nothing happens."),
+	}
 }
 
-func (h *InteractionHandler) Handle(cellType CellType, player *Player) InteractionResult {
-	if interaction, ok := h.Interactions[cellType]; ok {
-		result := interaction.Interact(player)
+// Resolve runs a specific interaction and applies its result to the player
+func (h *InteractionHandler) Resolve(interaction Interactable, player *Player) InteractionResult {
+	// Limited interactions refuse while used up or recovering
+	if l, ok := interaction.(Limited); ok {
+		if ready, reason := l.Usage().Ready(h.Turns.Turn); !ready {
+			h.Post(MsgSystem, SeverityWarning, reason)
+			return InteractionResult{Message: reason}
+		}
+		l.Usage().Use(h.Turns.Turn)
+	}
 
-		h.AddMessage(result.Message)
-		player.Health += result.HealthChange
-		player.Score += result.ScoreChange
+	wasEncumbered := player.IsEncumbered()
+	result := interaction.Interact(player, h.RNG)
+	result.ScoreChange *= h.ScoreMultiplier
 
-		if player.Health > player.MaxHealth {
-			player.Health = player.MaxHealth
-		}
+	category := result.Category
+	if category == MsgSystem {
+		category = logCategory(result.Kind)
+	}
+	h.Post(category, healthSeverity(result.HealthChange), result.Message)
+	h.Log.Add(LogEntry{
+		Tick:         h.Clock.Ticks,
+		Kind:         result.Kind,
+		Text:         result.Message,
+		HealthChange: result.HealthChange,
+		ScoreChange:  result.ScoreChange,
+	})
+	for _, item := range result.Items {
+		player.AddItem(item)
+	}
+	if !wasEncumbered && player.IsEncumbered() {
+		h.Post(MsgSystem, SeverityWarning, tr("You are overburdened (%d/%d)! Movement slowed.",
+			player.CarryWeight(), player.CarryLimit()))
+	}
+	player.Health += result.HealthChange
+	player.Score += result.ScoreChange
+	player.Gold = max(0, player.Gold+result.GoldChange)
+	for _, e := range result.Effects {
+		player.ApplyEffect(e)
+	}
+	if player.GainExperience(result.ExperienceChange) {
+		msg := tr("You reached level %d! Max health is now %d.", player.Level, player.MaxHealth)
+		h.Post(MsgSystem, SeverityGood, msg)
+		h.Log.Add(LogEntry{Tick: h.Clock.Ticks, Kind: LogEvent, Text: msg})
+	}
 
-		return result
+	if player.Health > player.MaxHealth {
+		player.Health = player.MaxHealth
 	}
 
-	return InteractionResult{
-		Message: "Nothing happens.",
+	// Tell subscribers only once the result is fully applied to the player
+	for _, kind := range interactionEvents(interaction, result) {
+		h.Publish(kind, interaction, result)
 	}
+
+	return result
 }
 
+// Publish puts an event on the bus. Interact publishes the outcomes of
+// interactions itself; this is for the rest, such as a monster's blow or a
+// companion's kill.
+func (h *InteractionHandler) Publish(kind EventKind, source Interactable, result InteractionResult) {
+	h.Events.Publish(Event{Kind: kind, Tick: h.Clock.Ticks, Source: source, Result: result})
+}
+
+// Record shows a message and files it in the combat log
+func (h *InteractionHandler) Record(kind LogKind, msg string, healthChange int) {
+	h.Post(logCategory(kind), healthSeverity(healthChange), msg)
+	h.Log.Add(LogEntry{Tick: h.Clock.Ticks, Kind: kind, Text: msg, HealthChange: healthChange})
+}
+
+// AddMessage shows a plain system message
 func (h *InteractionHandler) AddMessage(msg string) {
+	h.Post(MsgSystem, SeverityInfo, msg)
+}
+
+// Post shows a message tagged with its category and severity
+func (h *InteractionHandler) Post(category MessageCategory, severity Severity, msg string) {
 	timedMsg := TimedMessage{
 		Text:          msg,
-		CreatedAt:     time.Now(),
+		CreatedAt:     h.Clock.Ticks,
 		TotalLifetime: h.MessageLife,
 		RemainingTime: h.MessageLife,
+		Category:      category,
+		Severity:      severity,
 	}
 
 	h.Messages = append(h.Messages, timedMsg)
+	h.History.Add(timedMsg)
 
 	// Still cap the total number of messages to avoid memory buildup
 	if len(h.Messages) > 5 {
@@ -157,13 +376,13 @@ func (h *InteractionHandler) AddMessage(msg string) {
 	}
 }
 
-// UpdateMessages updates the remaining time for all messages and removes expired ones
+// UpdateMessages updates the remaining time for all messages and removes expired ones.
+// Ages are measured in game clock ticks, so messages stop fading while the game is paused.
 func (h *InteractionHandler) UpdateMessages() {
-	now := time.Now()
 	var activeMessages []TimedMessage
 
 	for _, msg := range h.Messages {
-		elapsed := now.Sub(msg.CreatedAt).Seconds()
+		elapsed := h.Clock.SecondsSince(msg.CreatedAt)
 		remaining := h.MessageLife - elapsed
 
 		if remaining > 0 {
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	invColumns      = 6
+	invMinSlots     = 12 // Empty slots still show so the grid reads as a grid
+	invSlotSize     = 40
+	invSlotGap      = 6
+	invHeaderHeight = 44
+	invDetailLines  = 7
+	invButtonWidth  = 84
+	invButtonHeight = 24
+)
+
+// InventoryAction is one of the buttons under the selected item
+type InventoryAction int
+
+const (
+	ActionUse InventoryAction = iota
+	ActionEquip
+	ActionDrop
+	numInventoryActions
+)
+
+// inventoryInputs are the input actions that press each button
+var inventoryInputs = [numInventoryActions]Action{ActionUseItem, ActionEquipItem, ActionDropItem}
+
+func (a InventoryAction) String() string {
+	switch a {
+	case ActionUse:
+		return tr("Use (U)")
+	case ActionEquip:
+		return tr("Equip (E)")
+	default:
+		return tr("Drop (D)")
+	}
+}
+
+// InventoryMenu is the inventory screen. While it is open it takes all input
+// and the world stands still.
+type InventoryMenu struct {
+	Cursor int // Selected slot
+}
+
+// itemColors tints the slots by item type
+var itemColors = map[TreasureType]color.RGBA{
+	TreasureGems:     {80, 200, 230, 255},
+	TreasureArtifact: {200, 120, 255, 255},
+	TreasureWeapon:   {200, 200, 210, 255},
+	TreasureAmulet:   {255, 215, 0, 255},
+	TrapComponents:   {150, 110, 70, 255},
+}
+
+// invSlots returns how many slots the grid shows
+func (p *Player) invSlots() int {
+	n := max(invMinSlots, len(p.Inventory))
+	return (n + invColumns - 1) / invColumns * invColumns
+}
+
+// inventoryLayout returns the panel, the rectangle of each slot and of each
+// action button for a screen of the given size
+func (g *Game) inventoryLayout(screenW int) (panel image.Rectangle, slots []image.Rectangle, buttons []image.Rectangle) {
+	n := g.player.invSlots()
+	w := invColumns*(invSlotSize+invSlotGap) + invSlotGap
+	gridH := n / invColumns * (invSlotSize + invSlotGap)
+	h := invHeaderHeight + gridH + 16*invDetailLines + invButtonHeight + 20
+	x, y := screenW-w-10, 10
+	panel = image.Rect(x, y, x+w, y+h)
+
+	for i := 0; i < n; i++ {
+		sx := x + invSlotGap + i%invColumns*(invSlotSize+invSlotGap)
+		sy := y + invHeaderHeight + i/invColumns*(invSlotSize+invSlotGap)
+		slots = append(slots, image.Rect(sx, sy, sx+invSlotSize, sy+invSlotSize))
+	}
+	by := y + h - invButtonHeight - 8
+	for a := 0; a < int(numInventoryActions); a++ {
+		bx := x + invSlotGap + a*(invButtonWidth+invSlotGap)
+		buttons = append(buttons, image.Rect(bx, by, bx+invButtonWidth, by+invButtonHeight))
+	}
+	return panel, slots, buttons
+}
+
+// updateInventory handles input while the inventory screen is open
+func (g *Game) updateInventory() {
+	m, p := g.inventory, g.player
+	if input.JustPressed(ActionCancel) || input.JustPressed(ActionInventory) {
+		g.closePanel(PanelInventory)
+		return
+	}
+
+	n := p.invSlots()
+	switch {
+	case input.Repeated(ActionMoveLeft):
+		m.Cursor--
+	case input.Repeated(ActionMoveRight):
+		m.Cursor++
+	case input.Repeated(ActionMoveUp):
+		m.Cursor -= invColumns
+	case input.Repeated(ActionMoveDown):
+		m.Cursor += invColumns
+	}
+	m.Cursor = (m.Cursor + n) % n
+
+	action := InventoryAction(-1)
+	if input.JustPressed(ActionConfirm) {
+		action = ActionUse
+	}
+	for a, in := range inventoryInputs {
+		if input.JustPressed(in) {
+			action = InventoryAction(a)
+		}
+	}
+
+	// Clicking a slot selects it; clicking a button acts on the selection
+	if input.JustPressed(ActionClick) {
+		cursor := input.Cursor
+		_, slots, buttons := g.inventoryLayout(g.screenW)
+		for i, r := range slots {
+			if cursor.In(r) {
+				m.Cursor = i
+			}
+		}
+		for a, r := range buttons {
+			if cursor.In(r) {
+				action = InventoryAction(a)
+			}
+		}
+	}
+
+	// Number keys put the selected item on the hotbar
+	if slot, ok := hotbarSlotPressed(); ok && m.Cursor < len(p.Inventory) {
+		g.interactionHandler.AddMessage(p.slotItem(m.Cursor, slot))
+		return
+	}
+
+	if action < 0 || m.Cursor >= len(p.Inventory) {
+		return
+	}
+	var msg string
+	switch action {
+	case ActionUse:
+		msg = p.useItem(m.Cursor)
+	case ActionEquip:
+		msg = p.toggleEquip(m.Cursor)
+	case ActionDrop:
+		msg = p.dropItem(m.Cursor)
+	}
+	g.interactionHandler.Record(LogEvent, msg, 0)
+}
+
+// canUse reports whether an item has a use action
+func (item Item) canUse() bool {
+	return item.Type == TrapComponents
+}
+
+// canEquip reports whether an item can be wielded
+func (item Item) canEquip() bool {
+	return item.Type == TreasureWeapon
+}
+
+// useItem uses up the item at index i
+func (p *Player) useItem(i int) string {
+	item := p.Inventory[i]
+	if !item.canUse() {
+		return tr("You can't use the %s.", item.Name)
+	}
+	p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+	p.Defense++
+	return tr("You reinforce your armor with salvaged trap parts. (+1 defense)")
+}
+
+// toggleEquip wields the weapon at index i, or puts it away if it already is
+func (p *Player) toggleEquip(i int) string {
+	item := &p.Inventory[i]
+	if !item.canEquip() {
+		return tr("You can't equip the %s.", item.Name)
+	}
+	if item.Equipped {
+		item.Equipped = false
+		return tr("You put away the %s.", item.Name)
+	}
+	for j := range p.Inventory {
+		p.Inventory[j].Equipped = false
+	}
+	item.Equipped = true
+	return tr("You wield the %s.", item.Name)
+}
+
+// dropItem discards the item at index i, unless a curse binds it
+func (p *Player) dropItem(i int) string {
+	item := p.Inventory[i]
+	if item.Blessing == Cursed {
+		return tr("The %s won't leave your hands.", item.Name)
+	}
+	p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+	return tr("Dropped %s (weight %d).", item.Name, item.Weight)
+}
+
+// hoveredItem returns the inventory item under the cursor while the screen is open
+func (g *Game) hoveredItem(screenW int) (Item, bool) {
+	cursor := input.Cursor
+	_, slots, _ := g.inventoryLayout(screenW)
+	for i, r := range slots {
+		if cursor.In(r) && i < len(g.player.Inventory) {
+			return g.player.Inventory[i], true
+		}
+	}
+	return Item{}, false
+}
+
+// drawInventory draws the slot grid, the selected item's details and the action buttons
+func (g *Game) drawInventory(screen *ebiten.Image) {
+	p, m := g.player, g.inventory
+	panel, slots, buttons := g.inventoryLayout(screen.Bounds().Dx())
+	drawPanel(screen, panel.Min.X, panel.Min.Y, panel.Dx(), panel.Dy())
+
+	weightLine := tr("Weight: %d/%d", p.CarryWeight(), p.CarryLimit())
+	if p.IsEncumbered() {
+		weightLine += tr(" OVERBURDENED")
+	}
+	ui.DrawText(screen, tr("Inventory (I/Esc: close, arrows: select)"), panel.Min.X+6, panel.Min.Y+4)
+	ui.DrawText(screen, weightLine, panel.Min.X+6, panel.Min.Y+20)
+
+	for i, r := range slots {
+		vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), invSlotSize, invSlotSize,
+			color.RGBA{40, 40, 50, 255}, false)
+		if i < len(p.Inventory) {
+			item := p.Inventory[i]
+			clr := itemColors[item.Type]
+			vector.DrawFilledRect(screen, float32(r.Min.X+8), float32(r.Min.Y+8), invSlotSize-16, invSlotSize-16, clr, false)
+			switch {
+			case item.Blessing == Cursed:
+				vector.StrokeRect(screen, float32(r.Min.X+3), float32(r.Min.Y+3), invSlotSize-6, invSlotSize-6, 2,
+					color.RGBA{200, 40, 40, 255}, false)
+			case item.Blessing == Blessed:
+				vector.StrokeRect(screen, float32(r.Min.X+3), float32(r.Min.Y+3), invSlotSize-6, invSlotSize-6, 2,
+					color.RGBA{240, 230, 140, 255}, false)
+			}
+			if item.Equipped {
+				ui.DrawText(screen, "E", r.Min.X+2, r.Min.Y)
+			}
+		}
+		border := color.RGBA{90, 90, 110, 255}
+		if i == m.Cursor {
+			border = color.RGBA{255, 255, 255, 255}
+		}
+		vector.StrokeRect(screen, float32(r.Min.X), float32(r.Min.Y), invSlotSize, invSlotSize, 1, border, false)
+	}
+
+	// Details of the selected item
+	detailY := slots[len(slots)-1].Max.Y + 8
+	var selected *Item
+	if m.Cursor < len(p.Inventory) {
+		selected = &p.Inventory[m.Cursor]
+		t := selected.Tooltip()
+		lines := append([]string{t.Title}, t.Lines...)
+		if selected.canUse() {
+			lines = append(lines[:min(len(lines), invDetailLines-1)], tr("1-5: put on the hotbar"))
+		}
+		for i, line := range lines[:min(len(lines), invDetailLines)] {
+			ui.DrawText(screen, line, panel.Min.X+6, detailY+16*i)
+		}
+	} else {
+		ui.DrawText(screen, tr("(empty slot)"), panel.Min.X+6, detailY)
+	}
+
+	for a, r := range buttons {
+		enabled := selected != nil
+		switch InventoryAction(a) {
+		case ActionUse:
+			enabled = enabled && selected.canUse()
+		case ActionEquip:
+			enabled = enabled && selected.canEquip()
+		case ActionDrop:
+			enabled = enabled && selected.Blessing != Cursed
+		}
+		bg, fg := color.RGBA{50, 50, 60, 255}, color.RGBA{200, 200, 220, 255}
+		if !enabled {
+			bg, fg = color.RGBA{30, 30, 35, 255}, color.RGBA{80, 80, 90, 255}
+		}
+		vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), bg, false)
+		vector.StrokeRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), 1, fg, false)
+		ui.DrawText(screen, InventoryAction(a).String(), r.Min.X+8, r.Min.Y+4)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+// Item is something the player carries in their inventory
+type Item struct {
+	Name   string
+	Type   TreasureType
+	Weight int
+	Trait  Trait      // Passive modifier granted while carried (artifacts)
+	Damage DamageType // Damage dealt when wielded (weapons)
+
+	Blessing Blessing // Cursed items can't be dropped or sold until a shrine lifts the curse
+	Equipped bool     // Wielded weapon; see Player.Weapon
+}
+
+const (
+	baseCarryLimit     = 10 // Carry limit before any levels
+	carryLimitPerLevel = 5  // Extra carry limit per player level
+	baseMoveCooldown   = 10 // Frames between steps when unencumbered
+	maxEncumbrance     = 30 // Cap on the extra frames added by encumbrance
+)
+
+// TrapComponents are salvaged from disarmed traps for crafting
+const TrapComponents TreasureType = "trap components"
+
+// itemWeights holds the weight of treasure types that are carried rather than
+// used on pickup. Types missing here never enter the inventory.
+var itemWeights = map[TreasureType]int{
+	TreasureGems:     2,
+	TreasureArtifact: 5,
+	TrapComponents:   1,
+	TreasureWeapon:   3,
+	TreasureAmulet:   1,
+}
+
+// NewTreasureItem returns the inventory item for a treasure type, if it is carried
+func NewTreasureItem(ttype TreasureType) (Item, bool) {
+	weight, ok := itemWeights[ttype]
+	if !ok {
+		return Item{}, false
+	}
+	return Item{Name: string(ttype), Type: ttype, Weight: weight}, true
+}
+
+// CarryWeight returns the total weight of everything in the inventory
+func (p *Player) CarryWeight() int {
+	total := 0
+	for _, item := range p.Inventory {
+		total += item.Weight
+	}
+	return total
+}
+
+// CarryLimit returns how much the player can carry without slowing down
+func (p *Player) CarryLimit() int {
+	return baseCarryLimit + p.Level*carryLimitPerLevel
+}
+
+// IsEncumbered reports whether the player is carrying more than their limit
+func (p *Player) IsEncumbered() bool {
+	return p.CarryWeight() > p.CarryLimit()
+}
+
+// moveDelay returns the frames between steps, growing with the excess weight
+// and doubled while sneaking
+func (p *Player) moveDelay() int {
+	delay := baseMoveCooldown
+	if excess := p.CarryWeight() - p.CarryLimit(); excess > 0 {
+		delay += min(5+10*excess/p.CarryLimit(), maxEncumbrance)
+	}
+	if p.Sneaking {
+		delay *= 2
+	}
+	return delay
+}
+
+// Weapon returns the equipped weapon, or else the most recently picked up one
+func (p *Player) Weapon() (Item, bool) {
+	for _, item := range p.Inventory {
+		if item.Equipped {
+			return item, true
+		}
+	}
+	for i := len(p.Inventory) - 1; i >= 0; i-- {
+		if p.Inventory[i].Type == TreasureWeapon {
+			return p.Inventory[i], true
+		}
+	}
+	return Item{}, false
+}
+
+// AttackType is the damage type of the player's blows; bare hands are physical
+func (p *Player) AttackType() DamageType {
+	if w, ok := p.Weapon(); ok {
+		return w.Damage
+	}
+	return DamagePhysical
+}
+
+// AddItem puts an item into the inventory
+func (p *Player) AddItem(item Item) {
+	p.Inventory = append(p.Inventory, item)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// newGameRenderer sets up the layers a run is drawn in
+func newGameRenderer() *Renderer {
+	r := &Renderer{}
+	r.Register(LayerTerrain, RenderFunc(drawTerrain))
+	r.Register(LayerDecorations, cellContents(LayerDecorations))
+	r.Register(LayerItems, cellContents(LayerItems))
+	r.Register(LayerItems, RenderFunc(drawFogLayer))
+	r.Register(LayerEntities, cellContents(LayerEntities))
+	r.Register(LayerEntities, RenderFunc(func(screen *ebiten.Image, f *Frame) {
+		g := f.Game
+		if g.player.Companion != nil {
+			g.player.Companion.Draw(screen)
+		}
+		g.player.Draw(screen)
+		g.dungeon.DrawAwareness(screen, g.player)
+	}))
+	r.Register(LayerEffects, RenderFunc(func(screen *ebiten.Image, f *Frame) {
+		g := f.Game
+		g.drawPathPreview(screen)
+		g.effects.Draw(screen, g.dungeon, g.player)
+		for _, p := range g.projectiles {
+			p.Draw(screen)
+		}
+	}))
+	r.Register(LayerUI, RenderFunc(func(screen *ebiten.Image, f *Frame) {
+		g := f.Game
+		if g.showGrid {
+			g.drawGrid(screen.SubImage(g.camera.View).(*ebiten.Image))
+		}
+		g.drawThreatIndicators(screen, g.camera.View)
+		g.drawHover(screen)
+		g.drawPanIndicator(screen)
+		g.drawHUD(screen)
+		g.drawHotbar(screen)
+		g.drawOverlays(screen)
+		g.drawMessageFeed(screen)
+		g.drawHoverTooltip(screen)
+		g.drawTransition(screen)
+	}))
+	return r
+}
+
+// cellLayer is the layer a cell's contents are drawn in. Bare floor and
+// walls are only terrain.
+func cellLayer(cell Cell) Layer {
+	switch cell.VisibleType() {
+	case Empty:
+		return LayerTerrain
+	case Wall:
+		if cell.Gate {
+			return LayerDecorations
+		}
+		return LayerTerrain
+	case Treasure, Satchel:
+		return LayerItems
+	case Monster:
+		return LayerEntities
+	}
+	return LayerDecorations
+}
+
+// drawTerrain draws the floor and walls under every cell the player knows of
+func drawTerrain(screen *ebiten.Image, f *Frame) {
+	d := f.Game.dungeon
+	fov := f.Game.player.FOVEnabled
+	for _, v := range f.Cells {
+		terrain := cellLayer(v.Cell) == LayerTerrain
+		switch {
+		case spriteTiles && v.Cell.Type == Wall && !v.Cell.Gate:
+			// Theme wall colors are close to black, so the bricks are
+			// lightened to stay visible
+			drawSprite(screen, spriteWallTiles+d.wallMask(v.X, v.Y, fov), v.X, v.Y, v.Shade(lightenColor(d.Theme.WallColor)))
+		case spriteTiles && !v.Cell.Gate:
+			drawSprite(screen, spriteFloor, v.X, v.Y, v.Shade(floorSpriteColor))
+		case !spriteTiles && terrain:
+			fillTile(screen, v.X, v.Y, v.Color)
+		case !spriteTiles && !v.Cell.Gate:
+			// Whatever stands here is drawn in its own layer, on the floor
+			fillTile(screen, v.X, v.Y, v.Shade(getCellColor(Empty, true)))
+		}
+	}
+}
+
+// cellContents draws what stands on the cells whose contents belong to
+// layer: a sprite, or in the flat-color mode the tile in the cell's color.
+// Explored objects out of sight stay on the map under the fog, except
+// monsters, which may have moved on.
+func cellContents(layer Layer) RenderFunc {
+	return func(screen *ebiten.Image, f *Frame) {
+		for _, v := range f.Cells {
+			if cellLayer(v.Cell) != layer {
+				continue
+			}
+			cell := v.Cell
+			monster := cell.VisibleType() == Monster
+			shown := v.InSight || v.Sensed || (v.Remembered && !monster) || cell.Type == Entrance || cell.Type == Wall
+			switch {
+			case !shown:
+			case !spriteTiles && monster && v.InSight:
+				drawFlatMonster(screen, v.X, v.Y, cell, v.Color)
+			case !spriteTiles:
+				fillTile(screen, v.X, v.Y, v.Color)
+			case monster:
+				sprite, _ := cellSprite(cell)
+				sprite, _ = animate(sprite, v.X, v.Y, v.Color)
+				drawMonsterSprite(screen, sprite, v.X, v.Y, cell.MonsterTier, v.Color)
+			default:
+				sprite, _ := cellSprite(cell)
+				if v.InSight {
+					sprite, _ = animate(sprite, v.X, v.Y, v.Color)
+				}
+				drawSprite(screen, sprite, v.X, v.Y, v.Color)
+			}
+			if v.InSight && cell.Type == Monster && cell.Elite != AffixNone {
+				vector.StrokeRect(screen, float32(v.X*tileSize)+1, float32(v.Y*tileSize)+1,
+					float32(tileSize)-2, float32(tileSize)-2, 2, eliteMarkerColor, false)
+			}
+		}
+	}
+}
+
+// drawFogLayer lays the fog over the remembered cells, and everything drawn
+// on them so far
+func drawFogLayer(screen *ebiten.Image, f *Frame) {
+	var fogged []Point
+	for _, v := range f.Cells {
+		if v.Remembered {
+			fogged = append(fogged, Point{v.X, v.Y})
+		}
+	}
+	drawFog(screen, fogged)
+}
+
+// fillTile fills the tile at (x, y) with a flat color
+func fillTile(screen *ebiten.Image, x, y int, clr color.RGBA) {
+	vector.DrawFilledRect(screen, float32(x*tileSize), float32(y*tileSize),
+		float32(tileSize), float32(tileSize), clr, false)
+}
@@ -0,0 +1,111 @@
+package main
+
+const (
+	vaultChance    = 50 // Percent of floors with a lever-gated vault
+	vaultLootBonus = 2  // Vault treasure is worth this many times a normal find
+)
+
+// LeverInteraction opens the gates wired to it. Gates are walls until then,
+// so pathing and sight treat them like any other wall.
+type LeverInteraction struct {
+	At     Point
+	Gates  []Point
+	pulled bool
+}
+
+func NewLeverInteraction(at Point, gates []Point) *LeverInteraction {
+	return &LeverInteraction{At: at, Gates: gates}
+}
+
+func (l *LeverInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	if l.pulled {
+		return InteractionResult{Message: tr("The lever is stuck fast. Whatever it opened stays open.")}
+	}
+	l.pulled = true
+
+	// The lever itself shows as pulled, and the opened gates show up on the map
+	changes := []CellChange{{At: l.At, Cell: Cell{Type: Lever, Switched: true, Interaction: l}}}
+	for _, g := range l.Gates {
+		changes = append(changes, CellChange{At: g, Cell: Cell{Type: Empty}})
+	}
+	return InteractionResult{
+		Message:    tr("You pull the lever. Somewhere, %s grinds open.", plural(len(l.Gates), tr("a gate"), tr("gates"))),
+		Kind:       LogEvent,
+		MapChanges: changes,
+		Reveal:     l.Gates,
+	}
+}
+
+// plural picks the singular or plural phrase for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// placeVault walls off a dead end behind a gate, puts bonus loot inside and
+// wires the gate to a lever in another dead end. The gate only ever seals a
+// corridor leading to the vault, so the rest of the floor stays connected.
+func (d *Dungeon) placeVault(level int) {
+	deadEnds := d.findDeadEnds()
+	d.rng.Shuffle(len(deadEnds), func(i, j int) { deadEnds[i], deadEnds[j] = deadEnds[j], deadEnds[i] })
+
+	for i, de := range deadEnds {
+		vault := Point{de[0], de[1]}
+		gate, ok := d.vaultDoor(vault)
+		if !ok {
+			continue
+		}
+		// The lever goes in a different dead end that the gate doesn't cut off
+		for _, other := range deadEnds[i+1:] {
+			lever := Point{other[0], other[1]}
+			if lever == gate || abs(lever.x-gate.x)+abs(lever.y-gate.y) <= 1 {
+				continue
+			}
+			kind := []TreasureType{TreasureGems, TreasureArtifact, TreasureWeapon}[d.rng.Intn(3)]
+			d.Cells[gate.y][gate.x] = Cell{Type: Wall, Gate: true}
+			d.Cells[vault.y][vault.x] = NewTreasureCell(vaultLootBonus*max(10, level*10), kind)
+			d.Cells[lever.y][lever.x] = Cell{Type: Lever,
+				Interaction: NewLeverInteraction(lever, []Point{gate})}
+			return
+		}
+	}
+}
+
+// vaultDoor returns the corridor tile leading into a dead end, if sealing it
+// would cut off nothing but the dead end itself
+func (d *Dungeon) vaultDoor(deadEnd Point) (Point, bool) {
+	for _, dir := range []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		door := Point{deadEnd.x + dir.x, deadEnd.y + dir.y}
+		if !inBounds(door.x, door.y, d.Width, d.Height) || d.Cells[door.y][door.x].Type != Empty {
+			continue
+		}
+		return door, d.openNeighbors(door) == 2
+	}
+	return Point{}, false
+}
+
+// openNeighbors counts the orthogonal neighbours of p that are not walls
+func (d *Dungeon) openNeighbors(p Point) int {
+	n := 0
+	for _, dir := range []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		x, y := p.x+dir.x, p.y+dir.y
+		if inBounds(x, y, d.Width, d.Height) && d.Cells[y][x].Type != Wall {
+			n++
+		}
+	}
+	return n
+}
+
+// placeInDeadEnd puts a permanent feature in a random dead end, where it can't
+// block a corridor, falling back to any empty tile if there are none
+func (d *Dungeon) placeInDeadEnd(feature CellType) (int, int) {
+	deadEnds := d.findDeadEnds()
+	if len(deadEnds) == 0 {
+		return d.placeRandomFeature(Empty, feature)
+	}
+	de := deadEnds[d.rng.Intn(len(deadEnds))]
+	d.Cells[de[1]][de[0]].Type = feature
+	return de[0], de[1]
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	torchRadiusBonus = 3   // Extra FOV radius while a torch burns
+	torchDuration    = 150 // Torch burn time in world turns
+	maxLanternLevel  = 3   // Cap on permanent lantern upgrades
+	maxDarkness      = 3   // Deepest floors shrink the base radius by this much
+	minFOVRadius     = 2   // The player can always see their immediate surroundings
+)
+
+// darknessForLevel returns how much the base FOV radius shrinks on a floor.
+// Every second floor gets one step darker, up to maxDarkness.
+func darknessForLevel(level int) int {
+	darkness := (level - 1) / 2
+	if darkness > maxDarkness {
+		darkness = maxDarkness
+	}
+	if darkness < 0 {
+		darkness = 0
+	}
+	return darkness
+}
+
+// EffectiveFOVRadius combines the base radius with the floor's darkness and any
+// light sources the player is carrying.
+func (p *Player) EffectiveFOVRadius(d *Dungeon) int {
+	radius := p.FOVRadius - d.Darkness + p.LanternLevel
+	if p.TorchTurns > 0 {
+		radius += torchRadiusBonus
+	}
+	if radius < minFOVRadius {
+		radius = minFOVRadius
+	}
+	return radius
+}
+
+// LightTorch starts (or refreshes) a torch burning for torchDuration turns
+func (p *Player) LightTorch() {
+	p.TorchTurns = torchDuration
+}
+
+// UpgradeLantern permanently increases the light radius, reporting whether the
+// upgrade applied or the lantern was already at its maximum level.
+func (p *Player) UpgradeLantern() bool {
+	if p.LanternLevel >= maxLanternLevel {
+		return false
+	}
+	p.LanternLevel++
+	return true
+}
+
+// edgeLight is how bright tiles at the edge of the light are, 1 being fully lit
+const edgeLight = 0.35
+
+// lightFalloff is how brightly the player's light shows a tile dist tiles
+// away: fully lit up close, fading smoothly to edgeLight at the radius
+func lightFalloff(dist, radius float64) float64 {
+	t := min(1, dist/(radius+0.5))
+	return 1 - (1-edgeLight)*t*t
+}
+
+// shadeColor scales the brightness of c by light
+func shadeColor(c color.RGBA, light float64) color.RGBA {
+	scale := func(v uint8) uint8 { return uint8(float64(v) * light) }
+	return color.RGBA{R: scale(c.R), G: scale(c.G), B: scale(c.B), A: c.A}
+}
+
+// rememberedColor is how explored tiles out of view are drawn under the fog:
+// mostly gray, so they read as memory rather than sight
+func rememberedColor(c color.RGBA) color.RGBA {
+	gray := (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+	fade := func(v uint8) uint8 { return uint8((3*int(v) + 7*gray) / 10) }
+	return color.RGBA{R: fade(c.R), G: fade(c.G), B: fade(c.B), A: c.A}
+}
+
+// fogOpacity is how dark the fog laid over explored tiles out of view is,
+// from 0 (none) to 1 (black). It is a global like tileSize, set from the
+// settings of the run being played.
+var fogOpacity = 0.55
+
+// drawFog lays the fog over the given tiles. Unlike darkening each color,
+// the fog dims everything drawn on a tile alike, so remembered objects
+// still read against the floor.
+func drawFog(screen *ebiten.Image, tiles []Point) {
+	fog := color.RGBA{A: uint8(255 * max(0, min(fogOpacity, 1)))}
+	for _, t := range tiles {
+		vector.DrawFilledRect(screen, float32(t.x*tileSize), float32(t.y*tileSize),
+			float32(tileSize), float32(tileSize), fog, false)
+	}
+}
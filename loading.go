@@ -0,0 +1,100 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	// loadingDelay is how many ticks a run may take to generate before the
+	// loading screen shows, so small dungeons don't flash it
+	loadingDelay = 10
+	spinnerDots  = 8
+)
+
+// generateFloor builds the floor described by spec on its own goroutine.
+// Generation only touches the new dungeon and its own random stream, so it
+// is safe to run alongside the game loop. The channel receives the floor
+// once it is ready.
+func generateFloor(spec FloorSpec) chan *Dungeon {
+	ready := make(chan *Dungeon, 1)
+	go func() { ready <- NewDungeon(spec) }()
+	return ready
+}
+
+// RunLoader generates the first floor of a run in the background. The game
+// is only created from it once the floor is ready, so a run is swapped in
+// whole and never seen half built.
+type RunLoader struct {
+	Settings GameSettings
+	RunSeed  int64
+	Spec     FloorSpec
+	Save     *SavedGame // Run to lay over the floor once it is ready; nil for a fresh run
+	frame    int        // Ticks since loading started
+	ready    chan *Dungeon
+}
+
+// NewRunLoader starts generating the first floor of a run
+func NewRunLoader(settings GameSettings, runSeed int64) *RunLoader {
+	spec := startFloorSpec(settings, runSeed)
+	return &RunLoader{Settings: settings, RunSeed: runSeed, Spec: spec, ready: generateFloor(spec)}
+}
+
+// NewSaveLoader starts generating the floor a saved run was left on
+func NewSaveLoader(save *SavedGame) *RunLoader {
+	return &RunLoader{Settings: save.Settings, RunSeed: save.RunSeed, Spec: save.Floor, Save: save,
+		ready: generateFloor(save.Floor)}
+}
+
+// Update advances the loading screen and returns the new game once the
+// floor is ready, or nil while it is still generating
+func (l *RunLoader) Update() *Game {
+	l.frame++
+	select {
+	case dungeon := <-l.ready:
+		if l.Save != nil {
+			return l.Save.Restore(dungeon)
+		}
+		return NewGame(l.Settings, l.RunSeed, dungeon)
+	default:
+		return nil
+	}
+}
+
+// Draw draws the loading screen with a spinner
+func (l *RunLoader) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{20, 20, 30, 255})
+	if l.frame < loadingDelay {
+		return
+	}
+	screenW, screenH := screen.Bounds().Dx(), screen.Bounds().Dy()
+	ui.Text.Draw(screen, tr("Generating dungeon..."), screenW/2, screenH/2-50,
+		ui.TextStyle{Size: ui.SizeLarge, Align: ui.AlignCenter, Bold: true})
+	ui.Text.Draw(screen, tr("%dx%d tiles, seed %d", l.Spec.Width, l.Spec.Height, l.RunSeed),
+		screenW/2, screenH/2-22, ui.TextStyle{Size: ui.SizeSmall, Color: color.RGBA{160, 160, 180, 255}, Align: ui.AlignCenter})
+	drawSpinner(screen, screenW/2, screenH/2+30, l.frame, 255)
+	ui.Text.Draw(screen, tr("Esc: back to menu"), screenW/2, screenH/2+70,
+		ui.TextStyle{Size: ui.SizeSmall, Color: color.RGBA{120, 120, 140, 255}, Align: ui.AlignCenter})
+}
+
+// drawSpinner draws a ring of dots centered on (cx, cy), the brightest one
+// going round once a second
+func drawSpinner(screen *ebiten.Image, cx, cy, frame int, alpha uint8) {
+	const radius = 14
+	lead := frame * spinnerDots / ticksPerSecond
+	for i := 0; i < spinnerDots; i++ {
+		angle := 2 * math.Pi * float64(i) / spinnerDots
+		x := float32(cx) + radius*float32(math.Cos(angle))
+		y := float32(cy) + radius*float32(math.Sin(angle))
+
+		// Dots trailing the lead one fade out
+		age := (lead - i + spinnerDots) % spinnerDots
+		fade := 1 - float64(age)/spinnerDots
+		vector.DrawFilledCircle(screen, x, y, 3, color.NRGBA{200, 200, 230, uint8(float64(alpha) * fade)}, true)
+	}
+}
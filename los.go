@@ -0,0 +1,54 @@
+package main
+
+// CanSee reports whether to is within radius of from with nothing blocking
+// the view. It is the sight check for player FOV, monster detection and
+// ranged targeting alike. Shadowcasting is tried from both ends, so sight
+// stays symmetric the same way LineOfSight keeps it.
+func (d *Dungeon) CanSee(from, to Point, radius int) bool {
+	if !isWithinFOV(from.x, from.y, to.x, to.y, radius) {
+		return false
+	}
+	if fovAlgorithm == FOVShadowcast {
+		return d.fieldOfView(from, radius).Sees(to) || d.fieldOfView(to, radius).Sees(from)
+	}
+	return d.LineOfSight(from, to)
+}
+
+// LineOfSight reports whether no wall lies between two tiles. The end points
+// themselves may be walls. A Bresenham line can differ depending on which end
+// it starts from, so both directions are tried to keep sight symmetric: if the
+// player can see a monster, the monster can see the player.
+func (d *Dungeon) LineOfSight(from, to Point) bool {
+	return d.clearLine(from, to) || d.clearLine(to, from)
+}
+
+// clearLine walks the Bresenham line from one tile to another
+func (d *Dungeon) clearLine(from, to Point) bool {
+	dx, dy := abs(to.x-from.x), -abs(to.y-from.y)
+	sx, sy := 1, 1
+	if from.x > to.x {
+		sx = -1
+	}
+	if from.y > to.y {
+		sy = -1
+	}
+
+	x, y, e := from.x, from.y, dx+dy
+	for {
+		if x == to.x && y == to.y {
+			return true
+		}
+		if (x != from.x || y != from.y) && d.Cells[y][x].Type == Wall {
+			return false
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y += sy
+		}
+	}
+}
@@ -17,8 +17,9 @@ const (
 
 func main() {
 
-	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowSize(fitWindowSize(screenWidth, screenHeight))
 	ebiten.SetWindowTitle("Procedural Dungeon")
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
 	// Create the main game with menu
 	mainGame := NewMainGame()
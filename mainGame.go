@@ -2,14 +2,17 @@ package main
 
 import (
 	"fmt"
+	"image"
 	"image/color"
-	"math"
+	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
 )
 
 // GameState represents the current state of the game
@@ -18,6 +21,9 @@ type GameState int
 const (
 	StateMenu GameState = iota
 	StateGame
+	StateHighScores
+	StateLoading
+	StateSaves
 )
 
 // Define available resolution options
@@ -36,61 +42,125 @@ var resolutions = []Resolution{
 	{1920, 1080, "1920x1080 (Full HD)"},
 }
 
-// Define default tile sizes options
-var tileSizeOptions = []int{8, 12, 16, 20, 24, 32}
+// Define default tile sizes options; autoTileSize fits the dungeon to the window
+var tileSizeOptions = []int{8, 12, 16, 20, 24, 32, autoTileSize}
+
+// Camera follow options: how much of the way to the player the view moves
+// each tick
+type CameraEasing struct {
+	Rate  float64
+	Label string
+}
+
+var cameraEasings = []CameraEasing{
+	{1, "Instant"},
+	{0.3, "Snappy"},
+	{0.15, "Smooth"},
+	{0.07, "Lazy"},
+}
+
+// Screen shake options, scaling how hard the screen shakes
+type ShakeIntensity struct {
+	Scale float64
+	Label string
+}
+
+var shakeIntensities = []ShakeIntensity{
+	{0, "Off"},
+	{0.5, "Low"},
+	{1, "Normal"},
+	{1.5, "Strong"},
+}
+
+// Sight options, the default first
+var fovAlgorithms = []FOVAlgorithm{FOVShadowcast, FOVLineOfSight}
 
-// Define difficulty options
+// Define difficulty options; how each scales with depth is set by its curve
+// in difficulty.go, which players can override from difficultyFile
 type Difficulty struct {
-	Level       int
-	Label       string
-	MonsterMod  float64 // Monster strength modifier
-	TreasureMod float64 // Treasure value modifier
+	Level int
+	Label string
 }
 
 var difficulties = []Difficulty{
-	{1, "Easy", 0.8, 1.2},
-	{2, "Normal", 1.0, 1.0},
-	{3, "Hard", 1.2, 0.8},
-	{4, "Nightmare", 1.5, 0.7},
+	{1, "Easy"},
+	{2, "Normal"},
+	{3, "Hard"},
+	{4, "Nightmare"},
 }
 
-// Button represents a clickable UI element
-type Button struct {
-	X, Y          int
-	Width, Height int
-	Label         string
-	Selected      bool
-	OnClick       func()
+// Define player color options
+type PlayerColor struct {
+	Label string
+	Color color.RGBA
 }
 
+var playerColors = []PlayerColor{
+	{"White", color.RGBA{255, 255, 255, 255}},
+	{"Cyan", color.RGBA{0, 220, 255, 255}},
+	{"Magenta", color.RGBA{255, 80, 220, 255}},
+	{"Orange", color.RGBA{255, 150, 30, 255}},
+	{"Lime", color.RGBA{150, 255, 80, 255}},
+	{"Violet", color.RGBA{160, 110, 255, 255}},
+}
+
+const (
+	defaultPlayerName = "Adventurer"
+	maxPlayerNameLen  = 16
+)
+
+// menuTop is where the options start, below the menu title
+const menuTop = 120
+
+// Where the menu pages sit on screen. They are placed again whenever the
+// window changes size, so they follow resizes and resolution changes.
+var (
+	menuAnchor   = ui.Fill(0, menuTop, 0, 0)
+	scoresAnchor = ui.Anchor{Top: 1, Right: 1, Bottom: 1, Offset: image.Rect(0, -100, 0, -40)}
+	savesAnchor  = ui.Fill(0, menuTop-20, 0, 0)
+)
+
 // MainMenu represents the pre-game options panel
 type MainMenu struct {
 	selectedResolution int
 	selectedTileSize   int
 	selectedDifficulty int
+	selectedLanguage   int
+	selectedEasing     int
+	selectedSight      int
+	selectedShake      int
+	fogOpacity         int // Percent
+	repeatDelay        int // Milliseconds before a held move key repeats
+	repeatRate         int // Repeats per second of a held move key
+	autosaveTurns      int // World turns between autosaves; 0 saves only on new floors
+	spriteTiles        bool
+	vignette           bool
+	scanlines          bool
+	lowHealthPulse     bool
 	enableFOV          bool
+	casualMode         bool
+	reducedMotion      bool
+	turnBased          bool
+	arena              bool
+	confirmDanger      bool
+	interactKey        bool
 	dungeonWidth       int
 	dungeonHeight      int
-	buttons            []*Button
-	sliders            []*Slider
-
-	// Scroll related properties
-	scrollY       int  // Current scroll position
-	contentHeight int  // Total height of all content
-	scrollBarGrab bool // Is the scrollbar being dragged
-	scrollBarY    int  // Y position of the scrollbar
-}
-
-// Slider represents a UI element for selecting a numeric value
-type Slider struct {
-	X, Y          int
-	Width, Height int
-	Label         string
-	MinValue      int
-	MaxValue      int
-	Value         int
-	OnChange      func(int)
-	Active        bool // Is the slider actively being dragged
+	playerName         string
+	selectedColor      int
+	nameFieldActive    bool   // Is the name field capturing keyboard input
+	seedText           string // Seed typed or pasted for the next run; empty means random
+	seedFieldActive    bool   // Is the seed field capturing keyboard input
+	rebinding          Action // Action whose key is being rebound
+	rebindActive       bool   // Is a controls button waiting for a key press
+
+	// Widgets, with the ones updated from outside their own handlers kept at hand
+	root           *ui.VBox
+	tileSize       *ui.Dropdown
+	nameField      *ui.Button
+	seedField      *ui.Button
+	bindButtons    [numActions]*ui.Button
+	interactToggle *ui.Toggle // Names the interact key, so it follows rebinding
 }
 
 // GameSettings contains all settings for the game
@@ -98,13 +168,32 @@ type GameSettings struct {
 	ScreenWidth    int
 	ScreenHeight   int
 	TileSize       int
+	AutoTileSize   bool    // Refit TileSize to the window for every floor
+	SpriteTiles    bool    // Draw the dungeon with the tileset rather than flat colors
+	CameraEasing   float64 // How quickly the camera catches up with the player
+	ShakeIntensity float64 // Scale of screen shake; 0 turns it off
+	FogOpacity     float64 // How dark explored tiles out of view are drawn, from 0 to 1
+	RepeatDelay    int     // Ticks a move key is held before it repeats
+	RepeatInterval int     // Ticks between the repeats of a held move key
+	AutosaveTurns  int     // World turns between autosaves; 0 saves only on new floors
+	Vignette       bool    // Shade the dungeon darker towards the edge of the light
+	Scanlines      bool    // CRT-style scanlines over the whole screen
+	LowHealthPulse bool    // Pulse the screen's edges red when health runs low
 	DungeonWidth   int
 	DungeonHeight  int
 	EnableFOV      bool
-	DifficultyMods struct {
-		Monster  float64
-		Treasure float64
-	}
+	FOVAlgorithm   FOVAlgorithm // How walls block sight
+	CasualMode     bool         // Deaths leave a recoverable satchel and retries replay the same seed
+	ReducedMotion  bool         // Replace timing minigames with Luck-based rolls
+	TurnBased      bool         // The world only advances when the player acts
+	Arena          bool         // Endless horde mode on a single open floor
+	ConfirmDanger  bool         // Ask before attacking a monster or taking the exit
+	InteractKey    bool         // Chests, shrines, levers and the like wait for E instead of a bump
+	StartLevel     int          // Dungeon level of the first floor, set by difficulty
+	Seed           int64        // Run seed to replay; 0 picks a fresh one
+	PlayerName     string
+	PlayerColor    color.RGBA
+	Difficulty     DifficultyCurve // Monster and treasure scaling by dungeon level
 }
 
 // MainGame is the root game struct that manages game state
@@ -112,37 +201,114 @@ type MainGame struct {
 	state    GameState
 	menu     *MainMenu
 	game     *Game
+	loading  *RunLoader // Generates the next run's first floor; nil unless loading
 	settings GameSettings
+	lastRun  *GameSettings   // Settings of the last run started, offered again from the menu
+	lastRank int             // High score rank of the last run left, highlighted in the table
+	scores   *ui.VBox        // Back button of the high score page
+	bounds   image.Rectangle // Screen size last reported to Layout
+	laidOut  image.Rectangle // Screen size the menu pages were last placed for
+	quitting bool            // Is the quit prompt up over the main menu
+	saves    *ui.VBox        // Slot buttons of the load page
+	deleting int             // Slot the delete prompt asks about, counting from 1; 0 when none
+	prefs    MenuPrefs       // Menu choices as last written to the settings file
 }
 
 func NewMainGame() *MainGame {
+	if err := initUserDirs(); err != nil {
+		log.Printf("could not set up user directories, using the working directory: %v", err)
+	}
+
+	curves, err := loadDifficultyCurves()
+	if err != nil {
+		log.Printf("could not load difficulty curves, using defaults: %v", err)
+	}
+	difficultyCurves = curves
+
+	registry, err := loadScripts(scriptPath())
+	if err != nil {
+		log.Printf("could not load scripts: %v", err)
+	}
+	scripts = registry
+
+	keys, err := loadBindings()
+	if err != nil {
+		log.Printf("could not load key bindings, using defaults: %v", err)
+	}
+	bindings = keys
+
+	table, err := loadHighScores()
+	if err != nil {
+		log.Printf("could not load high scores: %v", err)
+	}
+	highScores = table
+
 	menu := &MainMenu{
 		selectedResolution: 2, // Default to 1280x720
 		selectedTileSize:   2, // Default to 16
 		selectedDifficulty: 1, // Default to Normal
+		selectedEasing:     2, // Default to Smooth
+		selectedShake:      2, // Default to Normal
+		fogOpacity:         55,
+		repeatDelay:        300,
+		repeatRate:         10,
+		autosaveTurns:      200,
+		spriteTiles:        true,
+		vignette:           true,
+		lowHealthPulse:     true,
 		enableFOV:          true,
 		dungeonWidth:       40, // Default width
 		dungeonHeight:      20, // Default height
-		scrollY:            0,
+		playerName:         defaultPlayerName,
+		selectedColor:      0, // Default to White
+	}
+	if err := loadMenuPrefs(menu); err != nil {
+		log.Printf("could not load settings, using defaults: %v", err)
+	}
+	if err := setLanguage(languages[menu.selectedLanguage].Code); err != nil {
+		log.Printf("could not load language %s: %v", languages[menu.selectedLanguage].Code, err)
+		menu.selectedLanguage = 0
 	}
 
 	// Default settings
 	settings := GameSettings{
-		ScreenWidth:   resolutions[menu.selectedResolution].Width,
-		ScreenHeight:  resolutions[menu.selectedResolution].Height,
-		TileSize:      tileSizeOptions[menu.selectedTileSize],
-		DungeonWidth:  menu.dungeonWidth,
-		DungeonHeight: menu.dungeonHeight,
-		EnableFOV:     menu.enableFOV,
+		ScreenWidth:    resolutions[menu.selectedResolution].Width,
+		ScreenHeight:   resolutions[menu.selectedResolution].Height,
+		TileSize:       tileSizeOptions[menu.selectedTileSize],
+		AutoTileSize:   tileSizeOptions[menu.selectedTileSize] == autoTileSize,
+		SpriteTiles:    menu.spriteTiles,
+		CameraEasing:   cameraEasings[menu.selectedEasing].Rate,
+		ShakeIntensity: shakeIntensities[menu.selectedShake].Scale,
+		FogOpacity:     float64(menu.fogOpacity) / 100,
+		RepeatDelay:    menu.repeatDelay * ticksPerSecond / 1000,
+		RepeatInterval: ticksPerSecond / menu.repeatRate,
+		AutosaveTurns:  menu.autosaveTurns,
+		Vignette:       menu.vignette,
+		Scanlines:      menu.scanlines,
+		LowHealthPulse: menu.lowHealthPulse,
+		DungeonWidth:   menu.dungeonWidth,
+		DungeonHeight:  menu.dungeonHeight,
+		EnableFOV:      menu.enableFOV,
+		FOVAlgorithm:   fovAlgorithms[menu.selectedSight],
+		CasualMode:     menu.casualMode,
+		ReducedMotion:  menu.reducedMotion,
+		TurnBased:      menu.turnBased,
+		Arena:          menu.arena,
+		ConfirmDanger:  menu.confirmDanger,
+		InteractKey:    menu.interactKey,
+		StartLevel:     difficulties[menu.selectedDifficulty].Level,
+		PlayerName:     menu.playerName,
+		PlayerColor:    playerColors[menu.selectedColor].Color,
 	}
-	settings.DifficultyMods.Monster = difficulties[menu.selectedDifficulty].MonsterMod
-	settings.DifficultyMods.Treasure = difficulties[menu.selectedDifficulty].TreasureMod
+	settings.Difficulty = curveFor(difficulties[menu.selectedDifficulty].Label)
 
 	mainGame := &MainGame{
 		state:    StateMenu,
 		menu:     menu,
 		settings: settings,
+		prefs:    menu.prefs(),
 	}
+	ebiten.SetWindowSize(fitWindowSize(settings.ScreenWidth, settings.ScreenHeight))
 
 	// Initialize menu buttons
 	mainGame.initializeMenu()
@@ -152,568 +318,876 @@ func NewMainGame() *MainGame {
 
 // Initialize menu elements
 func (m *MainGame) initializeMenu() {
-	buttonY := 140
-	buttonSpacing := 40
-
-	m.menu.buttons = []*Button{}
-
-	// Title section doesn't need to be a button, it will be drawn separately
-
-	// Resolution section
-	buttonY += buttonSpacing
-	resolutionLabel := &Button{
-		X:        m.settings.ScreenWidth/2 - 150,
-		Y:        buttonY,
-		Width:    300,
-		Height:   30,
-		Label:    "Display Resolution",
-		Selected: false,
-	}
-	m.menu.buttons = append(m.menu.buttons, resolutionLabel)
-
-	buttonY += 35
-	resolutionButtons := []*Button{}
+	resolutionLabels := make([]string, len(resolutions))
 	for i, res := range resolutions {
-		resIndex := i // Capture the index for closure
-		button := &Button{
-			X:        m.settings.ScreenWidth/2 - 150,
-			Y:        buttonY + i*35,
-			Width:    300,
-			Height:   30,
-			Label:    res.Label,
-			Selected: i == m.menu.selectedResolution,
-			OnClick: func() {
-				m.menu.selectedResolution = resIndex
-				// Update all button selected states
-				for j, btn := range m.menu.buttons {
-					if strings.Contains(btn.Label, "x") { // Simple check for resolution buttons
-						m.menu.buttons[j].Selected = (j-2 == resIndex) // Adjust index offset based on your buttons array
-					}
-				}
-				m.updateSettings()
-				m.initializeMenu() // Reinitialize the menu after changing resolution
-			},
-		}
-		resolutionButtons = append(resolutionButtons, button)
+		resolutionLabels[i] = res.Label
+	}
+	resolution := &ui.Dropdown{
+		Label:    tr("Resolution"),
+		Options:  resolutionLabels,
+		Selected: m.menu.selectedResolution,
+		OnChange: func(i int) {
+			m.menu.selectedResolution = i
+			m.updateSettings()
+			ebiten.SetWindowSize(fitWindowSize(m.settings.ScreenWidth, m.settings.ScreenHeight))
+			m.initializeMenu() // Reinitialize the menu after changing resolution
+		},
 	}
-	m.menu.buttons = append(m.menu.buttons, resolutionButtons...)
-
-	buttonY += len(resolutions)*35 + buttonSpacing
 
-	// Tile size buttons
-	tileSizeLabel := &Button{
-		X:        m.settings.ScreenWidth/2 - 150,
-		Y:        buttonY,
-		Width:    300,
-		Height:   30,
-		Label:    "Tile Size",
-		Selected: false,
+	languageLabels := make([]string, len(languages))
+	for i, lang := range languages {
+		languageLabels[i] = lang.Label
+	}
+	language := &ui.Dropdown{
+		Label:    tr("Language"),
+		Options:  languageLabels,
+		Selected: m.menu.selectedLanguage,
+		OnChange: func(i int) {
+			if err := setLanguage(languages[i].Code); err != nil {
+				log.Printf("could not load language %s: %v", languages[i].Code, err)
+				return
+			}
+			m.menu.selectedLanguage = i
+			m.savePrefs()
+			m.initializeMenu() // Rebuild the menu in the new language
+		},
 	}
-	m.menu.buttons = append(m.menu.buttons, tileSizeLabel)
 
-	buttonY += 35
-	tileSizeButtons := []*Button{}
+	tileSizeLabels := make([]string, len(tileSizeOptions))
 	for i, size := range tileSizeOptions {
-		sizeIndex := i // Capture the index for closure
-		button := &Button{
-			X:        m.settings.ScreenWidth/2 - 150 + (i%3)*100,
-			Y:        buttonY + (i/3)*35,
-			Width:    90,
-			Height:   30,
-			Label:    fmt.Sprintf("%dpx", size),
-			Selected: i == m.menu.selectedTileSize,
-			OnClick: func() {
-				// Deselect all tile size buttons first
-				for j, btn := range m.menu.buttons {
-					if strings.HasSuffix(btn.Label, "px") {
-						m.menu.buttons[j].Selected = false
-					}
-				}
+		tileSizeLabels[i] = m.tileSizeLabel(size)
+	}
+	m.menu.tileSize = &ui.Dropdown{
+		Label:    tr("Tile Size"),
+		Options:  tileSizeLabels,
+		Selected: m.menu.selectedTileSize,
+		OnChange: func(i int) {
+			m.menu.selectedTileSize = i
+			m.updateSettings()
+		},
+	}
 
-				// Now select this button
-				for j, btn := range m.menu.buttons {
-					if btn.Label == fmt.Sprintf("%dpx", tileSizeOptions[sizeIndex]) {
-						m.menu.buttons[j].Selected = true
-						break
-					}
-				}
+	easingLabels := make([]string, len(cameraEasings))
+	for i, easing := range cameraEasings {
+		easingLabels[i] = tr(easing.Label)
+	}
+	easing := &ui.Dropdown{
+		Label:    tr("Camera Follow"),
+		Options:  easingLabels,
+		Selected: m.menu.selectedEasing,
+		OnChange: func(i int) {
+			m.menu.selectedEasing = i
+			m.updateSettings()
+		},
+	}
 
-				m.menu.selectedTileSize = sizeIndex
-				m.updateSettings()
-			},
-		}
-		tileSizeButtons = append(tileSizeButtons, button)
+	shakeLabels := make([]string, len(shakeIntensities))
+	for i, s := range shakeIntensities {
+		shakeLabels[i] = tr(s.Label)
+	}
+	shake := &ui.Dropdown{
+		Label:    tr("Screen Shake"),
+		Options:  shakeLabels,
+		Selected: m.menu.selectedShake,
+		Tooltip:  ui.Tooltip{Title: tr("Screen Shake"), Lines: []string{tr("Big hits, boss blows, traps and wall slams shake the view"), tr("Reduced Motion turns it off too")}},
+		OnChange: func(i int) {
+			m.menu.selectedShake = i
+			m.updateSettings()
+		},
+	}
+
+	fog := &ui.Slider{
+		Label:   tr("Fog Opacity"),
+		Min:     0,
+		Max:     100,
+		Value:   m.menu.fogOpacity,
+		Tooltip: ui.Tooltip{Title: tr("Fog Opacity"), Lines: []string{tr("How dark explored tiles out of view are drawn")}},
+		OnChange: func(val int) {
+			m.menu.fogOpacity = val
+			m.updateSettings()
+		},
 	}
-	m.menu.buttons = append(m.menu.buttons, tileSizeButtons...)
 
-	buttonY += 70 + buttonSpacing
+	autosave := &ui.Slider{
+		Label:   tr("Autosave Every (turns)"),
+		Min:     0,
+		Max:     1000,
+		Value:   m.menu.autosaveTurns,
+		Tooltip: ui.Tooltip{Title: tr("Autosave Every (turns)"), Lines: []string{tr("The run also saves itself on every new floor"), tr("0 saves only on new floors")}},
+		OnChange: func(val int) {
+			m.menu.autosaveTurns = val
+			m.updateSettings()
+		},
+	}
 
-	// Difficulty buttons
-	difficultyLabel := &Button{
-		X:        m.settings.ScreenWidth/2 - 150,
-		Y:        buttonY,
-		Width:    300,
-		Height:   30,
-		Label:    "Difficulty",
-		Selected: false,
+	repeatDelay := &ui.Slider{
+		Label:   tr("Key Repeat Delay (ms)"),
+		Min:     100,
+		Max:     1000,
+		Value:   m.menu.repeatDelay,
+		Tooltip: ui.Tooltip{Title: tr("Key Repeat Delay (ms)"), Lines: []string{tr("How long a held move key waits before it keeps stepping")}},
+		OnChange: func(val int) {
+			m.menu.repeatDelay = val
+			m.updateSettings()
+		},
+	}
+	repeatRate := &ui.Slider{
+		Label:   tr("Key Repeat Rate"),
+		Min:     1,
+		Max:     30,
+		Value:   m.menu.repeatRate,
+		Tooltip: ui.Tooltip{Title: tr("Key Repeat Rate"), Lines: []string{tr("Steps per second while a move key is held"), tr("Walking speed still caps how fast you move")}},
+		OnChange: func(val int) {
+			m.menu.repeatRate = val
+			m.updateSettings()
+		},
 	}
-	m.menu.buttons = append(m.menu.buttons, difficultyLabel)
 
-	buttonY += 35
-	difficultyButtons := []*Button{}
+	difficultyLabels := make([]string, len(difficulties))
 	for i, diff := range difficulties {
-		diffIndex := i // Capture the index for closure
-		button := &Button{
-			X:        m.settings.ScreenWidth/2 - 150 + (i%2)*150,
-			Y:        buttonY + (i/2)*35,
-			Width:    140,
-			Height:   30,
-			Label:    diff.Label,
-			Selected: i == m.menu.selectedDifficulty,
-			OnClick: func() {
-				// Deselect all difficulty buttons first
-				for j, btn := range m.menu.buttons {
-					for _, d := range difficulties {
-						if btn.Label == d.Label {
-							m.menu.buttons[j].Selected = false
-						}
-					}
-				}
+		difficultyLabels[i] = tr(diff.Label)
+	}
+	difficulty := &ui.Dropdown{
+		Label:    tr("Difficulty"),
+		Options:  difficultyLabels,
+		Selected: m.menu.selectedDifficulty,
+		OnChange: func(i int) {
+			m.menu.selectedDifficulty = i
+			m.updateSettings()
+		},
+	}
 
-				// Now select this button
-				for j, btn := range m.menu.buttons {
-					if btn.Label == difficulties[diffIndex].Label {
-						m.menu.buttons[j].Selected = true
-						break
-					}
-				}
+	sightLabels := make([]string, len(fovAlgorithms))
+	for i, a := range fovAlgorithms {
+		sightLabels[i] = tr(a.String())
+	}
+	sight := &ui.Dropdown{
+		Label:    tr("Sight"),
+		Options:  sightLabels,
+		Selected: m.menu.selectedSight,
+		Tooltip:  ui.Tooltip{Title: tr("Sight"), Lines: []string{tr("How walls block the view, for you and monsters"), tr("Shadowcasting hides what is behind corners and pillars")}},
+		OnChange: func(i int) {
+			m.menu.selectedSight = i
+			m.updateSettings()
+		},
+	}
+
+	m.menu.seedField = &ui.Button{
+		Label:    m.seedFieldLabel(),
+		Selected: m.menu.seedFieldActive,
+		Tooltip:  ui.Tooltip{Title: tr("Seed"), Lines: []string{tr("Replay a shared run by typing or pasting its seed (Ctrl+V)"), tr("Leave empty for a random dungeon")}},
+		OnClick: func() {
+			m.menu.seedFieldActive = true
+			m.refreshSeedField()
+		},
+	}
 
-				m.menu.selectedDifficulty = diffIndex
+	// toggle builds an ON/OFF switch bound to one of the menu's flags
+	toggle := func(label string, value *bool, tooltip ui.Tooltip) *ui.Toggle {
+		return &ui.Toggle{
+			Label:   label,
+			Value:   *value,
+			Tooltip: tooltip,
+			OnChange: func(on bool) {
+				*value = on
 				m.updateSettings()
 			},
 		}
-		difficultyButtons = append(difficultyButtons, button)
-	}
-	m.menu.buttons = append(m.menu.buttons, difficultyButtons...)
-
-	buttonY += 70 + buttonSpacing
-
-	// FOV toggle button
-	fovButton := &Button{
-		X:      m.settings.ScreenWidth/2 - 150,
-		Y:      buttonY,
-		Width:  300,
-		Height: 30,
-		Label: fmt.Sprintf("Field of View: %v", func() string {
-			if m.menu.enableFOV {
-				return "ON"
-			} else {
-				return "OFF"
-			}
-		}()),
-		Selected: m.menu.enableFOV,
-		OnClick: func() {
-			m.menu.enableFOV = !m.menu.enableFOV
-
-			// Update this button's state and label
-			for j, btn := range m.menu.buttons {
-				if strings.HasPrefix(btn.Label, "Field of View:") {
-					m.menu.buttons[j].Selected = m.menu.enableFOV
-					m.menu.buttons[j].Label = fmt.Sprintf("Field of View: %v", func() string {
-						if m.menu.enableFOV {
-							return "ON"
-						} else {
-							return "OFF"
-						}
-					}())
-					break
-				}
-			}
-
-			m.updateSettings()
-		},
 	}
-	m.menu.buttons = append(m.menu.buttons, fovButton)
 
-	buttonY += buttonSpacing + 20
+	m.menu.interactToggle = toggle("", &m.menu.interactKey, ui.Tooltip{})
+	m.refreshInteractToggle()
 
 	// Dungeon size sliders
-	dungeonWidthSlider := &Slider{
-		X:        m.settings.ScreenWidth/2 - 150,
-		Y:        buttonY,
-		Width:    300,
-		Height:   20,
-		Label:    fmt.Sprintf("Dungeon Width: %d", m.menu.dungeonWidth),
-		MinValue: 20,
-		MaxValue: 80,
-		Value:    m.menu.dungeonWidth,
+	dungeonWidth := &ui.Slider{
+		Label: tr("Dungeon Width"),
+		Min:   20,
+		Max:   80,
+		Value: m.menu.dungeonWidth,
 		OnChange: func(val int) {
 			m.menu.dungeonWidth = val
 			m.updateSettings()
 		},
 	}
-
-	buttonY += 50
-
-	dungeonHeightSlider := &Slider{
-		X:        m.settings.ScreenWidth/2 - 150,
-		Y:        buttonY,
-		Width:    300,
-		Height:   20,
-		Label:    fmt.Sprintf("Dungeon Height: %d", m.menu.dungeonHeight),
-		MinValue: 10,
-		MaxValue: 40,
-		Value:    m.menu.dungeonHeight,
+	dungeonHeight := &ui.Slider{
+		Label: tr("Dungeon Height"),
+		Min:   10,
+		Max:   40,
+		Value: m.menu.dungeonHeight,
 		OnChange: func(val int) {
 			m.menu.dungeonHeight = val
 			m.updateSettings()
 		},
 	}
 
-	m.menu.sliders = []*Slider{dungeonWidthSlider, dungeonHeightSlider}
-
-	buttonY += 70
+	// Player profile: name and color
+	m.menu.nameField = &ui.Button{
+		Label:    m.nameFieldLabel(),
+		Selected: m.menu.nameFieldActive,
+		OnClick: func() {
+			m.menu.nameFieldActive = true
+			m.refreshNameField()
+		},
+	}
+	colors := &ui.Grid{Columns: 3, Gap: 10}
+	colorButtons := make([]*ui.Button, len(playerColors))
+	for i, pc := range playerColors {
+		colorIndex := i // Capture the index for closure
+		colorButtons[i] = &ui.Button{
+			Label:    tr(pc.Label),
+			Selected: i == m.menu.selectedColor,
+			Swatch:   pc.Color,
+			OnClick: func() {
+				// Select only this color button
+				for j, btn := range colorButtons {
+					btn.Selected = j == colorIndex
+				}
+				m.menu.selectedColor = colorIndex
+				m.updateSettings()
+			},
+		}
+		colors.Children = append(colors.Children, colorButtons[i])
+	}
 
-	// Start Game button
-	startButton := &Button{
-		X:        m.settings.ScreenWidth/2 - 100,
-		Y:        buttonY,
-		Width:    200,
-		Height:   40,
-		Label:    "Start Game",
-		Selected: false,
+	// Controls section: click an action, then press its new key
+	controls := &ui.Grid{Columns: 2, Gap: 10}
+	for a := Action(0); a < numActions; a++ {
+		action := a // Capture the action for closure
+		m.menu.bindButtons[a] = &ui.Button{
+			Label: m.bindingLabel(action),
+			OnClick: func() {
+				m.menu.rebinding = action
+				m.menu.rebindActive = true
+				m.refreshBindingLabels()
+			},
+		}
+		controls.Children = append(controls.Children, m.menu.bindButtons[a])
+	}
+	resetControls := &ui.Button{
+		Label: tr("Reset Controls"),
 		OnClick: func() {
-			m.startGame()
+			bindings = defaultBindings
+			m.menu.rebindActive = false
+			if err := saveBindings(bindings); err != nil {
+				log.Printf("could not save key bindings: %v", err)
+			}
+			m.refreshBindingLabels()
 		},
 	}
-	m.menu.buttons = append(m.menu.buttons, startButton)
 
-	// Calculate total content height for scrollbar
-	m.menu.contentHeight = buttonY + 60 // Add some padding at the bottom
+	children := []ui.Widget{
+		&ui.Label{Text: tr("Display")},
+		resolution,
+		m.menu.tileSize,
+		toggle(tr("Sprite Tiles"), &m.menu.spriteTiles,
+			ui.Tooltip{Title: tr("Sprite Tiles"), Lines: []string{tr("Draw the dungeon with the tileset"), tr("Turn off for plain colored squares")}}),
+		easing,
+		shake,
+		toggle(tr("Vignette"), &m.menu.vignette,
+			ui.Tooltip{Title: tr("Vignette"), Lines: []string{tr("The dungeon fades out towards the edge of your light")}}),
+		toggle(tr("CRT Scanlines"), &m.menu.scanlines,
+			ui.Tooltip{Title: tr("CRT Scanlines"), Lines: []string{tr("Draw the screen like an old monitor")}}),
+		toggle(tr("Low Health Pulse"), &m.menu.lowHealthPulse,
+			ui.Tooltip{Title: tr("Low Health Pulse"), Lines: []string{tr("The screen's edges pulse red when you are close to death")}}),
+		language,
+		&ui.Label{Text: tr("Gameplay")},
+		difficulty,
+		m.menu.seedField,
+		toggle(tr("Field of View"), &m.menu.enableFOV,
+			ui.Tooltip{Title: tr("Field of View"), Lines: []string{tr("Only what your light reaches is shown"), tr("Explored tiles stay on the map")}}),
+		sight,
+		fog,
+		toggle(tr("Casual Mode"), &m.menu.casualMode,
+			ui.Tooltip{Title: tr("Casual Mode"), Lines: []string{tr("Dying drops a satchel with your gold and items"), tr("The next run revisits that dungeon to recover it")}}),
+		toggle(tr("Reduced Motion"), &m.menu.reducedMotion,
+			ui.Tooltip{Title: tr("Reduced Motion"), Lines: []string{tr("No screen shake"), tr("Timing minigames become dice rolls")}}),
+		toggle(tr("Turn-Based"), &m.menu.turnBased,
+			ui.Tooltip{Title: tr("Turn-Based"), Lines: []string{tr("The world only moves when you do")}}),
+		toggle(tr("Arena Mode"), &m.menu.arena,
+			ui.Tooltip{Title: tr("Arena Mode"), Lines: []string{tr("One open floor and endless waves of monsters"), tr("Each wave raises the score multiplier")}}),
+		toggle(tr("Confirm Danger"), &m.menu.confirmDanger,
+			ui.Tooltip{Title: tr("Confirm Danger"), Lines: []string{tr("Ask before attacking a healthy monster"), tr("or taking the exit")}}),
+		m.menu.interactToggle,
+		autosave,
+		&ui.Label{Text: tr("Dungeon Size")},
+		dungeonWidth,
+		dungeonHeight,
+		&ui.Label{Text: tr("Player Profile")},
+		m.menu.nameField,
+		colors,
+		&ui.Label{Text: tr("Controls"), Tooltip: ui.Tooltip{Title: tr("Controls"), Lines: []string{tr("Click an action, then press its new key"), tr("Esc cancels; Shift + move keys pans the view")}}},
+		controls,
+		resetControls,
+		repeatDelay,
+		repeatRate,
+		&ui.Label{}, // Spacer before the start buttons
+	}
+
+	// The last saved run is picked up ahead of starting a new one
+	if slot, ok := latestSave(loadSaveSummaries()); ok {
+		children = append(children,
+			&ui.Button{Label: tr("Continue"), Height: 40, OnClick: func() { m.loadSlot(slot) }},
+			&ui.Button{Label: tr("Load Game"), OnClick: m.showSaves},
+		)
+	}
+	children = append(children,
+		&ui.Button{Label: tr("Start Game"), Height: 40, OnClick: m.startGame},
+		&ui.Button{Label: tr("High Scores"), OnClick: m.showHighScores},
+	)
+
+	// Once a run has been played, offer it again without touching the options
+	if m.lastRun != nil {
+		children = append(children, &ui.Button{
+			Label:   tr("Restart Last Run"),
+			Height:  40,
+			OnClick: func() { m.startRun(*m.lastRun) },
+		})
+	}
+
+	// Keep the scroll position and keyboard focus when the menu is rebuilt
+	scrollY, focus := 0, -1
+	if m.menu.root != nil {
+		scrollY, focus = m.menu.root.ScrollY, m.menu.root.FocusIndex()
+	}
+	m.menu.root = &ui.VBox{
+		Width:    300,
+		Gap:      8,
+		Padding:  20,
+		Children: children,
+		ScrollY:  scrollY,
+	}
+	m.menu.root.SetRect(menuAnchor.Resolve(m.bounds))
+	m.menu.root.FocusNth(focus)
 }
 
 // Update the game settings based on menu selections
 func (m *MainGame) updateSettings() {
 	m.settings.ScreenWidth = resolutions[m.menu.selectedResolution].Width
 	m.settings.ScreenHeight = resolutions[m.menu.selectedResolution].Height
+	m.settings.AutoTileSize = tileSizeOptions[m.menu.selectedTileSize] == autoTileSize
 	m.settings.TileSize = tileSizeOptions[m.menu.selectedTileSize]
+	if m.settings.AutoTileSize {
+		screenW, screenH := m.screenSize()
+		m.settings.TileSize = fitTileSize(screenW, screenH, m.menu.dungeonWidth, m.menu.dungeonHeight)
+	}
+	m.settings.SpriteTiles = m.menu.spriteTiles
+	m.settings.CameraEasing = cameraEasings[m.menu.selectedEasing].Rate
+	m.settings.ShakeIntensity = shakeIntensities[m.menu.selectedShake].Scale
+	m.settings.FogOpacity = float64(m.menu.fogOpacity) / 100
+	m.settings.RepeatDelay = m.menu.repeatDelay * ticksPerSecond / 1000
+	m.settings.RepeatInterval = ticksPerSecond / m.menu.repeatRate
+	m.settings.AutosaveTurns = m.menu.autosaveTurns
+	m.settings.Vignette = m.menu.vignette
+	m.settings.Scanlines = m.menu.scanlines
+	m.settings.LowHealthPulse = m.menu.lowHealthPulse
 	m.settings.DungeonWidth = m.menu.dungeonWidth
 	m.settings.DungeonHeight = m.menu.dungeonHeight
 	m.settings.EnableFOV = m.menu.enableFOV
-	m.settings.DifficultyMods.Monster = difficulties[m.menu.selectedDifficulty].MonsterMod
-	m.settings.DifficultyMods.Treasure = difficulties[m.menu.selectedDifficulty].TreasureMod
+	m.settings.FOVAlgorithm = fovAlgorithms[m.menu.selectedSight]
+	m.settings.CasualMode = m.menu.casualMode
+	m.settings.ReducedMotion = m.menu.reducedMotion
+	m.settings.TurnBased = m.menu.turnBased
+	m.settings.Arena = m.menu.arena
+	m.settings.ConfirmDanger = m.menu.confirmDanger
+	m.settings.InteractKey = m.menu.interactKey
+	m.settings.StartLevel = difficulties[m.menu.selectedDifficulty].Level
+	m.settings.Seed = 0
+	if seed, err := parseSeed(m.menu.seedText); err == nil {
+		m.settings.Seed = seed
+	}
+	m.settings.PlayerName = m.menu.playerName
+	m.settings.PlayerColor = playerColors[m.menu.selectedColor].Color
+	m.settings.Difficulty = curveFor(difficulties[m.menu.selectedDifficulty].Label)
+	m.savePrefs()
+
+	// Keep the Auto option showing the size it currently resolves to
+	if m.menu.tileSize != nil {
+		for i, size := range tileSizeOptions {
+			if size == autoTileSize {
+				m.menu.tileSize.Options[i] = m.tileSizeLabel(size)
+			}
+		}
+	}
+}
 
-	// Update window size
-	ebiten.SetWindowSize(m.settings.ScreenWidth, m.settings.ScreenHeight)
+// savePrefs writes the menu's choices to the settings file when they have
+// changed since it was last written
+func (m *MainGame) savePrefs() {
+	prefs := m.menu.prefs()
+	if prefs == m.prefs {
+		return
+	}
+	if err := saveMenuPrefs(prefs); err != nil {
+		log.Printf("could not save settings: %v", err)
+		return
+	}
+	m.prefs = prefs
 }
 
-// Start the game with current settings
-func (m *MainGame) startGame() {
-	// Create a new game with the selected settings
-	dungeon := NewDungeon(m.settings.DungeonWidth, m.settings.DungeonHeight, difficulties[m.menu.selectedDifficulty].Level)
-	player := NewPlayer(dungeon.Entrance)
-	player.FOVEnabled = m.settings.EnableFOV
-
-	// Create the interaction handler with difficulty modifiers
-	interactionHandler := NewInteractionHandler()
-
-	// Register interactions for different cell types with difficulty modifiers
-	interactionHandler.Register(Monster, NewMonsterInteraction(1))            // Will be overridden per cell
-	interactionHandler.Register(Treasure, NewTreasureInteraction(10, "gold")) // Will be overridden per cell
-	interactionHandler.Register(Exit, NewExitInteraction(2))                  // Go to level 2
-
-	m.game = &Game{
-		dungeon:            dungeon,
-		player:             player,
-		interactionHandler: interactionHandler,
-	}
-
-	// Apply difficulty modifiers to monsters and treasures
-	for y := 0; y < dungeon.Height; y++ {
-		for x := 0; x < dungeon.Width; x++ {
-			cell := &dungeon.Cells[y][x]
-			if cell.Type == Monster {
-				cell.InteractionLevel = int(float64(cell.InteractionLevel) * m.settings.DifficultyMods.Monster)
-				if cell.InteractionLevel < 1 {
-					cell.InteractionLevel = 1
-				}
-			} else if cell.Type == Treasure {
-				cell.InteractionLevel = int(float64(cell.InteractionLevel) * m.settings.DifficultyMods.Treasure)
-				if cell.InteractionLevel < 5 {
-					cell.InteractionLevel = 5 // Minimum treasure value
-				}
-			}
-		}
+// tileSizeLabel returns the button label for a tile size option
+func (m *MainGame) tileSizeLabel(size int) string {
+	if size == autoTileSize {
+		screenW, screenH := m.screenSize()
+		return tr("Auto (%dpx)", fitTileSize(screenW, screenH, m.menu.dungeonWidth, m.menu.dungeonHeight))
 	}
+	return fmt.Sprintf("%dpx", size)
+}
 
-	m.state = StateGame
+// nameFieldLabel renders the player name field, with a caret while editing
+func (m *MainGame) nameFieldLabel() string {
+	label := tr("Name: %s", m.menu.playerName)
+	if m.menu.nameFieldActive {
+		label += "_"
+	}
+	return label
+}
 
-	// Set global tileSize variable used in other files
-	tileSize = m.settings.TileSize
+// refreshNameField syncs the name field button with the current name and focus
+func (m *MainGame) refreshNameField() {
+	m.menu.nameField.Label = m.nameFieldLabel()
+	m.menu.nameField.Selected = m.menu.nameFieldActive
 }
 
-// Use the standard library strings package for string operations
+// updateNameField applies typed characters to the player name while the field is focused
+func (m *MainGame) updateNameField() {
+	if !m.menu.nameFieldActive {
+		return
+	}
 
-func (m *MainGame) Update() error {
-	switch m.state {
-	case StateMenu:
-		mouseX, mouseY := ebiten.CursorPosition()
-
-		// Handle scrolling with mouse wheel
-		_, wheelY := ebiten.Wheel()
-		if wheelY != 0 {
-			m.menu.scrollY -= int(wheelY * 20)
-			// Clamp scrolling
-			maxScroll := m.menu.contentHeight - m.settings.ScreenHeight + 40
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
+	name := []rune(m.menu.playerName)
+	name = ebiten.AppendInputChars(name)
+	if input.JustPressed(ActionErase) && len(name) > 0 {
+		name = name[:len(name)-1]
+	}
+	if len(name) > maxPlayerNameLen {
+		name = name[:maxPlayerNameLen]
+	}
+	m.menu.playerName = string(name)
 
-			if m.menu.scrollY < 0 {
-				m.menu.scrollY = 0
-			} else if m.menu.scrollY > maxScroll {
-				m.menu.scrollY = maxScroll
-			}
+	if input.JustPressed(ActionConfirm) || input.JustPressed(ActionCancel) {
+		m.menu.nameFieldActive = false
+		// Never start a run with a blank name
+		if strings.TrimSpace(m.menu.playerName) == "" {
+			m.menu.playerName = defaultPlayerName
 		}
+	}
 
-		// Calculate scrollbar properties
-		viewportHeight := m.settings.ScreenHeight
-		scrollBarHeight := int(float64(viewportHeight) * float64(viewportHeight) / float64(m.menu.contentHeight))
-		if scrollBarHeight < 30 {
-			scrollBarHeight = 30 // Minimum height for visibility
-		}
+	m.refreshNameField()
+	m.settings.PlayerName = m.menu.playerName
+}
+
+// seedFieldLabel renders the seed field, with a caret while editing
+func (m *MainGame) seedFieldLabel() string {
+	text := m.menu.seedText
+	if text == "" && !m.menu.seedFieldActive {
+		text = tr("Random")
+	}
+	label := tr("Seed: %s", text)
+	if m.menu.seedFieldActive {
+		label += "_"
+	}
+	return label
+}
+
+// refreshSeedField syncs the seed field button with the current text and focus
+func (m *MainGame) refreshSeedField() {
+	m.menu.seedField.Label = m.seedFieldLabel()
+	m.menu.seedField.Selected = m.menu.seedFieldActive
+}
 
-		// Calculate scrollbar position
-		maxScroll := m.menu.contentHeight - viewportHeight + 40
-		if maxScroll <= 0 {
-			m.menu.scrollBarY = 0
+// updateSeedField takes digits typed or pasted with Ctrl+V into the seed
+// field while it is focused
+func (m *MainGame) updateSeedField() {
+	if !m.menu.seedFieldActive {
+		return
+	}
+
+	text := m.menu.seedText
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r >= '0' && r <= '9' || r == '-' && text == "" {
+			text += string(r)
+		}
+	}
+	if input.JustPressed(ActionErase) && len(text) > 0 {
+		text = text[:len(text)-1]
+	}
+	if input.JustPressed(ActionPaste) {
+		if seed, err := pasteSeed(); err != nil {
+			log.Printf("could not paste seed: %v", err)
 		} else {
-			m.menu.scrollBarY = int(float64(m.menu.scrollY) / float64(maxScroll) * float64(viewportHeight-scrollBarHeight))
+			text = strconv.FormatInt(seed, 10)
 		}
+	}
+	if len(text) > maxSeedLen {
+		text = text[:maxSeedLen]
+	}
+	m.menu.seedText = text
 
-		// Handle scrollbar dragging
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-			// Check if clicked on scrollbar
-			scrollBarX := m.settings.ScreenWidth - 20
-			scrollBarWidth := 10
+	if input.JustPressed(ActionConfirm) || input.JustPressed(ActionCancel) {
+		m.menu.seedFieldActive = false
+		// Anything that isn't a whole seed means a random run
+		if _, err := parseSeed(m.menu.seedText); err != nil {
+			m.menu.seedText = ""
+		}
+	}
 
-			if !m.menu.scrollBarGrab && mouseX >= scrollBarX && mouseX <= scrollBarX+scrollBarWidth &&
-				mouseY >= m.menu.scrollBarY && mouseY <= m.menu.scrollBarY+scrollBarHeight {
-				m.menu.scrollBarGrab = true
-			}
+	m.refreshSeedField()
+	m.updateSettings()
+}
 
-			if m.menu.scrollBarGrab {
-				// Calculate scroll position based on mouse position
-				maxScrollBarPos := viewportHeight - scrollBarHeight
-				scrollPct := float64(mouseY) / float64(maxScrollBarPos)
-				if scrollPct < 0 {
-					scrollPct = 0
-				}
-				if scrollPct > 1 {
-					scrollPct = 1
-				}
+// bindingLabel renders a controls button, which prompts for a key while rebinding
+func (m *MainGame) bindingLabel(a Action) string {
+	if m.menu.rebindActive && m.menu.rebinding == a {
+		return tr("%s: [press]", tr(a.String()))
+	}
+	return fmt.Sprintf("%s: %s", tr(a.String()), bindings.Key(a))
+}
 
-				maxScroll := m.menu.contentHeight - viewportHeight + 40
-				m.menu.scrollY = int(scrollPct * float64(maxScroll))
-				if m.menu.scrollY < 0 {
-					m.menu.scrollY = 0
-				} else if m.menu.scrollY > maxScroll {
-					m.menu.scrollY = maxScroll
-				}
-			}
-		} else {
-			m.menu.scrollBarGrab = false
+// refreshBindingLabels syncs every controls button with the current bindings
+func (m *MainGame) refreshBindingLabels() {
+	for a, btn := range m.menu.bindButtons {
+		btn.Label = m.bindingLabel(Action(a))
+		btn.Selected = m.menu.rebindActive && m.menu.rebinding == Action(a)
+	}
+	m.refreshInteractToggle()
+}
+
+// refreshInteractToggle names the current interact key on its toggle
+func (m *MainGame) refreshInteractToggle() {
+	t := m.menu.interactToggle
+	if t == nil {
+		return
+	}
+	key := bindings.Key(ActionInteract)
+	t.Label = tr("Interact Key (%s)", key)
+	t.Tooltip = ui.Tooltip{Title: t.Label, Lines: []string{
+		tr("Shrines, levers and other objects wait for %s", key),
+		tr("instead of triggering when you walk into them"),
+	}}
+}
+
+// updateRebinding binds the next key pressed to the action being rebound and
+// saves it. Escape cancels, so it always stays free for the pause menu.
+func (m *MainGame) updateRebinding() {
+	if !m.menu.rebindActive {
+		return
+	}
+
+	keys := inpututil.AppendJustPressedKeys(nil)
+	if len(keys) == 0 {
+		return
+	}
+	m.menu.rebindActive = false
+	if keys[0] != ebiten.KeyEscape {
+		bindings.Bind(m.menu.rebinding, keys[0])
+		if err := saveBindings(bindings); err != nil {
+			log.Printf("could not save key bindings: %v", err)
 		}
+	}
+	m.refreshBindingLabels()
+}
 
-		// Handle mouse button clicks on UI elements
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			// Adjust mouse Y position for scrolling
-			adjustedMouseY := mouseY + m.menu.scrollY
-
-			// Check button clicks
-			for _, button := range m.menu.buttons {
-				if button.OnClick != nil &&
-					mouseX >= button.X && mouseX < button.X+button.Width &&
-					adjustedMouseY >= button.Y && adjustedMouseY < button.Y+button.Height {
-					button.OnClick()
-				}
-			}
+// Start the game with current settings
+func (m *MainGame) startGame() {
+	m.startRun(m.settings)
+}
 
-			// Check slider clicks
-			for i, slider := range m.menu.sliders {
-				if adjustedMouseY >= slider.Y && adjustedMouseY < slider.Y+slider.Height &&
-					mouseX >= slider.X && mouseX < slider.X+slider.Width {
-					// Calculate position within slider
-					pos := float64(mouseX-slider.X) / float64(slider.Width)
-					newVal := slider.MinValue + int(pos*float64(slider.MaxValue-slider.MinValue))
-					if newVal < slider.MinValue {
-						newVal = slider.MinValue
-					}
-					if newVal > slider.MaxValue {
-						newVal = slider.MaxValue
-					}
-					slider.Value = newVal
-					slider.Active = true
-
-					// Update slider label
-					if i == 0 {
-						slider.Label = fmt.Sprintf("Dungeon Width: %d", newVal)
-						m.menu.dungeonWidth = newVal
-					} else if i == 1 {
-						slider.Label = fmt.Sprintf("Dungeon Height: %d", newVal)
-						m.menu.dungeonHeight = newVal
-					}
-
-					if slider.OnChange != nil {
-						slider.OnChange(newVal)
-					}
-				}
-			}
+// startRun starts a fresh run with the given settings
+func (m *MainGame) startRun(settings GameSettings) {
+	runSeed := time.Now().UnixNano()
+
+	// A seed from the menu replays a shared run. Otherwise a casual run
+	// replays the seed of the run that lost a satchel, so the floor it was
+	// dropped on can be generated again
+	if settings.Seed != 0 {
+		runSeed = settings.Seed
+	} else if settings.CasualMode {
+		if satchel, err := loadLostSatchel(); err != nil {
+			log.Printf("could not load lost satchel: %v", err)
+		} else if satchel != nil {
+			runSeed = satchel.RunSeed
 		}
+	}
 
-		// Update active sliders even when mouse is held
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-			for i, slider := range m.menu.sliders {
-				if slider.Active {
-					// Calculate position within slider
-					pos := float64(mouseX-slider.X) / float64(slider.Width)
-					newVal := slider.MinValue + int(pos*float64(slider.MaxValue-slider.MinValue))
-					newVal = int(math.Max(float64(slider.MinValue), math.Min(float64(slider.MaxValue), float64(newVal))))
-					slider.Value = newVal
-
-					// Update slider label and value
-					if i == 0 {
-						slider.Label = fmt.Sprintf("Dungeon Width: %d", newVal)
-						m.menu.dungeonWidth = newVal
-					} else if i == 1 {
-						slider.Label = fmt.Sprintf("Dungeon Height: %d", newVal)
-						m.menu.dungeonHeight = newVal
-					}
-
-					if slider.OnChange != nil {
-						slider.OnChange(newVal)
-					}
-				}
-			}
-		} else {
-			// Reset active state when mouse button is released
-			for i := range m.menu.sliders {
-				m.menu.sliders[i].Active = false
-			}
+	// The run appears once its first floor has generated in the background
+	m.game = nil
+	m.loading = NewRunLoader(settings, runSeed)
+	m.state = StateLoading
+	m.lastRun = &settings
+}
+
+// loadSlot loads the run in a save slot and carries on from where it was saved
+func (m *MainGame) loadSlot(slot int) {
+	save, err := LoadGame(slot)
+	if err != nil {
+		log.Printf("could not load saved game: %v", err)
+		return
+	}
+	if save == nil {
+		return
+	}
+	m.game = nil
+	m.loading = NewSaveLoader(save)
+	m.state = StateLoading
+	m.lastRun = &save.Settings
+}
+
+// showSaves switches to the load page, with a button to load each filled
+// slot and one to delete it
+func (m *MainGame) showSaves() {
+	m.state = StateSaves
+	children := []ui.Widget{}
+	for slot, s := range loadSaveSummaries() {
+		if s == nil {
+			children = append(children, &ui.Label{Text: saveSlotLabel(slot, s)})
+			continue
 		}
+		children = append(children,
+			&ui.Button{Label: saveSlotLabel(slot, s), Height: 40, OnClick: func() { m.loadSlot(slot) }},
+			&ui.Button{Label: tr("Delete %s", saveSlotName(slot)), OnClick: func() { m.deleting = slot + 1 }},
+		)
+	}
+	children = append(children, &ui.Label{}, &ui.Button{Label: tr("Back"), Height: 40, OnClick: m.closeSaves})
+	m.saves = &ui.VBox{Width: 460, Gap: 8, Padding: 20, Children: children}
+	m.saves.SetRect(savesAnchor.Resolve(m.bounds))
+	m.saves.FocusNth(0)
+}
 
-	case StateGame:
-		if m.game != nil {
-			return m.game.Update()
+// closeSaves goes back from the load page to the menu, which drops its
+// Continue button if the last save was deleted
+func (m *MainGame) closeSaves() {
+	m.state = StateMenu
+	m.initializeMenu()
+}
+
+// updateDeletePrompt answers the delete prompt: Y or Enter empties the slot,
+// N or Escape keeps it
+func (m *MainGame) updateDeletePrompt() {
+	switch {
+	case input.JustPressed(ActionYes) || input.JustPressed(ActionConfirm):
+		if err := clearSavedGame(m.deleting - 1); err != nil {
+			log.Printf("could not delete saved game: %v", err)
 		}
+		m.deleting = 0
+		m.showSaves()
+	case input.JustPressed(ActionNo) || input.JustPressed(ActionCancel):
+		m.deleting = 0
 	}
+}
 
+// updateQuitPrompt answers the quit prompt: Y or Enter closes the game, N or
+// Escape goes back to the menu
+func (m *MainGame) updateQuitPrompt() error {
+	switch {
+	case input.JustPressed(ActionYes) || input.JustPressed(ActionConfirm):
+		return ebiten.Termination
+	case input.JustPressed(ActionNo) || input.JustPressed(ActionCancel):
+		m.quitting = false
+	}
 	return nil
 }
 
-func (m *MainGame) Draw(screen *ebiten.Image) {
-	switch m.state {
-	case StateMenu:
-		// Draw background
-		screen.Fill(color.RGBA{20, 20, 30, 255})
+// drawPrompt asks a yes or no question in the middle of the screen, with
+// the keys that answer it
+func drawPrompt(screen *ebiten.Image, question, answers string) {
+	panelW, panelH := 300, 62
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := screen.Bounds().Dy()/2 - panelH/2
+	drawPanel(screen, panelX, panelY, panelW, panelH)
 
-		// Create a clipping area for scrolling content
-		clipY := 0
-		clipHeight := m.settings.ScreenHeight
+	ui.DrawText(screen, question, panelX+6, panelY+4)
+	ui.DrawText(screen, answers, panelX+6, panelY+36)
+}
 
-		// Draw title (always visible, doesn't scroll)
-		titleText := "Procedural Dungeon - Game Options"
-		titleX := m.settings.ScreenWidth/2 - len(titleText)*4
-		ebitenutil.DebugPrintAt(screen, titleText, titleX, 80)
-
-		// Draw scrollable content
-		for _, button := range m.menu.buttons {
-			// Adjust y position for scrolling
-			adjY := button.Y - m.menu.scrollY
-
-			// Skip rendering if outside the viewport
-			if adjY+button.Height < clipY || adjY > clipY+clipHeight {
-				continue
-			}
+// updateLoading swaps in the new run once its first floor is ready. Escape
+// gives up on it and returns to the menu.
+func (m *MainGame) updateLoading() {
+	if input.JustPressed(ActionCancel) {
+		m.loading = nil
+		m.state = StateMenu
+		m.initializeMenu()
+		return
+	}
+	game := m.loading.Update()
+	if game == nil {
+		return
+	}
+	m.game = game
+	m.loading = nil
+	m.state = StateGame
 
-			// Skip buttons that are just labels
-			if button.OnClick == nil {
-				ebitenutil.DebugPrintAt(screen, button.Label, button.X+10, adjY+10)
-				continue
-			}
+	// Set global tileSize variable used in other files
+	tileSize = m.lastRun.TileSize
+	spriteTiles = m.lastRun.SpriteTiles
+	fovAlgorithm = m.lastRun.FOVAlgorithm
+	fogOpacity = m.lastRun.FogOpacity
+	keyRepeatDelay = m.lastRun.RepeatDelay
+	keyRepeatInterval = m.lastRun.RepeatInterval
+}
 
-			// Draw button background
-			bgColor := color.RGBA{50, 50, 60, 255}
-			if button.Selected {
-				bgColor = color.RGBA{100, 100, 200, 255}
-			}
+// quitToMenu drops the current run and brings back the options menu as it
+// was left, with a shortcut to restart using the last run's settings
+func (m *MainGame) quitToMenu() {
+	m.lastRank = m.game.highScoreRank
+	m.game = nil
+	m.state = StateMenu
+	m.menu.nameFieldActive = false
+	m.menu.seedFieldActive = false
+	m.menu.rebindActive = false
+	m.initializeMenu()
+}
+
+// showHighScores switches to the high score page, with its back button
+// focused so Enter or A returns straight away
+func (m *MainGame) showHighScores() {
+	m.state = StateHighScores
+	m.scores = &ui.VBox{
+		Width:    200,
+		Children: []ui.Widget{&ui.Button{Label: tr("Back"), Height: 40, OnClick: func() { m.state = StateMenu }}},
+	}
+	m.scores.SetRect(scoresAnchor.Resolve(m.bounds))
+	m.scores.FocusNth(0)
+}
 
-			vector.DrawFilledRect(screen, float32(button.X), float32(adjY),
-				float32(button.Width), float32(button.Height), bgColor, false)
+// relayout places the menu pages again after the screen changed size
+func (m *MainGame) relayout() {
+	if m.laidOut == m.bounds {
+		return
+	}
+	m.laidOut = m.bounds
+	m.updateSettings() // Auto tile sizes follow the window
+	m.menu.root.SetRect(menuAnchor.Resolve(m.bounds))
+	if m.scores != nil {
+		m.scores.SetRect(scoresAnchor.Resolve(m.bounds))
+	}
+	if m.saves != nil {
+		m.saves.SetRect(savesAnchor.Resolve(m.bounds))
+	}
+}
 
-			// Draw button border
-			borderColor := color.RGBA{200, 200, 220, 255}
-			vector.StrokeRect(screen, float32(button.X), float32(adjY),
-				float32(button.Width), float32(button.Height), 1, borderColor, false)
+// Use the standard library strings package for string operations
 
-			// Draw button text
-			ebitenutil.DebugPrintAt(screen, button.Label, button.X+10, adjY+10)
+func (m *MainGame) Update() error {
+	input = readInput()
+	m.relayout()
+	switch m.state {
+	case StateMenu:
+		if m.quitting {
+			return m.updateQuitPrompt()
 		}
 
-		// Draw sliders
-		for _, slider := range m.menu.sliders {
-			// Adjust y position for scrolling
-			adjY := slider.Y - m.menu.scrollY
+		// Keys typed into the name field or taken by a rebind are not menu
+		// navigation, even the Enter or Escape that ends them
+		typing := m.menu.nameFieldActive || m.menu.seedFieldActive || m.menu.rebindActive
+		m.updateNameField()
+		m.updateSeedField()
+		m.updateRebinding()
+
+		// Escape with nothing left to back out of asks to quit
+		if !typing && input.JustPressed(ActionCancel) {
+			m.quitting = true
+			break
+		}
 
-			// Skip rendering if outside the viewport
-			if adjY+slider.Height < clipY || adjY > clipY+clipHeight {
-				continue
+		// Clicking anywhere drops focus from the name field and cancels a
+		// rebind; clicking the widget itself refocuses it
+		in := ui.ReadInput()
+		if typing {
+			in.Nav = ui.NavNone
+		}
+		if in.Clicked {
+			if m.menu.nameFieldActive {
+				m.menu.nameFieldActive = false
+				m.refreshNameField()
+			}
+			if m.menu.seedFieldActive {
+				m.menu.seedFieldActive = false
+				m.refreshSeedField()
 			}
+			if m.menu.rebindActive {
+				m.menu.rebindActive = false
+				m.refreshBindingLabels()
+			}
+		}
+		m.menu.root.Update(in)
+
+	case StateHighScores:
+		if input.JustPressed(ActionCancel) {
+			m.state = StateMenu
+			break
+		}
+		m.scores.Update(ui.ReadInput())
 
-			// Draw slider label
-			ebitenutil.DebugPrintAt(screen, slider.Label, slider.X, adjY-15)
-
-			// Draw slider track
-			trackColor := color.RGBA{80, 80, 90, 255}
-			vector.DrawFilledRect(screen, float32(slider.X), float32(adjY),
-				float32(slider.Width), float32(slider.Height), trackColor, false)
-
-			// Draw slider handle
-			handlePos := float32(slider.X) + float32(slider.Width)*
-				float32(slider.Value-slider.MinValue)/float32(slider.MaxValue-slider.MinValue)
-			handleColor := color.RGBA{180, 180, 220, 255}
-			vector.DrawFilledRect(screen,
-				handlePos-5, float32(adjY)-5,
-				10, float32(slider.Height)+10,
-				handleColor, false)
+	case StateSaves:
+		if m.deleting > 0 {
+			m.updateDeletePrompt()
+			break
 		}
+		if input.JustPressed(ActionCancel) {
+			m.closeSaves()
+			break
+		}
+		m.saves.Update(ui.ReadInput())
 
-		// Draw scrollbar if content is larger than viewport
-		if m.menu.contentHeight > m.settings.ScreenHeight {
-			scrollBarX := m.settings.ScreenWidth - 20
-			scrollBarWidth := 10
+	case StateLoading:
+		m.updateLoading()
 
-			// Calculate scrollbar height and position
-			viewportHeight := m.settings.ScreenHeight
-			scrollBarHeight := int(float64(viewportHeight) * float64(viewportHeight) / float64(m.menu.contentHeight))
-			if scrollBarHeight < 30 {
-				scrollBarHeight = 30 // Minimum height for visibility
+	case StateGame:
+		if m.game != nil {
+			if err := m.game.Update(); err != nil {
+				return err
 			}
+			if m.game.restartRequested {
+				m.startRun(*m.lastRun)
+			} else if m.game.quitRequested {
+				m.quitToMenu()
+			}
+		}
+	}
 
-			// Draw scrollbar track
-			trackColor := color.RGBA{40, 40, 50, 255}
-			vector.DrawFilledRect(screen, float32(scrollBarX), 0,
-				float32(scrollBarWidth), float32(viewportHeight), trackColor, false)
+	return nil
+}
 
-			// Draw scrollbar handle
-			handleColor := color.RGBA{100, 100, 120, 255}
-			if m.menu.scrollBarGrab {
-				handleColor = color.RGBA{120, 120, 150, 255}
-			}
-			vector.DrawFilledRect(screen, float32(scrollBarX), float32(m.menu.scrollBarY),
-				float32(scrollBarWidth), float32(scrollBarHeight), handleColor, false)
+func (m *MainGame) Draw(screen *ebiten.Image) {
+	switch m.state {
+	case StateMenu:
+		// Draw background
+		screen.Fill(color.RGBA{20, 20, 30, 255})
+
+		// Draw title (always visible, doesn't scroll)
+		centerX := screen.Bounds().Dx() / 2
+		ui.Text.Draw(screen, tr("Procedural Dungeon - Game Options"), centerX, 60,
+			ui.TextStyle{Size: ui.SizeTitle, Align: ui.AlignCenter, Bold: true})
+		ui.Text.Draw(screen, tr("Arrows / D-pad: move    Enter / A: select"), centerX, 96,
+			ui.TextStyle{Size: ui.SizeSmall, Color: color.RGBA{160, 160, 180, 255}, Align: ui.AlignCenter})
+
+		m.menu.root.Draw(screen, image.Point{})
+
+		// Explain the hovered widget, if it has anything to say, unless the
+		// quit prompt is up
+		if m.quitting {
+			drawPrompt(screen, tr("Quit the game?"), tr("Y/Enter: quit   N/Esc: stay"))
+		} else {
+			drawTooltip(screen, m.menu.root.TooltipAt(input.Cursor), input.Cursor.X, input.Cursor.Y)
+		}
+
+	case StateHighScores:
+		screen.Fill(color.RGBA{20, 20, 30, 255})
+		drawHighScores(screen, m.lastRank)
+		m.scores.Draw(screen, image.Point{})
+
+	case StateSaves:
+		screen.Fill(color.RGBA{20, 20, 30, 255})
+		ui.Text.Draw(screen, tr("Load Game"), screen.Bounds().Dx()/2, 60,
+			ui.TextStyle{Size: ui.SizeTitle, Align: ui.AlignCenter, Bold: true})
+		m.saves.Draw(screen, image.Point{})
+		if m.deleting > 0 {
+			drawPrompt(screen, tr("Delete %s?", saveSlotName(m.deleting-1)), tr("Y/Enter: delete   N/Esc: keep"))
 		}
 
+	case StateLoading:
+		m.loading.Draw(screen)
+
 	case StateGame:
 		if m.game != nil {
 			m.game.Draw(screen)
@@ -721,8 +1195,24 @@ func (m *MainGame) Draw(screen *ebiten.Image) {
 	}
 }
 
+// screenSize is the size of the screen as last laid out, or the selected
+// resolution before the first layout. The window can be dragged to any size,
+// so Auto tile sizes fit it rather than the resolution picked.
+func (m *MainGame) screenSize() (int, int) {
+	if m.bounds.Empty() {
+		return m.settings.ScreenWidth, m.settings.ScreenHeight
+	}
+	return m.bounds.Dx(), m.bounds.Dy()
+}
+
+// Layout uses the window's size as the screen, so the menu and the HUD
+// reflow when it is resized
 func (m *MainGame) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return m.settings.ScreenWidth, m.settings.ScreenHeight
+	m.bounds = image.Rect(0, 0, outsideWidth, outsideHeight)
+	if m.game != nil {
+		return m.game.Layout(outsideWidth, outsideHeight)
+	}
+	return outsideWidth, outsideHeight
 }
 
 func Contains(s, substr string) bool {
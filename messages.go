@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+// MessageCategory says what a message is about, so the UI can route it:
+// dialogue goes to the dialogue box, everything else to the toast list.
+type MessageCategory int
+
+const (
+	MsgSystem   MessageCategory = iota // Game state: floors, alarms, saving
+	MsgCombat                          // Blows exchanged with monsters
+	MsgLoot                            // Treasure, items and gold
+	MsgDialogue                        // Someone speaking: shrines, merchants, ghosts
+	MsgTravel                          // Floors, exits and stairs
+)
+
+func (c MessageCategory) String() string {
+	switch c {
+	case MsgCombat:
+		return "combat"
+	case MsgLoot:
+		return "loot"
+	case MsgDialogue:
+		return "dialogue"
+	case MsgTravel:
+		return "travel"
+	default:
+		return "system"
+	}
+}
+
+// Color is the text color of messages in this category
+func (c MessageCategory) Color() color.RGBA {
+	switch c {
+	case MsgCombat:
+		return color.RGBA{240, 130, 120, 255}
+	case MsgLoot:
+		return color.RGBA{250, 210, 90, 255}
+	case MsgDialogue:
+		return color.RGBA{215, 195, 255, 255}
+	case MsgTravel:
+		return color.RGBA{140, 195, 255, 255}
+	default:
+		return color.RGBA{215, 215, 215, 255}
+	}
+}
+
+// Severity is how much a message matters to the player
+type Severity int
+
+const (
+	SeverityInfo    Severity = iota
+	SeverityGood             // Healing, rewards, level ups
+	SeverityWarning          // Something to watch out for
+	SeverityDanger           // The player got hurt
+)
+
+// Color is the accent the UI marks messages of this severity with
+func (s Severity) Color() color.RGBA {
+	switch s {
+	case SeverityGood:
+		return color.RGBA{90, 220, 110, 255}
+	case SeverityWarning:
+		return color.RGBA{240, 190, 60, 255}
+	case SeverityDanger:
+		return color.RGBA{230, 60, 60, 255}
+	default:
+		return color.RGBA{170, 170, 170, 255}
+	}
+}
+
+// logCategory maps a combat log kind onto the message category it shows as
+func logCategory(kind LogKind) MessageCategory {
+	switch kind {
+	case LogCombat:
+		return MsgCombat
+	case LogPickup:
+		return MsgLoot
+	case LogFloor:
+		return MsgTravel
+	default:
+		return MsgSystem
+	}
+}
+
+// healthSeverity rates a message by what it did to the player's health
+func healthSeverity(healthChange int) Severity {
+	switch {
+	case healthChange < 0:
+		return SeverityDanger
+	case healthChange > 0:
+		return SeverityGood
+	default:
+		return SeverityInfo
+	}
+}
+
+// MessagesIn returns the active messages of the given categories, oldest first
+func (h *InteractionHandler) MessagesIn(categories ...MessageCategory) []TimedMessage {
+	var out []TimedMessage
+	for _, msg := range h.GetActiveMessages() {
+		for _, c := range categories {
+			if msg.Category == c {
+				out = append(out, msg)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// drawDialogue shows what shrines, merchants and other speakers said last
+// in a box along the bottom of the screen
+func (g *Game) drawDialogue(screen *ebiten.Image) {
+	lines := g.interactionHandler.MessagesIn(MsgDialogue)
+	if len(lines) == 0 {
+		return
+	}
+	if len(lines) > 3 {
+		lines = lines[len(lines)-3:]
+	}
+	panelW, panelH := 460, 12+16*len(lines)
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := screen.Bounds().Dy() - panelH - 20
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+	for i, msg := range lines {
+		drawMessage(screen, msg, panelX+8, panelY+6+16*i, MsgDialogue.Color())
+	}
+}
+
+// drawSeverityMark puts a small accent bar in front of a toast
+func drawSeverityMark(screen *ebiten.Image, x, y int, s Severity) {
+	vector.DrawFilledRect(screen, float32(x), float32(y), 3, 14, s.Color(), false)
+}
+
+// messageIconSize is the width of the icon in front of a message, gap included
+const messageIconSize = 16
+
+// drawMessage draws a message's category icon followed by its text in clr,
+// with (x, y) the top left of the line
+func drawMessage(screen *ebiten.Image, msg TimedMessage, x, y int, clr color.Color) {
+	drawMessageIcon(screen, float32(x), float32(y+1), msg.Category)
+	ui.Text.Draw(screen, msg.Text, x+messageIconSize, y, ui.TextStyle{Color: clr, Shadow: true})
+}
+
+// drawMessageIcon draws a 12 pixel icon for a message category with its top
+// left at (x, y): a sword for combat, a coin for loot, stairs for travel, a
+// speech bubble for dialogue and an exclamation mark for the rest
+func drawMessageIcon(screen *ebiten.Image, x, y float32, c MessageCategory) {
+	clr := c.Color()
+	switch c {
+	case MsgCombat:
+		vector.StrokeLine(screen, x+2, y+11, x+11, y+2, 2, clr, true)                         // Blade
+		vector.StrokeLine(screen, x+2, y+6, x+7, y+11, 2, clr, true)                          // Guard
+		vector.StrokeLine(screen, x, y+13, x+3, y+10, 2, color.RGBA{150, 110, 70, 255}, true) // Grip
+	case MsgLoot:
+		vector.DrawFilledCircle(screen, x+6, y+6, 6, clr, true)
+		vector.StrokeCircle(screen, x+6, y+6, 3.5, 1, color.RGBA{180, 130, 30, 255}, true)
+	case MsgTravel:
+		for step := float32(0); step < 3; step++ {
+			vector.StrokeLine(screen, x+step*4, y+4+step*4, x+step*4+4, y+4+step*4, 1.5, clr, true)
+			vector.StrokeLine(screen, x+step*4, y+step*4, x+step*4, y+4+step*4, 1.5, clr, true)
+		}
+	case MsgDialogue:
+		vector.StrokeRect(screen, x, y, 12, 8, 1.5, clr, true)
+		vector.StrokeLine(screen, x+3, y+8, x+2, y+12, 1.5, clr, true)
+		vector.StrokeLine(screen, x+2, y+12, x+6, y+8, 1.5, clr, true)
+	default:
+		vector.DrawFilledRect(screen, x+5, y, 2.5, 8, clr, true)
+		vector.DrawFilledRect(screen, x+5, y+10, 2.5, 2.5, clr, true)
+	}
+}
+
+const (
+	messageHistorySize     = 200 // Oldest messages are dropped past this
+	messageHistoryPageSize = 16  // Messages visible in the history panel at once
+)
+
+// MessageHistory keeps the toasts after they fade, so a missed message can
+// still be read in the message log panel
+type MessageHistory struct {
+	Messages []TimedMessage
+	Scroll   int // Messages scrolled back from the newest
+}
+
+func NewMessageHistory() *MessageHistory {
+	return &MessageHistory{Messages: make([]TimedMessage, 0, messageHistorySize)}
+}
+
+// Add appends a message, keeping the view pinned if the player scrolled back
+func (h *MessageHistory) Add(msg TimedMessage) {
+	h.Messages = append(h.Messages, msg)
+	if len(h.Messages) > messageHistorySize {
+		h.Messages = h.Messages[len(h.Messages)-messageHistorySize:]
+	} else if h.Scroll > 0 {
+		h.Scroll++
+	}
+}
+
+// ScrollBy moves the view back (positive) or forward (negative) in time
+func (h *MessageHistory) ScrollBy(n int) {
+	h.Scroll = max(0, min(h.Scroll+n, len(h.Messages)-messageHistoryPageSize))
+}
+
+// Page returns the messages currently in view, oldest first
+func (h *MessageHistory) Page() []TimedMessage {
+	end := len(h.Messages) - h.Scroll
+	return h.Messages[max(0, end-messageHistoryPageSize):end]
+}
+
+// drawMessageHistory draws the scrollable message log in the bottom right corner
+func (g *Game) drawMessageHistory(screen *ebiten.Image) {
+	history := g.interactionHandler.History
+	panelW, panelH := min(460, screen.Bounds().Dx()-20), 30+16*messageHistoryPageSize
+	panelX, panelY := screen.Bounds().Dx()-panelW-10, screen.Bounds().Dy()-panelH-10
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	title := tr("Messages %d/%d (%s: close, wheel/PgUp/PgDn: scroll)", len(history.Messages), messageHistorySize, bindings.Key(ActionMessages))
+	if history.Scroll > 0 {
+		title += fmt.Sprintf(" -%d", history.Scroll)
+	}
+	ui.Text.Draw(screen, title, panelX+6, panelY+4, ui.TextStyle{Bold: true})
+	for i, msg := range history.Page() {
+		y := panelY + 24 + 16*i
+		secs := msg.CreatedAt / ticksPerSecond
+		drawSeverityMark(screen, panelX+4, y, msg.Severity)
+		ui.Text.Draw(screen, fmt.Sprintf("[%02d:%02d]", secs/60, secs%60), panelX+10, y, ui.TextStyle{Color: color.RGBA{150, 150, 160, 255}})
+		drawMessage(screen, msg, panelX+58, y, msg.Category.Color())
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+const (
+	wanderChance        = 50 // Percent chance an idle monster wanders when it may move
+	monsterHealthPerLvl = 5  // Added to the species' base health per level
+	monsterDamagePerLvl = 2  // Added to the species' base damage per level
+)
+
+// monsterDirs are the orthogonal steps a monster can take
+var monsterDirs = []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// MonsterEntity is a monster living on the map. The cell at X, Y mirrors it:
+// it has Type Monster and carries the monster's level and flags, so moving a
+// monster moves its cell along with it.
+type MonsterEntity struct {
+	X, Y      int
+	Health    int
+	MaxHealth int
+	AI        MonsterAI
+	Hunter    bool           // Sent by the alarm; tracks the player anywhere while it rings
+	Boss      *BossState     // Set for bosses, nil for ordinary monsters
+	Master    *MonsterEntity // The summoner that called this minion, if any
+	Guarding  *Point         // The chest this guardian protects, if any
+	summon    int            // World turns until a summoner calls its next minion
+	ability   int            // World turns until its special ability is ready
+	cooldown  int            // World turns until the monster may step again
+}
+
+// addMonster registers a monster entity for the Monster cell at x, y
+func (d *Dungeon) addMonster(x, y int) *MonsterEntity {
+	m := &MonsterEntity{X: x, Y: y}
+	m.resetHealth(d.Cells[y][x])
+	d.Monsters = append(d.Monsters, m)
+	return m
+}
+
+// resetHealth gives the monster full health for the species and level of its cell
+func (m *MonsterEntity) resetHealth(cell Cell) {
+	m.MaxHealth = cell.Species.Info().Health + monsterHealthPerLvl*cell.InteractionLevel
+	if cell.Elite == AffixArmored {
+		m.MaxHealth += m.MaxHealth * eliteHealthPercent / 100
+	}
+	if m.Boss != nil {
+		m.MaxHealth *= bossHealthMultiplier
+	}
+	m.Health = m.MaxHealth
+}
+
+// MonsterAt returns the monster standing at x, y, or nil
+func (d *Dungeon) MonsterAt(x, y int) *MonsterEntity {
+	for _, m := range d.Monsters {
+		if m.X == x && m.Y == y {
+			return m
+		}
+	}
+	return nil
+}
+
+// RemoveMonsterAt removes the monster at x, y and clears its cell
+func (d *Dungeon) RemoveMonsterAt(x, y int) {
+	for i, m := range d.Monsters {
+		if m.X == x && m.Y == y {
+			d.Monsters = append(d.Monsters[:i], d.Monsters[i+1:]...)
+			break
+		}
+	}
+	d.Cells[y][x] = Cell{Type: Empty}
+}
+
+// moveMonster steps a monster and its cell to an adjacent tile
+func (d *Dungeon) moveMonster(m *MonsterEntity, to Point) {
+	d.Cells[to.y][to.x] = d.Cells[m.Y][m.X]
+	d.Cells[m.Y][m.X] = Cell{Type: Empty}
+	m.X, m.Y = to.x, to.y
+}
+
+// canMonsterEnter reports whether a monster may step onto p. Monsters only
+// walk on bare floor and never onto the player or their companion.
+func (d *Dungeon) canMonsterEnter(p Point, player *Player) bool {
+	return inBounds(p.x, p.y, d.Width, d.Height) &&
+		d.Cells[p.y][p.x].Type == Empty &&
+		!(p.x == player.X && p.y == player.Y) &&
+		!player.Companion.Occupies(p)
+}
+
+// isAdjacent reports whether two tiles touch orthogonally
+func isAdjacent(ax, ay, bx, by int) bool {
+	return abs(ax-bx)+abs(ay-by) == 1
+}
+
+// updateMonsters runs one world turn of monster AI. Each monster updates its
+// state, attacks the player if it is chasing them and adjacent, and otherwise
+// moves according to its state.
+func (g *Game) updateMonsters() {
+	d, p := g.dungeon, g.player
+
+	// Iterate over a copy, the loop may add monsters to the list
+	monsters := append([]*MonsterEntity(nil), d.Monsters...)
+	noises := d.takeNoises()
+	for _, m := range monsters {
+		if m.Health <= 0 {
+			continue // Killed earlier this turn by a rival
+		}
+		m.think(d, p, noises)
+		if m.Boss != nil {
+			g.updateBoss(m)
+		}
+		g.updateSummoner(m)
+
+		if m.cooldown > 0 {
+			m.cooldown--
+			continue
+		}
+		m.cooldown = d.Cells[m.Y][m.X].MoveInterval() - 1
+
+		if m.AI.State == AIChase && isAdjacent(m.X, m.Y, p.X, p.Y) {
+			g.monsterAttack(m)
+			continue
+		}
+		if c := p.Companion; m.AI.State == AIChase && c != nil && !c.Downed && isAdjacent(m.X, m.Y, c.X, c.Y) {
+			g.monsterAttackCompanion(m)
+			continue
+		}
+		if rival := d.adjacentRival(m); rival != nil {
+			g.monsterClash(m, rival)
+			continue
+		}
+		if g.useAbility(m) {
+			continue
+		}
+		if m.AI.State == AIChase && g.canShoot(m) {
+			g.monsterShoot(m)
+			continue
+		}
+		m.act(d, p, g.rng.Stream(StreamAI))
+	}
+}
+
+// monsterAttack lets a monster next to the player land a blow. The player can
+// fight back by bumping into it or retreat and try to outrun it.
+func (g *Game) monsterAttack(m *MonsterEntity) {
+	cell := g.dungeon.Cells[m.Y][m.X]
+	fight := NewMonsterInteraction(cell, m)
+	damage := fight.Blow(g.player)
+	g.stopTravel()
+	g.damagePlayer(fight, damage)
+	g.dungeon.MakeNoise(Point{g.player.X, g.player.Y}, noiseCombat)
+	g.interactionHandler.Record(LogCombat,
+		tr("A level %d %s attacks you for %d %s damage!", cell.InteractionLevel, cell.MonsterName(),
+			damage, cell.Species.Info().Attack.LocalName()), -damage)
+
+	switch {
+	case m.Boss != nil:
+		g.knockPlayer(m, bossKnockback)
+	case heavyHit(damage, g.player.MaxHealth):
+		g.knockPlayer(m, knockbackDistance)
+	}
+}
+
+// canShoot reports whether a ranged monster has the player in range and sight
+func (g *Game) canShoot(m *MonsterEntity) bool {
+	reach := g.dungeon.Cells[m.Y][m.X].Species.Info().Range
+	return reach > 0 && g.dungeon.CanSee(Point{m.X, m.Y}, Point{g.player.X, g.player.Y}, reach)
+}
+
+// monsterShoot lets a ranged monster hit the player from a distance. The
+// player has to close in or duck out of sight to stop it.
+func (g *Game) monsterShoot(m *MonsterEntity) {
+	cell := g.dungeon.Cells[m.Y][m.X]
+	species := cell.Species.Info()
+	fight := NewMonsterInteraction(cell, m)
+	damage := fight.Blow(g.player)
+	g.stopTravel()
+	g.damagePlayer(fight, damage)
+	g.fireProjectile(Point{m.X, m.Y}, Point{g.player.X, g.player.Y}, species.Color)
+	g.interactionHandler.Record(LogCombat,
+		tr("A level %d %s shoots you for %d %s damage!", cell.InteractionLevel, cell.MonsterName(), damage, species.Attack.LocalName()), -damage)
+	if species.Ability == AbilityPull {
+		g.pullPlayer(m)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+const (
+	noiseCombat      = 6  // Trading blows with a monster
+	noiseTrap        = 5  // A trap going off
+	noiseLockedChest = 10 // Breaking a lock open
+)
+
+// Noise is a sound made this world turn. Monsters within Radius tiles that
+// can't see the player go to investigate it.
+type Noise struct {
+	At     Point
+	Radius int
+}
+
+// Impact is a blow heavy enough to shake the screen, such as a trap going off
+// or something slammed into a wall
+type Impact struct {
+	At       Point
+	Strength float64 // Pixels the screen shakes by at the most
+}
+
+const (
+	trapImpact = 5
+	slamImpact = 3
+)
+
+// Impact records a heavy blow for the effects to pick up
+func (d *Dungeon) Impact(at Point, strength float64) {
+	d.Impacts = append(d.Impacts, Impact{At: at, Strength: strength})
+}
+
+// takeImpacts returns the blows since the last call and clears them
+func (d *Dungeon) takeImpacts() []Impact {
+	impacts := d.Impacts
+	d.Impacts = nil
+	return impacts
+}
+
+// MakeNoise lets the floor's monsters hear a sound at the given tile on the
+// next world turn
+func (d *Dungeon) MakeNoise(at Point, radius int) {
+	d.Noises = append(d.Noises, Noise{At: at, Radius: radius})
+}
+
+// takeNoises returns the pending noises and clears them, so each noise is
+// heard on exactly one world turn
+func (d *Dungeon) takeNoises() []Noise {
+	noises := d.Noises
+	d.Noises = nil
+	return noises
+}
+
+// hears returns the closest noise in earshot of the monster
+func (m *MonsterEntity) hears(noises []Noise) (Point, bool) {
+	best, bestDist := Point{}, -1
+	for _, n := range noises {
+		dx, dy := n.At.x-m.X, n.At.y-m.Y
+		dist := dx*dx + dy*dy
+		if dist <= n.Radius*n.Radius && (bestDist < 0 || dist < bestDist) {
+			best, bestDist = n.At, dist
+		}
+	}
+	return best, bestDist >= 0
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+// drawPanel draws the translucent box used behind in-game panels
+func drawPanel(screen *ebiten.Image, x, y, w, h int) {
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h),
+		color.RGBA{0, 0, 0, 200}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h),
+		1, color.RGBA{200, 200, 220, 255}, false)
+}
+
+// drawDisarm draws the trap disarm timing bar
+func (g *Game) drawDisarm(screen *ebiten.Image) {
+	panelW, panelH := 360, 80
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := screen.Bounds().Dy()/2 - panelH/2
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	ui.DrawText(screen, tr("Disarm: SPACE inside the green zone (ESC: back off)"), panelX+6, panelY+4)
+
+	barX, barY, barW, barH := float32(panelX+20), float32(panelY+36), float32(panelW-40), float32(20)
+	vector.DrawFilledRect(screen, barX, barY, barW, barH, color.RGBA{60, 60, 70, 255}, false)
+	vector.DrawFilledRect(screen, barX+barW*float32(g.disarm.ZoneStart), barY,
+		barW*float32(g.disarm.ZoneWidth), barH, color.RGBA{40, 180, 60, 255}, false)
+	markerX := barX + barW*float32(g.disarm.Marker)
+	vector.DrawFilledRect(screen, markerX-2, barY-4, 4, barH+8, color.RGBA{255, 255, 255, 255}, false)
+}
+
+// drawGameOver draws the death or victory screen over the dungeon, with the run summary
+func (g *Game) drawGameOver(screen *ebiten.Image) {
+	title, lines := g.gameOverText()
+	panelW, panelH := 320, 30+16*len(lines)+gameOverButtonH+10
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := screen.Bounds().Dy()/2 - panelH/2
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	ui.DrawText(screen, title, panelX+6, panelY+4)
+	for i, line := range lines {
+		ui.DrawText(screen, line, panelX+6, panelY+24+16*i)
+	}
+
+	button := gameOverButton(screen.Bounds().Dx(), screen.Bounds().Dy(), len(lines))
+	fill := color.RGBA{50, 50, 60, 255}
+	if mouse := input.Cursor; mouse.In(button) {
+		fill = color.RGBA{100, 100, 200, 255}
+	}
+	vector.DrawFilledRect(screen, float32(button.Min.X), float32(button.Min.Y), float32(button.Dx()), float32(button.Dy()), fill, false)
+	vector.StrokeRect(screen, float32(button.Min.X), float32(button.Min.Y), float32(button.Dx()), float32(button.Dy()), 1, color.RGBA{200, 200, 220, 255}, false)
+	ui.DrawText(screen, tr("Copy Seed (C)"), button.Min.X+10, button.Min.Y+2)
+}
+
+const (
+	gameOverButtonW = 120
+	gameOverButtonH = 20
+)
+
+// gameOverButton returns where the copy seed button sits below the text of
+// a game over panel with the given number of lines
+func gameOverButton(screenW, screenH, lines int) image.Rectangle {
+	panelH := 30 + 16*lines + gameOverButtonH + 10
+	x := screenW/2 - gameOverButtonW/2
+	y := screenH/2 - panelH/2 + 24 + 16*lines + 4
+	return image.Rect(x, y, x+gameOverButtonW, y+gameOverButtonH)
+}
+
+// gameOverText returns the title and lines of the game over panel
+func (g *Game) gameOverText() (string, []string) {
+	title := tr("GAME OVER")
+	lines := []string{
+		tr("%s has fallen on dungeon level %d.", g.player.Name, g.dungeon.Level),
+		tr("Final score: %d", g.player.Score),
+	}
+	if g.won {
+		title = tr("VICTORY")
+		lines[0] = tr("%s escaped the dungeon!", g.player.Name)
+	}
+	if g.arena != nil {
+		lines[0] = tr("%s has fallen in the arena on wave %d.", g.player.Name, g.arena.Wave)
+		lines = append(lines, tr("Survived %s", g.arena.SurvivalTime(g.clock.Ticks)))
+	}
+	lines = append(lines, "")
+	lines = append(lines, g.runSummary()...)
+	if g.highScoreRank > 0 {
+		lines = append(lines, tr("New high score: #%d!", g.highScoreRank))
+	}
+	lines = append(lines, "", tr("Seed: %d", g.runSeed), tr("Press %s to play again, %s for the menu", bindings.Key(ActionRetry), bindings.Key(ActionQuitToMenu)))
+	if g.casualMode && !g.won && g.arena == nil {
+		lines = append(lines, tr("(Casual: the next run revisits this dungeon)"))
+	}
+	return title, lines
+}
+
+// drawCharacterSheet draws the player's stats and artifact traits in the middle of the screen
+func (g *Game) drawCharacterSheet(screen *ebiten.Image) {
+	p := g.player
+	lines := []string{
+		tr("%s - Level %d (XP %d)", p.Name, p.Level, p.Experience),
+		tr("Health: %d/%d", p.Health, p.MaxHealth),
+		tr("Defense: %d  Luck: %d", p.EffectiveDefense(), p.EffectiveLuck()),
+		tr("Attack: %d %s", p.AttackDamage(), p.AttackType().LocalName()),
+		tr("Light radius: %d (lantern level %d)", p.EffectiveFOVRadius(g.dungeon), p.LanternLevel),
+		tr("Carry weight: %d/%d", p.CarryWeight(), p.CarryLimit()),
+		companionLine(p.Companion),
+		"",
+		tr("Traits:"),
+	}
+
+	hasTraits := false
+	for _, t := range artifactTraits {
+		if count := p.TraitCount(t); count > 0 {
+			lines = append(lines, tr("  %s x%d - %s", tr(t.String()), count, t.Description()))
+			hasTraits = true
+		}
+	}
+	if !hasTraits {
+		lines = append(lines, tr("  (none - find artifacts to gain traits)"))
+	}
+
+	panelW, panelH := 340, 30+16*len(lines)
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := screen.Bounds().Dy()/2 - panelH/2
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	ui.DrawText(screen, tr("Character Sheet (%s: close)", bindings.Key(ActionCharacter)), panelX+6, panelY+4)
+	for i, line := range lines {
+		ui.DrawText(screen, line, panelX+6, panelY+24+16*i)
+	}
+}
+
+// drawQuestLog lists accepted quests and their progress, newest first
+func (g *Game) drawQuestLog(screen *ebiten.Image) {
+	var lines []string
+	for i := len(g.quests.Quests) - 1; i >= 0; i-- {
+		lines = append(lines, g.quests.Quests[i].ProgressLine())
+	}
+	if len(lines) == 0 {
+		lines = append(lines, tr("(no quests - look for a shrine)"))
+	}
+
+	panelW, panelH := 460, 30+16*len(lines)
+	panelX := screen.Bounds().Dx()/2 - panelW/2
+	panelY := screen.Bounds().Dy()/2 - panelH/2
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	ui.DrawText(screen, tr("Quest Log (%s: close)", bindings.Key(ActionQuestLog)), panelX+6, panelY+4)
+	for i, line := range lines {
+		ui.DrawText(screen, line, panelX+6, panelY+24+16*i)
+	}
+}
+
+// drawCombatLog draws the scrollable combat log along the bottom of the screen
+func (g *Game) drawCombatLog(screen *ebiten.Image) {
+	log := g.interactionHandler.Log
+	panelW, panelH := min(560, screen.Bounds().Dx()-20), 30+16*combatLogPageSize
+	panelX, panelY := 10, screen.Bounds().Dy()-panelH-10
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	title := tr("Combat Log (%s: close, wheel/PgUp/PgDn: scroll)", bindings.Key(ActionCombatLog))
+	if log.Scroll > 0 {
+		title += fmt.Sprintf(" -%d", log.Scroll)
+	}
+	ui.DrawText(screen, title, panelX+6, panelY+4)
+	for i, entry := range log.Page() {
+		ui.DrawText(screen, entry.String(), panelX+6, panelY+24+16*i)
+	}
+}
+
+// drawBossBar draws the health bar of the boss being fought at the top of the screen
+func drawBossBar(screen *ebiten.Image, boss *MonsterEntity) {
+	barW, barH := float32(320), float32(14)
+	barX, barY := float32(screen.Bounds().Dx())/2-barW/2, float32(44)
+	fill := barW * float32(max(0, boss.Health)) / float32(boss.MaxHealth)
+
+	vector.DrawFilledRect(screen, barX, barY, barW, barH, color.RGBA{40, 0, 0, 220}, false)
+	vector.DrawFilledRect(screen, barX, barY, fill, barH, color.RGBA{200, 30, 30, 255}, false)
+	vector.StrokeRect(screen, barX, barY, barW, barH, 1, color.RGBA{230, 200, 120, 255}, false)
+	ui.DrawText(screen, fmt.Sprintf("%s - %s (%d/%d)",
+		boss.Boss.Name, tr(boss.Boss.CurrentPhase().Name), boss.Health, boss.MaxHealth), int(barX)+4, int(barY)-1)
+}
+
+// companionLine describes the player's companion for the character sheet
+func companionLine(c *Companion) string {
+	switch {
+	case c == nil:
+		return tr("Companion: none")
+	case c.Downed:
+		return tr("Companion: %s (downed)", c.Kind.LocalName())
+	default:
+		return tr("Companion: %s %d/%d HP", c.Kind.LocalName(), c.Health, c.MaxHealth)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// PanelKind is one of the panels and menus that open over the dungeon
+type PanelKind int
+
+const (
+	PanelCharacter PanelKind = iota
+	PanelQuests
+	PanelCombatLog
+	PanelMessages
+	PanelInventory
+	PanelShop
+	PanelConfirm
+	PanelPause
+	PanelHelp
+)
+
+// modal reports whether the panel takes all input while it is on top, with
+// the world waiting until it closes. The others sit over the dungeon while
+// the game goes on.
+func (k PanelKind) modal() bool {
+	return k >= PanelInventory
+}
+
+// PanelStack is the open panels in the order they were opened, the last on
+// top. Escape closes the top one; with none open it pauses the game.
+type PanelStack []PanelKind
+
+// Open puts a panel on top, moving it there if it is already open
+func (s *PanelStack) Open(k PanelKind) {
+	s.Close(k)
+	*s = append(*s, k)
+}
+
+// Close takes a panel off the stack wherever it is
+func (s *PanelStack) Close(k PanelKind) {
+	*s = slices.DeleteFunc(*s, func(open PanelKind) bool { return open == k })
+}
+
+// IsOpen reports whether a panel is open
+func (s PanelStack) IsOpen(k PanelKind) bool {
+	return slices.Contains(s, k)
+}
+
+// Top returns the panel on top, if any is open
+func (s PanelStack) Top() (PanelKind, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	return s[len(s)-1], true
+}
+
+// Modal reports whether a modal panel is open, which keeps the world waiting
+func (s PanelStack) Modal() bool {
+	return slices.ContainsFunc(s, PanelKind.modal)
+}
+
+// openPanel opens a panel that has no state of its own beyond being open
+func (g *Game) openPanel(k PanelKind) {
+	switch k {
+	case PanelInventory:
+		g.inventory = &InventoryMenu{}
+	case PanelPause:
+		g.pause = &PauseMenu{}
+	}
+	g.panels.Open(k)
+}
+
+// closePanel closes a panel and drops the state it kept while open
+func (g *Game) closePanel(k PanelKind) {
+	switch k {
+	case PanelInventory:
+		g.inventory = nil
+	case PanelShop:
+		g.shop = nil
+	case PanelConfirm:
+		g.confirm = nil
+	case PanelPause:
+		g.pause = nil
+	}
+	g.panels.Close(k)
+}
+
+// togglePanel opens a panel, or closes it if it is already open
+func (g *Game) togglePanel(k PanelKind) {
+	if g.panels.IsOpen(k) {
+		g.closePanel(k)
+	} else {
+		g.openPanel(k)
+	}
+}
+
+// updatePanel hands the input to the modal panel on top
+func (g *Game) updatePanel(k PanelKind) {
+	switch k {
+	case PanelInventory:
+		g.updateInventory()
+	case PanelShop:
+		g.updateShop()
+	case PanelConfirm:
+		g.updateConfirm()
+	case PanelPause:
+		g.updatePause()
+	case PanelHelp:
+		g.updateHelp()
+	}
+}
+
+// drawOpenPanel draws one open panel
+func (g *Game) drawOpenPanel(screen *ebiten.Image, k PanelKind) {
+	switch k {
+	case PanelCharacter:
+		g.drawCharacterSheet(screen)
+	case PanelQuests:
+		g.drawQuestLog(screen)
+	case PanelCombatLog:
+		g.drawCombatLog(screen)
+	case PanelMessages:
+		g.drawMessageHistory(screen)
+	case PanelInventory:
+		g.drawInventory(screen)
+	case PanelShop:
+		g.drawShop(screen)
+	case PanelConfirm:
+		g.drawConfirm(screen)
+	case PanelPause:
+		g.drawPause(screen)
+	case PanelHelp:
+		g.drawHelp(screen)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import "container/heap"
+
+const (
+	baseStepCost      = 1  // Cost of entering an open tile; walls are never entered
+	revealedTrapCost  = 10 // Extra cost of walking over a known trap
+	monsterDangerCost = 6  // Extra cost of a tile next to a monster
+)
+
+// pathBuffers are FindPath's grids, kept on the dungeon so a search reuses
+// them instead of allocating. Each holds one entry per tile, row by row.
+type pathBuffers struct {
+	cost   []int     // Cheapest cost found to each tile, -1 if not reached
+	prev   []Point   // The tile each one was reached from
+	danger []int     // How many monsters stand next to each tile
+	open   pathQueue // A* open set
+}
+
+// grow makes sure the buffers hold n tiles
+func (b *pathBuffers) grow(n int) {
+	if len(b.cost) < n {
+		b.cost = make([]int, n)
+		b.prev = make([]Point, n)
+		b.danger = make([]int, n)
+	}
+}
+
+// FindPath finds the cheapest path from start to goal with A*. Tiles cost more
+// when they hold known traps or lie next to monsters, so routes steer around
+// danger when a safe detour exists. A monster standing at start is the one
+// walking, and isn't counted as danger to itself.
+func (d *Dungeon) FindPath(start, goal Point) []Point {
+	if !inBounds(goal.x, goal.y, d.Width, d.Height) {
+		return nil
+	}
+	danger := d.dangerMap(d.MonsterAt(start.x, start.y))
+
+	n := d.Width * d.Height
+	cost, prev := d.paths.cost[:n], d.paths.prev[:n]
+	for i := range cost {
+		cost[i] = -1
+	}
+	at := func(p Point) int { return p.y*d.Width + p.x }
+
+	open := &d.paths.open
+	*open = append((*open)[:0], pathNode{pos: start, priority: manhattan(start, goal)})
+	cost[at(start)] = 0
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathNode).pos
+		if current == goal {
+			break
+		}
+
+		for _, dir := range monsterDirs {
+			next := Point{current.x + dir.x, current.y + dir.y}
+			if !inBounds(next.x, next.y, d.Width, d.Height) || d.Cells[next.y][next.x].Type == Wall {
+				continue
+			}
+			newCost := cost[at(current)] + d.stepCost(next, danger)
+			if old := cost[at(next)]; old >= 0 && old <= newCost {
+				continue
+			}
+			cost[at(next)] = newCost
+			prev[at(next)] = current
+			heap.Push(open, pathNode{pos: next, priority: newCost + manhattan(next, goal)})
+		}
+	}
+
+	if cost[at(goal)] < 0 {
+		return nil
+	}
+
+	// Reconstruct path
+	var path []Point
+	for p := goal; p != start; p = prev[at(p)] {
+		path = append([]Point{p}, path...)
+	}
+	return append([]Point{start}, path...)
+}
+
+// stepCost is the cost of entering p. Hidden traps cost the same as floor,
+// since nobody knows they are there.
+func (d *Dungeon) stepCost(p Point, danger []int) int {
+	cell := d.Cells[p.y][p.x]
+	cost := baseStepCost
+	if cell.Type == Trap && cell.Revealed {
+		cost += revealedTrapCost
+	}
+	if danger[p.y*d.Width+p.x] > 0 {
+		cost += monsterDangerCost
+	}
+	return cost
+}
+
+// PathCost adds up what walking a path costs, leaving out the tile it starts
+// from. risky counts the steps that cost extra for being near a monster or
+// over a known trap.
+func (d *Dungeon) PathCost(path [][2]int) (cost, risky int) {
+	danger := d.dangerMap(nil)
+	for _, p := range path {
+		step := d.stepCost(Point{p[0], p[1]}, danger)
+		cost += step
+		if step > baseStepCost {
+			risky++
+		}
+	}
+	return cost, risky
+}
+
+// dangerMap counts the monsters next to each tile, leaving out mover. It
+// fills the dungeon's path buffers, so it lasts until the next search.
+func (d *Dungeon) dangerMap(mover *MonsterEntity) []int {
+	d.paths.grow(d.Width * d.Height)
+	danger := d.paths.danger[:d.Width*d.Height]
+	clear(danger)
+	for _, m := range d.Monsters {
+		if m == mover {
+			continue
+		}
+		for _, dir := range monsterDirs {
+			if x, y := m.X+dir.x, m.Y+dir.y; inBounds(x, y, d.Width, d.Height) {
+				danger[y*d.Width+x]++
+			}
+		}
+	}
+	return danger
+}
+
+// manhattan is the A* heuristic; every step costs at least 1
+func manhattan(a, b Point) int {
+	return abs(a.x-b.x) + abs(a.y-b.y)
+}
+
+// pathNode is an entry of the A* open set
+type pathNode struct {
+	pos      Point
+	priority int
+}
+
+// pathQueue is a min-heap of path nodes ordered by priority
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)        { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
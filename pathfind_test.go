@@ -0,0 +1,170 @@
+package main
+
+import "testing"
+
+// pathFixture builds a dungeon from rows of '#' walls and '.' floors, with
+// '^' for known traps, 'm' for monsters, 'S' for the start and 'G' for the
+// goal
+func pathFixture(t *testing.T, rows []string) (d *Dungeon, start, goal Point) {
+	t.Helper()
+	d = &Dungeon{Width: len(rows[0]), Height: len(rows), Cells: make([][]Cell, len(rows))}
+	start, goal = Point{-1, -1}, Point{-1, -1}
+	for y, row := range rows {
+		d.Cells[y] = make([]Cell, len(row))
+		for x, c := range row {
+			switch c {
+			case '#':
+				d.Cells[y][x].Type = Wall
+			case '^':
+				d.Cells[y][x] = Cell{Type: Trap, Revealed: true}
+			case 'm':
+				d.Cells[y][x].Type = Monster
+				d.Monsters = append(d.Monsters, &MonsterEntity{X: x, Y: y})
+			case 'S':
+				start = Point{x, y}
+			case 'G':
+				goal = Point{x, y}
+			}
+		}
+	}
+	if start.x < 0 || goal.x < 0 {
+		t.Fatal("fixture needs an 'S' and a 'G'")
+	}
+	return d, start, goal
+}
+
+// steps is how many moves a path takes, -1 for no path
+func steps(path []Point) int {
+	return len(path) - 1
+}
+
+var pathCases = []struct {
+	name      string
+	rows      []string
+	goal      *Point // Overrides the fixture's 'G'
+	wantSteps int    // FindPath's steps, -1 for no path
+	wantBFS   int    // FindPathBFS's steps, -1 for no path
+}{
+	{
+		name: "open room",
+		rows: []string{
+			"#######",
+			"#S....#",
+			"#.....#",
+			"#....G#",
+			"#######",
+		},
+		wantSteps: 6,
+		wantBFS:   6,
+	},
+	{
+		name: "known trap detour",
+		rows: []string{
+			"#######",
+			"#S.^.G#",
+			"#.###.#",
+			"#.....#",
+			"#######",
+		},
+		wantSteps: 8,
+		wantBFS:   4,
+	},
+	{
+		name: "trap cheaper than a long detour",
+		rows: []string{
+			"##########",
+			"#S.^....G#",
+			"#.######.#",
+			"#.######.#",
+			"#.######.#",
+			"#.######.#",
+			"#.######.#",
+			"#........#",
+			"##########",
+		},
+		wantSteps: 7,
+		wantBFS:   7,
+	},
+	{
+		name: "monster detour",
+		rows: []string{
+			"#######",
+			"###m###",
+			"#S...G#",
+			"#.###.#",
+			"#.....#",
+			"#######",
+		},
+		wantSteps: 8,
+		wantBFS:   4,
+	},
+	{
+		name: "unreachable",
+		rows: []string{
+			"#######",
+			"#S.#.G#",
+			"#..#..#",
+			"#######",
+		},
+		wantSteps: -1,
+		wantBFS:   -1,
+	},
+	{
+		name: "out of bounds",
+		rows: []string{
+			"#####",
+			"#S.G#",
+			"#####",
+		},
+		goal:      &Point{9, 1},
+		wantSteps: -1,
+		wantBFS:   -1,
+	},
+}
+
+func TestFindPathAgainstBFS(t *testing.T) {
+	for _, tc := range pathCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, start, goal := pathFixture(t, tc.rows)
+			if tc.goal != nil {
+				goal = *tc.goal
+			}
+			path := d.FindPath(start, goal)
+			if got := steps(path); got != tc.wantSteps {
+				t.Errorf("FindPath took %d steps, want %d: %v", got, tc.wantSteps, path)
+			}
+			if got := steps(d.FindPathBFS(start, goal)); got != tc.wantBFS {
+				t.Errorf("FindPathBFS took %d steps, want %d", got, tc.wantBFS)
+			}
+			if path == nil {
+				return
+			}
+			if path[0] != start || path[len(path)-1] != goal {
+				t.Errorf("path runs %v to %v, want %v to %v", path[0], path[len(path)-1], start, goal)
+			}
+			for i := 1; i < len(path); i++ {
+				if manhattan(path[i-1], path[i]) != 1 || d.Cells[path[i].y][path[i].x].Type == Wall {
+					t.Fatalf("bad step %v -> %v", path[i-1], path[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDangerMapLeavesOutMover(t *testing.T) {
+	d, _, _ := pathFixture(t, []string{
+		"#######",
+		"#S.m.G#",
+		"#.....#",
+		"#m....#",
+		"#######",
+	})
+	mover := d.MonsterAt(3, 1)
+	danger := d.dangerMap(mover)
+	if n := danger[2*d.Width+3]; n != 0 {
+		t.Errorf("tile below the mover has danger %d, want 0", n)
+	}
+	if n := danger[2*d.Width+1]; n != 1 {
+		t.Errorf("tile above the other monster has danger %d, want 1", n)
+	}
+}
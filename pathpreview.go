@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Colors of the path preview: the route, the target tile it reaches, and
+// the tile it stops short on when something is in the way
+var (
+	pathArrowColor = color.RGBA{200, 200, 215, 255}
+	pathEndColor   = color.RGBA{255, 255, 255, 255}
+	pathStopColor  = color.RGBA{255, 170, 40, 255}
+)
+
+// drawPathPreview draws the path to the selected or hovered tile: a chevron
+// on each step pointing the way to the next, fading with distance, and a
+// ring on the tile the walk ends on
+func (g *Game) drawPathPreview(screen *ebiten.Image) {
+	path := g.pathToHover
+	if len(path) == 0 {
+		return
+	}
+	tile := float32(tileSize)
+	width := max(1.5, tile/10)
+	for i, p := range path[:len(path)-1] {
+		// Closer steps are drawn more strongly
+		clr := pathArrowColor
+		clr.A = uint8(230 - 130*i/len(path))
+		next := path[i+1]
+		drawChevron(screen, p, float32(next[0]-p[0]), float32(next[1]-p[1]), width, premultiply(clr))
+	}
+
+	// The destination gets a ring; a walk that stops short of the target
+	// tile ends on a differently colored one, so the stopping point is clear
+	last := path[len(path)-1]
+	end := pathEndColor
+	if target := g.pathTarget(); last != [2]int{target.x, target.y} {
+		end = pathStopColor
+	}
+	cx, cy := (float32(last[0])+0.5)*tile, (float32(last[1])+0.5)*tile
+	vector.StrokeCircle(screen, cx, cy, tile*0.3, width, end, true)
+	vector.DrawFilledCircle(screen, cx, cy, max(1, tile*0.08), end, true)
+}
+
+// drawChevron draws an arrowhead in the middle of tile p pointing along
+// (dx, dy), one step in any of the four directions
+func drawChevron(screen *ebiten.Image, p [2]int, dx, dy, width float32, clr color.RGBA) {
+	tile := float32(tileSize)
+	cx, cy := (float32(p[0])+0.5)*tile, (float32(p[1])+0.5)*tile
+	tipX, tipY := cx+dx*tile*0.2, cy+dy*tile*0.2
+	backX, backY := cx-dx*tile*0.15, cy-dy*tile*0.15
+	perpX, perpY := -dy*tile*0.22, dx*tile*0.22
+	vector.StrokeLine(screen, backX+perpX, backY+perpY, tipX, tipY, width, clr, true)
+	vector.StrokeLine(screen, backX-perpX, backY-perpY, tipX, tipY, width, clr, true)
+}
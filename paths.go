@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDir names the game's folder inside the OS config and data directories
+const appDir = "procedural-dungeon"
+
+// userDirs are where the game keeps the player's files: settings such as key
+// bindings and difficulty curves under the OS config directory, and saves,
+// scores and the lost satchel under its data directory. They stay empty,
+// meaning the working directory, until initUserDirs finds them.
+var userDirs struct {
+	Config string
+	Data   string
+}
+
+// configPath is where a settings file lives
+func configPath(name string) string {
+	return filepath.Join(userDirs.Config, name)
+}
+
+// dataPath is where a save, score or other record of play lives
+func dataPath(name string) string {
+	return filepath.Join(userDirs.Data, name)
+}
+
+// initUserDirs finds the config and data directories for this OS, creating
+// them on first run, and moves over any files an older version left in the
+// working directory. On error the working directory stays in use.
+func initUserDirs() error {
+	configBase, err := os.UserConfigDir()
+	if err != nil {
+		return err
+	}
+	dataBase, err := userDataDir()
+	if err != nil {
+		return err
+	}
+	config, data := filepath.Join(configBase, appDir), filepath.Join(dataBase, appDir)
+	for _, dir := range []string{config, data} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	userDirs.Config, userDirs.Data = config, data
+
+	moveLegacyFile(bindingsFile, configPath)
+	moveLegacyFile(difficultyFile, configPath)
+	moveLegacyFile(highScoresFile, dataPath)
+	moveLegacyFile(satchelFile, dataPath)
+	for slot := 0; slot < saveSlots+autosaveSlots; slot++ {
+		moveLegacyFile(saveSlotFile(slot), dataPath)
+	}
+	return nil
+}
+
+// userDataDir returns the OS directory for application data, the data
+// counterpart of os.UserConfigDir:
+//
+//   - On Unix, $XDG_DATA_HOME if set to an absolute path, else ~/.local/share
+//   - On Windows, %LocalAppData%
+//   - On Darwin and iOS, the same Application Support directory as config
+func userDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return dir, nil
+		}
+		return "", errors.New("%LocalAppData% is not defined")
+	case "darwin", "ios":
+		return os.UserConfigDir()
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// moveLegacyFile moves a file from the working directory, where older
+// versions kept everything, to its place in the user directories. A file
+// already there wins, and the old one is left alone.
+func moveLegacyFile(name string, path func(string) string) {
+	dest := path(name)
+	if _, err := os.Stat(name); err != nil {
+		return
+	}
+	if _, err := os.Stat(dest); !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err := os.Rename(name, dest); err != nil {
+		log.Printf("could not move %s to %s: %v", name, dest, err)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const pauseRowHeight = 24
+
+// PauseMenu is the overlay Escape opens during a run. While it is open it
+// takes all input and the world stands still.
+type PauseMenu struct {
+	Cursor   int
+	Settings bool           // Showing the in-run settings page instead of the main options
+	Slots    []*SaveSummary // Save slots shown instead of the main options; nil otherwise
+}
+
+// pauseSaveRow is the row of "Save Game" among the main options
+const pauseSaveRow = 3
+
+// pauseOptions are the entries of the main pause page
+var pauseOptions = []string{"Resume", "Settings", "Copy Seed", "Save Game", "Restart Run", "Quit to Menu"}
+
+// pauseRows returns the labels of the page currently shown, in the current language
+func (g *Game) pauseRows() []string {
+	if g.pause.Slots != nil {
+		rows := make([]string, 0, saveSlots+1)
+		for slot, s := range g.pause.Slots {
+			rows = append(rows, saveSlotLabel(slot, s))
+		}
+		return append(rows, tr("Back"))
+	}
+	if !g.pause.Settings {
+		rows := make([]string, len(pauseOptions))
+		for i, option := range pauseOptions {
+			rows[i] = tr(option)
+		}
+		return rows
+	}
+	return []string{
+		ui.ToggleLabel(tr("Field of View"), g.player.FOVEnabled),
+		ui.ToggleLabel(tr("Confirm Danger"), g.confirmDanger),
+		ui.ToggleLabel(tr("Interact Key (%s)", bindings.Key(ActionInteract)), g.interactKey),
+		ui.ToggleLabel(tr("Reduced Motion"), g.reducedMotion),
+		ui.ToggleLabel(tr("Sprite Tiles"), spriteTiles),
+		tr("Back"),
+	}
+}
+
+// pauseHeader is the height of the title and seed lines above the rows
+const pauseHeader = 46
+
+// pausePanel returns the rectangle the pause menu is drawn in, wide enough
+// for the longest row
+func pausePanel(screenW, screenH int, rows []string) (x, y, w, h int) {
+	w = 300
+	for _, row := range rows {
+		rowW, _ := ui.Text.Measure(row, ui.TextStyle{})
+		w = max(w, rowW+40)
+	}
+	h = pauseHeader + 10 + pauseRowHeight*len(rows)
+	return screenW/2 - w/2, screenH/2 - h/2, w, h
+}
+
+// updatePause handles input while the pause menu is open
+func (g *Game) updatePause() {
+	m := g.pause
+	if input.JustPressed(ActionCancel) {
+		if m.Slots != nil {
+			m.Slots, m.Cursor = nil, pauseSaveRow
+		} else if m.Settings {
+			m.Settings, m.Cursor = false, 1
+		} else {
+			g.closePanel(PanelPause)
+		}
+		return
+	}
+
+	labels := g.pauseRows()
+	rows := len(labels)
+	if input.Repeated(ActionMoveUp) {
+		m.Cursor--
+	}
+	if input.Repeated(ActionMoveDown) {
+		m.Cursor++
+	}
+
+	// Hovering a row selects it and clicking confirms
+	confirm := input.JustPressed(ActionConfirm)
+	mouseX, mouseY := input.Cursor.X, input.Cursor.Y
+	panelX, panelY, panelW, _ := pausePanel(g.screenW, g.screenH, labels)
+	if row := (mouseY - panelY - pauseHeader) / pauseRowHeight; mouseX >= panelX && mouseX < panelX+panelW &&
+		mouseY >= panelY+pauseHeader && row < rows {
+		m.Cursor = row
+		confirm = confirm || input.JustPressed(ActionClick)
+	}
+	m.Cursor = (m.Cursor + rows) % rows
+	if !confirm {
+		return
+	}
+
+	if m.Slots != nil {
+		g.pickSaveSlot(m.Cursor)
+		return
+	}
+	if m.Settings {
+		g.toggleSetting(m.Cursor)
+		return
+	}
+	switch pauseOptions[m.Cursor] {
+	case "Resume":
+		g.closePanel(PanelPause)
+	case "Settings":
+		m.Settings, m.Cursor = true, 0
+	case "Copy Seed":
+		g.copyRunSeed()
+	case "Save Game":
+		m.Slots, m.Cursor = loadSaveSummaries()[:saveSlots], 0
+	case "Restart Run":
+		g.restartRequested = true
+	case "Quit to Menu":
+		g.quitRequested = true
+	}
+}
+
+// pickSaveSlot saves to the slot on the given row of the save page, asking
+// first if it already holds a save
+func (g *Game) pickSaveSlot(row int) {
+	m := g.pause
+	if row >= len(m.Slots) {
+		m.Slots, m.Cursor = nil, pauseSaveRow
+		return
+	}
+	save := func() {
+		g.saveRun(row)
+		if g.pause != nil {
+			g.pause.Slots = loadSaveSummaries()[:saveSlots]
+		}
+	}
+	if m.Slots[row] == nil {
+		save()
+		return
+	}
+	g.confirm = &ConfirmPrompt{
+		Text:   tr("Overwrite slot %d?\n%s", row+1, saveSlotLabel(row, m.Slots[row])),
+		Action: save,
+	}
+	g.panels.Open(PanelConfirm)
+}
+
+// toggleSetting flips the in-run setting on the given row of the settings page
+func (g *Game) toggleSetting(row int) {
+	switch row {
+	case 0:
+		g.player.FOVEnabled = !g.player.FOVEnabled
+	case 1:
+		g.confirmDanger = !g.confirmDanger
+	case 2:
+		g.interactKey = !g.interactKey
+	case 3:
+		g.reducedMotion = !g.reducedMotion
+		g.effects.enabled = !g.reducedMotion
+		g.camera.Still = g.reducedMotion
+	case 4:
+		spriteTiles = !spriteTiles
+	default:
+		g.pause.Settings, g.pause.Cursor = false, 1
+	}
+}
+
+// drawPause draws the pause menu over the dungeon
+func (g *Game) drawPause(screen *ebiten.Image) {
+	rows := g.pauseRows()
+	panelX, panelY, panelW, panelH := pausePanel(screen.Bounds().Dx(), screen.Bounds().Dy(), rows)
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	title := tr("Paused (Esc: resume)")
+	switch {
+	case g.pause.Slots != nil:
+		title = tr("Save Game (Esc: back)")
+	case g.pause.Settings:
+		title = tr("Settings (Esc: back)")
+	}
+	ui.DrawText(screen, title, panelX+6, panelY+6)
+	ui.DrawText(screen, tr("Seed: %d", g.runSeed), panelX+6, panelY+22)
+	for i, label := range rows {
+		if i == g.pause.Cursor {
+			label = "> " + label
+		} else {
+			label = "  " + label
+		}
+		ui.DrawText(screen, label, panelX+10, panelY+pauseHeader+pauseRowHeight*i+4)
+	}
+}
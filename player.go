@@ -2,32 +2,56 @@ package main
 
 import (
 	"image/color"
-	"math/rand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+const (
+	playerBaseAttack   = 8 // Melee damage before level scaling
+	playerAttackPerLvl = 2
+	backstabMultiplier = 2  // Damage multiplier against monsters that haven't noticed the player
+	xpPerLevel         = 50 // Experience needed per current level to level up
+	healthPerLevel     = 10 // Max health gained per level
+)
+
 type Player struct {
+	Name         string
+	Color        color.RGBA // Tint used when drawing the player
 	X, Y         int
 	Health       int
 	MaxHealth    int
 	Score        int
+	Gold         int
 	FOVEnabled   bool
 	FOVRadius    int
 	moveCooldown int // frames until next move
 
 	Path []Point // A list of points (tiles) the player will follow
 
+	Inventory []Item // Carried items; their weight slows movement past CarryLimit
+	Hotbar    Hotbar // Item types slotted for quick use with the number keys
+
 	// New player stats that affect interactions
 	Defense    int // Reduces damage from monsters
 	Luck       int // Increases treasure value
 	Level      int // Player's current level
 	Experience int // Experience points
+
+	// Light sources that feed into EffectiveFOVRadius
+	TorchTurns   int // Remaining world turns of torch light
+	LanternLevel int // Permanent radius upgrades from lanterns
+
+	Sneaking bool           // Moving at half speed to halve the range monsters notice the player from
+	Effects  []StatusEffect // Timed effects other than the torch, which has its own counter
+
+	Companion *Companion // Freed from a cage; nil until then
 }
 
 func NewPlayer(startPos [2]int) *Player {
 	return &Player{
+		Name:         defaultPlayerName,
+		Color:        color.RGBA{255, 255, 255, 255},
 		X:            startPos[0],
 		Y:            startPos[1],
 		Health:       100,
@@ -43,44 +67,113 @@ func NewPlayer(startPos [2]int) *Player {
 	}
 }
 
-func (p *Player) MoveTo(targetX, targetY int, dungeon *Dungeon, interactionHandler *InteractionHandler) {
+// MoveTo starts the player along the path to the target, interacting with the
+// next cell if it is special. It reports whether an interaction used up a turn.
+// Interactions keep the same pace as steps: none happens while the player is
+// still recovering from the last move or blow.
+func (p *Player) MoveTo(targetX, targetY int, dungeon *Dungeon, interactionHandler *InteractionHandler) bool {
 	path := dungeon.FindPath(Point{p.X, p.Y}, Point{targetX, targetY})
 	if len(path) > 1 {
 		next := path[1]
 		cell := dungeon.Cells[next.y][next.x]
 
 		// Handle interaction for special cells
-		if cell.Type == Monster || cell.Type == Treasure || cell.Type == Exit {
-			result := interactionHandler.Handle(cell.Type, p)
+		if cell.Type.IsInteractive() {
+			if p.moveCooldown > 0 {
+				return false
+			}
+			if cell.Type == Treasure && !dungeon.checkGuard(next, cell, interactionHandler) {
+				return false
+			}
+			var result InteractionResult
+			loud := cell.Type == Treasure && cell.Locked
+			if m := dungeon.MonsterAt(next.x, next.y); cell.Type == Monster && m != nil {
+				// Fights are per monster; a shrieker only screams when first struck
+				loud = cell.Shrieker && m.Health == m.MaxHealth
+				result = interactionHandler.Resolve(NewMonsterInteraction(cell, m), p)
+				dungeon.MakeNoise(next, noiseCombat)
+			} else {
+				result = interactionHandler.Handle(cell, p)
+			}
+			dungeon.ApplyResult(result)
+			p.moveCooldown = p.moveDelay()
+			if cell.Type == Treasure && cell.Locked {
+				dungeon.MakeNoise(next, noiseLockedChest)
+			}
+			if cell.Type == Treasure && cell.Guard == GuardWatched {
+				if n := dungeon.rouseGuards(next); n > 0 {
+					interactionHandler.Record(LogEvent, tr("The chest was watched! %d monsters close in.", n), 0)
+				}
+			}
+
+			// Loud actions put the whole floor on alert
+			if loud {
+				soundAlarm(dungeon, interactionHandler, p)
+			}
+
+			// Heavy hits push the monster back
+			if m := dungeon.MonsterAt(next.x, next.y); result.Knockback > 0 && m != nil {
+				interactionHandler.Record(LogCombat, dungeon.knockMonster(m, p, result.Knockback), 0)
+				result.RemoveEntity = m.Health <= 0 // The slam may finish it off
+				next = Point{m.X, m.Y}
+			}
 
 			// If the interaction removes the entity, clear the cell
 			if result.RemoveEntity {
-				dungeon.Cells[next.y][next.x].Type = Empty
+				if m := dungeon.MonsterAt(next.x, next.y); cell.Type == Monster && m != nil {
+					if msg := dungeon.killMonster(m, interactionHandler.RNG); msg != "" {
+						interactionHandler.Record(LogEvent, msg, 0)
+					}
+				} else {
+					dungeon.Cells[next.y][next.x] = Cell{Type: Empty}
+				}
 			}
 
-			// Special handling for exit
+			// Special handling for exit; the game generates the next floor and moves the player there
 			if cell.Type == Exit {
-				// Generate new random dimensions for the next dungeon
-				newWidth := 40 + rand.Intn(30) // 40–69
-				newHeight := 12 + rand.Intn(8) // 12–19
-
-				newLevel := dungeon.Level + 1
-				*dungeon = *NewDungeon(newWidth, newHeight, newLevel)
-
-				// Move player to the new entrance
-				p.X, p.Y = dungeon.Entrance[0], dungeon.Entrance[1]
-				return
+				dungeon.ExitTaken = true
+				p.Path = nil
+				return true
 			}
 
 			// Move to the cell if it's now empty
 			if dungeon.Cells[next.y][next.x].Type == Empty {
 				p.Path = path[1:2] // Just move one step
 			}
-		} else {
-			// Normal movement for empty cells
-			p.Path = path[1:] // Exclude current position
+			return true
 		}
+
+		// Normal movement for empty cells
+		p.Path = path[1:] // Exclude current position
+	}
+	return false
+}
+
+// GainExperience adds experience and levels the player up once they have
+// enough. It reports whether the player gained a level.
+func (p *Player) GainExperience(xp int) bool {
+	p.Experience += xp
+	if p.Experience < p.Level*xpPerLevel {
+		return false
 	}
+	p.Experience -= p.Level * xpPerLevel
+	p.Level++
+	p.MaxHealth += healthPerLevel
+	p.Health += healthPerLevel
+	return true
+}
+
+// AttackDamage is the damage the player deals with one melee blow
+func (p *Player) AttackDamage() int {
+	return playerBaseAttack + playerAttackPerLvl*p.Level
+}
+
+// TickStatus advances timed status effects by one world turn
+func (p *Player) TickStatus() {
+	if p.TorchTurns > 0 {
+		p.TorchTurns--
+	}
+	p.tickEffects()
 }
 
 // Helper function to calculate absolute value
@@ -92,7 +185,11 @@ func abs(n int) int {
 }
 
 func (p *Player) Draw(screen *ebiten.Image) {
-	vector.DrawFilledRect(screen, float32(p.X*tileSize), float32(p.Y*tileSize), float32(tileSize), float32(tileSize), color.White, false)
+	if spriteTiles {
+		drawSprite(screen, spritePlayer, p.X, p.Y, p.Color)
+		return
+	}
+	vector.DrawFilledRect(screen, float32(p.X*tileSize), float32(p.Y*tileSize), float32(tileSize), float32(tileSize), p.Color, false)
 }
 
 func (p *Player) Update(dungeon *Dungeon) {
@@ -111,7 +208,7 @@ func (p *Player) Update(dungeon *Dungeon) {
 		cell := dungeon.Cells[next.y][next.x]
 
 		// Stop if the next cell is not walkable
-		if cell.Type == Monster || cell.Type == Treasure {
+		if cell.StopsMovement() {
 			p.Path = nil
 			return
 		}
@@ -120,7 +217,7 @@ func (p *Player) Update(dungeon *Dungeon) {
 		p.X, p.Y = next.x, next.y
 		p.Path = p.Path[1:]
 
-		// Reset movement delay (e.g., 10 frames)
-		p.moveCooldown = 10
+		// Reset movement delay (10 frames, more when encumbered)
+		p.moveCooldown = p.moveDelay()
 	}
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	_ "embed"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	lowHealthPercent = 25 // Health at or below this share of max health pulses red
+	pulseTicks       = 60 // Ticks of one low-health pulse
+)
+
+//go:embed assets/shaders/vignette.kage
+var vignetteKage []byte
+
+//go:embed assets/shaders/screen.kage
+var screenKage []byte
+
+// Post-processing shaders: the vignette lights the dungeon as it is placed
+// on screen, and the screen shader runs over the finished frame
+var vignetteShader, screenShader *ebiten.Shader
+
+func init() {
+	var err error
+	if vignetteShader, err = ebiten.NewShader(vignetteKage); err != nil {
+		log.Fatalf("compile vignette shader: %v", err)
+	}
+	if screenShader, err = ebiten.NewShader(screenKage); err != nil {
+		log.Fatalf("compile screen shader: %v", err)
+	}
+}
+
+// PostFX holds the optional shader effects and the offscreen frame the
+// whole-screen ones are drawn from
+type PostFX struct {
+	Vignette      bool // Fade the dungeon out towards the edge of the light
+	Scanlines     bool // Draw the screen like an old CRT
+	LowHealth     bool // Pulse red around the screen when health runs low
+	reducedMotion bool // Hold the low-health glow steady rather than pulsing
+	frame         *ebiten.Image
+}
+
+// NewPostFX returns the effects picked in the settings
+func NewPostFX(settings GameSettings) *PostFX {
+	return &PostFX{
+		Vignette:      settings.Vignette,
+		Scanlines:     settings.Scanlines,
+		LowHealth:     settings.LowHealthPulse,
+		reducedMotion: settings.ReducedMotion,
+	}
+}
+
+// DrawWorld draws the dungeon image onto the view through op, darkened
+// towards the edge of the player's light when the vignette is on
+func (p *PostFX) DrawWorld(view, world *ebiten.Image, op *ebiten.DrawImageOptions, player *Player, d *Dungeon) {
+	if !p.Vignette || !player.FOVEnabled {
+		view.DrawImage(world, op)
+		return
+	}
+	radius := float32(player.EffectiveFOVRadius(d)*tileSize + tileSize/2)
+	center := float32(tileSize) / 2
+	shaderOp := &ebiten.DrawRectShaderOptions{GeoM: op.GeoM}
+	shaderOp.Images[0] = world
+	shaderOp.Uniforms = map[string]any{
+		"Center": []float32{float32(player.X*tileSize) + center, float32(player.Y*tileSize) + center},
+		"Radius": radius,
+	}
+	bounds := world.Bounds()
+	view.DrawRectShader(bounds.Dx(), bounds.Dy(), vignetteShader, shaderOp)
+}
+
+// Frame returns the image the game should draw this frame to. With no
+// whole-screen effect to apply that is the screen itself; otherwise it is an
+// offscreen frame that Present later puts on screen.
+func (p *PostFX) Frame(screen *ebiten.Image, player *Player) *ebiten.Image {
+	if !p.Scanlines && p.pulse(player) == 0 {
+		return screen
+	}
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if p.frame == nil || p.frame.Bounds().Dx() != w || p.frame.Bounds().Dy() != h {
+		p.frame = ebiten.NewImage(w, h)
+	}
+	p.frame.Clear()
+	return p.frame
+}
+
+// Present draws frame onto the screen through the whole-screen effects, if
+// Frame handed out an offscreen frame
+func (p *PostFX) Present(screen, frame *ebiten.Image, player *Player) {
+	if frame == screen {
+		return
+	}
+	scanlines := float32(0)
+	if p.Scanlines {
+		scanlines = 1
+	}
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Images[0] = frame
+	op.Uniforms = map[string]any{
+		"Scanlines": scanlines,
+		"Pulse":     float32(p.pulse(player)),
+	}
+	screen.DrawRectShader(frame.Bounds().Dx(), frame.Bounds().Dy(), screenShader, op)
+}
+
+// pulse is how strongly the low-health glow shows this tick, stronger the
+// closer the player is to death, or 0 when health is fine
+func (p *PostFX) pulse(player *Player) float64 {
+	limit := player.MaxHealth * lowHealthPercent / 100
+	if !p.LowHealth || player.Health <= 0 || player.Health > limit {
+		return 0
+	}
+	danger := 0.5 + 0.5*float64(limit-player.Health)/float64(max(1, limit))
+	if p.reducedMotion {
+		return danger * 0.6
+	}
+	wave := 0.5 + 0.5*math.Sin(2*math.Pi*float64(animTicks)/pulseTicks)
+	return danger * (0.3 + 0.7*wave)
+}
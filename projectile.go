@@ -0,0 +1,56 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const projectileSpeed = 0.5 // Tiles a projectile flies per tick
+
+// Projectile is a purely visual shot flying between two tiles. Damage is
+// applied when it is fired so turn resolution never waits on animations.
+type Projectile struct {
+	From, To Point
+	Progress float64 // 0 at From, 1 at To
+	Color    color.RGBA
+	step     float64
+}
+
+func NewProjectile(from, to Point, clr color.RGBA) *Projectile {
+	dist := float64(max(abs(to.x-from.x), abs(to.y-from.y), 1))
+	return &Projectile{From: from, To: to, Color: clr, step: projectileSpeed / dist}
+}
+
+// Update moves the projectile along and reports whether it is still flying
+func (p *Projectile) Update() bool {
+	p.Progress += p.step
+	return p.Progress < 1
+}
+
+func (p *Projectile) Draw(screen *ebiten.Image) {
+	x := float64(p.From.x) + float64(p.To.x-p.From.x)*p.Progress
+	y := float64(p.From.y) + float64(p.To.y-p.From.y)*p.Progress
+	cx, cy := float32((x+0.5)*float64(tileSize)), float32((y+0.5)*float64(tileSize))
+	vector.DrawFilledCircle(screen, cx, cy, float32(max(2, tileSize/5)), p.Color, false)
+}
+
+// fireProjectile shows a shot unless reduced motion is on
+func (g *Game) fireProjectile(from, to Point, clr color.RGBA) {
+	if g.reducedMotion {
+		return
+	}
+	g.projectiles = append(g.projectiles, NewProjectile(from, to, clr))
+}
+
+// updateProjectiles advances every projectile in flight by one tick
+func (g *Game) updateProjectiles() {
+	flying := g.projectiles[:0]
+	for _, p := range g.projectiles {
+		if p.Update() {
+			flying = append(flying, p)
+		}
+	}
+	g.projectiles = flying
+}
@@ -0,0 +1,229 @@
+package main
+
+const (
+	shrineChance      = 40 // Percent of floors with a quest shrine
+	questBaseReward   = 30 // Score for turning in a quest on level 1
+	questRewardPerLvl = 10
+	questHeal         = 20 // Health the shrine restores when a quest is turned in
+)
+
+// QuestGoal is what a quest asks the player to do
+type QuestGoal int
+
+const (
+	GoalKill QuestGoal = iota // Kill a number of monsters on the quest's floor
+	GoalFind                  // Find the lost amulet hidden on the quest's floor
+)
+
+// QuestStatus tracks a quest from the shrine offering it to its reward
+type QuestStatus int
+
+const (
+	QuestOffered QuestStatus = iota
+	QuestActive
+	QuestComplete
+	QuestTurnedIn
+	QuestFailed
+)
+
+func (s QuestStatus) String() string {
+	switch s {
+	case QuestOffered:
+		return "offered"
+	case QuestActive:
+		return "active"
+	case QuestComplete:
+		return "complete - return to the shrine"
+	case QuestTurnedIn:
+		return "done"
+	case QuestFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Quest is an objective handed out by a shrine. It only counts on the floor it
+// was given on; leaving that floor unfinished fails it.
+type Quest struct {
+	Title     string
+	Goal      QuestGoal
+	Target    int
+	Progress  int
+	Level     int
+	FloorSeed int64
+	Reward    int // Score on turn-in; half as much experience comes with it
+	Status    QuestStatus
+}
+
+// newFloorQuest builds the quest offered by the current floor's shrine
+func newFloorQuest(d *Dungeon, find bool) *Quest {
+	q := &Quest{Level: d.Level, FloorSeed: d.Seed, Reward: questBaseReward + questRewardPerLvl*d.Level}
+	if find {
+		q.Goal = GoalFind
+		q.Target = 1
+		q.Title = tr("Find the lost amulet")
+	} else {
+		q.Goal = GoalKill
+		q.Target = min(3+d.Level/2, max(1, len(d.Monsters)))
+		q.Title = tr("Kill %d monsters on this floor", q.Target)
+	}
+	return q
+}
+
+// ProgressLine describes the quest for the quest log
+func (q *Quest) ProgressLine() string {
+	return tr("%s (%d/%d) - level %d, %s", q.Title, q.Progress, q.Target, q.Level, q.Status)
+}
+
+// advance counts progress toward an active quest
+func (q *Quest) advance(n int) {
+	if q.Status != QuestActive {
+		return
+	}
+	q.Progress = min(q.Target, q.Progress+n)
+	if q.Progress >= q.Target {
+		q.Status = QuestComplete
+	}
+}
+
+// QuestLog holds every quest the player has accepted and keeps them up to date
+// from interaction events
+type QuestLog struct {
+	Quests []*Quest
+	floor  int64 // Seed of the floor the player is on
+}
+
+func NewQuestLog(events *EventBus) *QuestLog {
+	l := &QuestLog{}
+	events.Subscribe(EventMonsterKilled, func(Event) {
+		for _, q := range l.current(GoalKill) {
+			q.advance(1)
+		}
+	})
+	events.Subscribe(EventTreasureCollected, func(e Event) {
+		if t, ok := e.Source.(*TreasureInteraction); ok && t.Type == TreasureAmulet {
+			for _, q := range l.current(GoalFind) {
+				q.advance(1)
+			}
+		}
+	})
+	return l
+}
+
+// current returns the active quests of a goal that count on this floor
+func (l *QuestLog) current(goal QuestGoal) []*Quest {
+	var quests []*Quest
+	for _, q := range l.Quests {
+		if q.Goal == goal && q.FloorSeed == l.floor && q.Status == QuestActive {
+			quests = append(quests, q)
+		}
+	}
+	return quests
+}
+
+// EnterFloor fails the quests left unfinished on the floor being left
+func (l *QuestLog) EnterFloor(seed int64) {
+	l.floor = seed
+	for _, q := range l.Quests {
+		if q.FloorSeed != seed && (q.Status == QuestActive || q.Status == QuestComplete) {
+			q.Status = QuestFailed
+		}
+	}
+}
+
+// --- Shrine Interaction ---
+
+// ShrineInteraction offers its quest on the first visit and pays out the
+// reward once the player comes back with it complete
+type ShrineInteraction struct {
+	Quest *Quest
+	Log   *QuestLog
+}
+
+func NewShrineInteraction(q *Quest, log *QuestLog) *ShrineInteraction {
+	return &ShrineInteraction{Quest: q, Log: log}
+}
+
+func (s *ShrineInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	// Cleansing comes first; the quest is still there next time
+	if lifted := player.liftCurses(); lifted > 0 {
+		return InteractionResult{
+			Message:  tr("The shrine's light burns away %s.", plural(lifted, tr("a curse"), tr("your curses"))),
+			Kind:     LogEvent,
+			Category: MsgDialogue,
+		}
+	}
+	q := s.Quest
+	switch q.Status {
+	case QuestOffered:
+		q.Status = QuestActive
+		s.Log.Quests = append(s.Log.Quests, q)
+		// An amulet picked up before the quest was given still counts
+		if q.Goal == GoalFind && player.hasItem(TreasureAmulet) {
+			q.advance(1)
+		}
+		return InteractionResult{
+			Message:  tr("The shrine whispers a task: %s. (Q: quest log)", q.Title),
+			Kind:     LogEvent,
+			Category: MsgDialogue,
+		}
+	case QuestComplete:
+		q.Status = QuestTurnedIn
+		if q.Goal == GoalFind {
+			player.takeItem(TreasureAmulet)
+		}
+		return InteractionResult{
+			Message:          tr("The shrine glows. Quest complete: %s! (+%d points)", q.Title, q.Reward),
+			Kind:             LogEvent,
+			HealthChange:     questHeal,
+			ScoreChange:      q.Reward,
+			ExperienceChange: q.Reward / 2,
+			RemoveEntity:     true,
+			EntityRemoved:    Shrine,
+			Category:         MsgDialogue,
+		}
+	default:
+		return InteractionResult{
+			Message:  tr("The shrine waits: %s (%d/%d).", q.Title, q.Progress, q.Target),
+			Kind:     LogEvent,
+			Category: MsgDialogue,
+		}
+	}
+}
+
+// setUpShrine gives the floor's shrine its quest, hiding the amulet a find
+// quest asks for somewhere far from the light
+func (g *Game) setUpShrine() {
+	d := g.dungeon
+	if d.Shrine == nil {
+		return
+	}
+	rng := g.rng.Stream(StreamLoot)
+	spot, canHide := d.pickSpawnPoint(g.player, rng)
+	q := newFloorQuest(d, canHide && rng.Intn(2) == 0)
+	if q.Goal == GoalFind {
+		d.Cells[spot.y][spot.x] = NewTreasureCell(q.Reward/2, TreasureAmulet)
+	}
+	d.Cells[d.Shrine.y][d.Shrine.x].Interaction = NewShrineInteraction(q, g.quests)
+}
+
+// hasItem reports whether the inventory holds an item of the given type
+func (p *Player) hasItem(t TreasureType) bool {
+	for _, item := range p.Inventory {
+		if item.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// takeItem removes one item of the given type from the inventory, if any
+func (p *Player) takeItem(t TreasureType) {
+	for i, item := range p.Inventory {
+		if item.Type == t {
+			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Layer is a stage of drawing a frame, each drawn over the ones before it.
+// All but LayerUI are drawn in dungeon pixels and placed on screen through
+// the camera; LayerUI is drawn in screen pixels on top.
+type Layer int
+
+const (
+	LayerTerrain     Layer = iota // Floors and walls
+	LayerDecorations              // Stairs, shrines, traps and the other fixtures
+	LayerItems                    // Treasure and satchels, then the fog over remembered tiles
+	LayerEntities                 // Monsters, the companion and the player
+	LayerEffects                  // Path preview, hit flashes, floating numbers and projectiles
+	LayerUI                       // Grid, threat indicators, hover highlight, HUD and panels
+	numLayers
+)
+
+// RenderLayer is something drawn in one layer of the frame. New visuals
+// register into the layer they belong to instead of growing Game.Draw.
+type RenderLayer interface {
+	Draw(screen *ebiten.Image, f *Frame)
+}
+
+// RenderFunc lets a plain function be registered as a RenderLayer
+type RenderFunc func(screen *ebiten.Image, f *Frame)
+
+func (fn RenderFunc) Draw(screen *ebiten.Image, f *Frame) {
+	fn(screen, f)
+}
+
+// Frame is what the layers share while one frame is drawn: the game, and
+// its dungeon's cells as the player sees them, worked out once for all
+// layers
+type Frame struct {
+	Game  *Game
+	Cells []CellView
+}
+
+// Renderer draws the registered layers in order
+type Renderer struct {
+	layers [numLayers][]RenderLayer
+}
+
+// Register adds r on top of what is already drawn in layer
+func (r *Renderer) Register(layer Layer, rl RenderLayer) {
+	r.layers[layer] = append(r.layers[layer], rl)
+}
+
+// DrawLayers draws the layers from first to last, inclusive
+func (r *Renderer) DrawLayers(screen *ebiten.Image, f *Frame, first, last Layer) {
+	for layer := first; layer <= last; layer++ {
+		for _, rl := range r.layers[layer] {
+			rl.Draw(screen, f)
+		}
+	}
+}
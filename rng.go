@@ -0,0 +1,70 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// RNGStream names an independent random stream of a run. Each system draws
+// from its own stream, so adding a random call to one system never shifts the
+// numbers another system sees for the same seed.
+type RNGStream int
+
+const (
+	StreamLoot   RNGStream = iota // Loot rolls such as artifact traits
+	StreamCombat                  // Combat and skill checks
+	StreamAI                      // Monster decisions
+	StreamSpawn                   // Wandering monster spawns
+	numStreams
+)
+
+func (s RNGStream) String() string {
+	switch s {
+	case StreamLoot:
+		return "loot"
+	case StreamCombat:
+		return "combat"
+	case StreamAI:
+		return "ai"
+	case StreamSpawn:
+		return "spawn"
+	default:
+		return "unknown"
+	}
+}
+
+// RNG holds the gameplay streams of a run, all derived from the run seed.
+// Floor generation has its own stream per floor, see streamRand.
+type RNG struct {
+	Seed    int64
+	streams [numStreams]*rand.Rand
+}
+
+func NewRNG(seed int64) *RNG {
+	r := &RNG{Seed: seed}
+	for s := RNGStream(0); s < numStreams; s++ {
+		r.streams[s] = streamRand(seed, s.String())
+	}
+	return r
+}
+
+// Stream returns the random source for one system
+func (r *RNG) Stream(s RNGStream) *rand.Rand {
+	return r.streams[s]
+}
+
+// streamRand returns a random source for the named stream of a seed
+func streamRand(seed int64, name string) *rand.Rand {
+	return rand.New(rand.NewSource(deriveSeed(seed, name)))
+}
+
+// deriveSeed mixes a stream name into a seed (FNV-1a of the name, then a
+// splitmix64 finaliser) so that streams of the same seed are uncorrelated.
+func deriveSeed(seed int64, name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	z := uint64(seed) ^ h.Sum64()
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
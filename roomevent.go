@@ -0,0 +1,168 @@
+package main
+
+const (
+	roomEventsPerFloor = 2 // Regions per floor that hold a one-time event
+	roomEventRadius    = 3 // Tiles around the center that count as the region
+	roomEventMinDist   = 8 // Keeps events away from the entrance
+	ambushSize         = 2 // Monsters that spring out of an ambush
+	caveInDamage       = 4 // Dealt when the rubble has nowhere to fall but on the player
+)
+
+// RoomEventKind is what happens when the player first walks into a region
+type RoomEventKind int
+
+const (
+	RoomAmbush RoomEventKind = iota // Monsters leap out of hiding
+	RoomCaveIn                      // The ceiling collapses onto a corridor
+	RoomGhost                       // A friendly ghost shows the way to the exit
+	numRoomEvents
+)
+
+func (k RoomEventKind) String() string {
+	switch k {
+	case RoomAmbush:
+		return "Ambush"
+	case RoomCaveIn:
+		return "Cave-in"
+	case RoomGhost:
+		return "Ghost"
+	default:
+		return "Unknown"
+	}
+}
+
+// RoomEvent fires once, the first time the player steps into its region.
+// It is resolved like any other interaction, so its outcome is logged and
+// published on the event bus.
+type RoomEvent struct {
+	Kind   RoomEventKind
+	Center Point
+	Fired  bool
+
+	d *Dungeon // Floor the event reshapes
+}
+
+// Contains reports whether a tile lies in the event's region
+func (e *RoomEvent) Contains(p Point) bool {
+	return abs(p.x-e.Center.x) <= roomEventRadius && abs(p.y-e.Center.y) <= roomEventRadius
+}
+
+// region lists the bare floor tiles of the event's region, nearest the center first
+func (e *RoomEvent) region(exclude Point) []Point {
+	var tiles []Point
+	for r := 0; r <= roomEventRadius; r++ {
+		for y := e.Center.y - r; y <= e.Center.y+r; y++ {
+			for x := e.Center.x - r; x <= e.Center.x+r; x++ {
+				if max(abs(x-e.Center.x), abs(y-e.Center.y)) != r || !inBounds(x, y, e.d.Width, e.d.Height) {
+					continue
+				}
+				if e.d.Cells[y][x].Type == Empty && (Point{x, y}) != exclude {
+					tiles = append(tiles, Point{x, y})
+				}
+			}
+		}
+	}
+	return tiles
+}
+
+func (e *RoomEvent) Interact(player *Player, rng *RNG) InteractionResult {
+	d := e.d
+	here := Point{player.X, player.Y}
+	switch e.Kind {
+	case RoomAmbush:
+		var changes []CellChange
+		for _, p := range e.region(here) {
+			if len(changes) == ambushSize {
+				break
+			}
+			if abs(p.x-here.x)+abs(p.y-here.y) < 2 {
+				continue
+			}
+			level := max(1, d.Level)
+			tier := monsterTierForLevel(level)
+			changes = append(changes, CellChange{At: p, Cell: Cell{Type: Monster, InteractionLevel: level,
+				MonsterTier: tier, Species: pickSpecies(tier, d.Theme.Name, rng.Stream(StreamSpawn))}})
+		}
+		return InteractionResult{
+			Message:    tr("Ambush! %d monsters leap out of the shadows.", len(changes)),
+			Kind:       LogEvent,
+			MapChanges: changes,
+		}
+	case RoomCaveIn:
+		exit := Point{d.Exit[0], d.Exit[1]}
+		for _, p := range e.region(here) {
+			if !d.stillReachable(p, here, exit) {
+				continue
+			}
+			return InteractionResult{
+				Message:    tr("The ceiling caves in, burying a nearby passage!"),
+				Kind:       LogEvent,
+				MapChanges: []CellChange{{At: p, Cell: Cell{Type: Wall}}},
+			}
+		}
+		return InteractionResult{
+			Message:      tr("Rocks rain down on you! (-%d HP)", caveInDamage),
+			Kind:         LogEvent,
+			HealthChange: -caveInDamage,
+		}
+	case RoomGhost:
+		return InteractionResult{
+			Message:  tr("A friendly ghost drifts by and whispers the way to the exit."),
+			Kind:     LogEvent,
+			Reveal:   d.FindPathBFS(here, Point{d.Exit[0], d.Exit[1]}),
+			Category: MsgDialogue,
+		}
+	}
+	return InteractionResult{Message: tr("Nothing happens.")}
+}
+
+// stillReachable reports whether the exit can still be reached from the
+// player with a wall at p
+func (d *Dungeon) stillReachable(p, from, exit Point) bool {
+	saved := d.Cells[p.y][p.x]
+	d.Cells[p.y][p.x] = Cell{Type: Wall}
+	ok := d.FindPathBFS(from, exit) != nil
+	d.Cells[p.y][p.x] = saved
+	return ok
+}
+
+// placeRoomEvents scatters one-time events over the floor, away from the entrance
+func (d *Dungeon) placeRoomEvents() {
+	entrance := Point{d.Entrance[0], d.Entrance[1]}
+	for i := 0; i < roomEventsPerFloor; i++ {
+		for tries := 0; tries < 20; tries++ {
+			x, y := d.rng.Intn(d.Width), d.rng.Intn(d.Height)
+			if d.Cells[y][x].Type != Empty || abs(x-entrance.x)+abs(y-entrance.y) < roomEventMinDist {
+				continue
+			}
+			d.RoomEvents = append(d.RoomEvents, &RoomEvent{
+				Kind:   RoomEventKind(d.rng.Intn(int(numRoomEvents))),
+				Center: Point{x, y},
+				d:      d,
+			})
+			break
+		}
+	}
+}
+
+// checkRoomEvents fires the event of any region the player has just walked into
+func (g *Game) checkRoomEvents() {
+	d, p := g.dungeon, g.player
+	here := Point{p.X, p.Y}
+	for _, e := range d.RoomEvents {
+		if e.Fired || !e.Contains(here) {
+			continue
+		}
+		e.Fired = true
+		result := g.interactionHandler.Resolve(e, p)
+		d.ApplyResult(result)
+		// The player has seen the whole region now
+		for y := e.Center.y - roomEventRadius; y <= e.Center.y+roomEventRadius; y++ {
+			for x := e.Center.x - roomEventRadius; x <= e.Center.x+roomEventRadius; x++ {
+				if inBounds(x, y, d.Width, d.Height) {
+					d.Visited[y][x] = true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+// victoryDepth is the deepest floor; taking its exit leads out of the dungeon
+const victoryDepth = 10
+
+// RunStats tallies a run for the summary shown when it ends, won or lost
+type RunStats struct {
+	FloorsCleared  int
+	MonstersKilled int // By the player, a companion or a rival monster alike
+	GoldEarned     int
+	TreasureFound  int
+
+	lastGold int // Gold last seen, to notice gains however they came
+}
+
+// NewRunStats starts counting, listening on the bus for kills, finds and descents
+func NewRunStats(events *EventBus) *RunStats {
+	s := &RunStats{}
+	events.Subscribe(EventMonsterKilled, func(Event) { s.MonstersKilled++ })
+	events.Subscribe(EventTreasureCollected, func(Event) { s.TreasureFound++ })
+	events.Subscribe(EventLevelDescended, func(Event) { s.FloorsCleared++ })
+	return s
+}
+
+// ObserveGold counts any rise in the player's gold since the last call
+func (s *RunStats) ObserveGold(gold int) {
+	if gold > s.lastGold {
+		s.GoldEarned += gold - s.lastGold
+	}
+	s.lastGold = gold
+}
+
+// runSummary lists the run's numbers for the end screen
+func (g *Game) runSummary() []string {
+	s := g.stats
+	return []string{
+		tr("Floors cleared: %d", s.FloorsCleared),
+		tr("Monsters killed: %d", s.MonstersKilled),
+		tr("Treasure found: %d", s.TreasureFound),
+		tr("Gold earned: %d", s.GoldEarned),
+		tr("Turns taken: %d", g.turns.Turn),
+		tr("Seed: %d", g.runSeed),
+	}
+}
+
+// win ends the run in victory once the player walks out of the deepest floor
+func (g *Game) win() {
+	g.gameOver = true
+	g.won = true
+	g.player.Path = nil
+	g.recordHighScore()
+	g.forgetSave()
+	g.interactionHandler.Record(LogFloor, tr("%s escaped the dungeon with %d points!", g.player.Name, g.player.Score), 0)
+}
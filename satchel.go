@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// satchelFile stores the lost satchel between runs
+const satchelFile = "lost_satchel.json"
+
+// LostSatchel is what a casual-mode player leaves behind on death. It is tied
+// to one tile of one floor, which later runs regenerate from the same seed.
+type LostSatchel struct {
+	RunSeed   int64  `json:"run_seed"`
+	FloorSeed int64  `json:"floor_seed"`
+	Level     int    `json:"level"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Gold      int    `json:"gold"`
+	Items     []Item `json:"items"`
+}
+
+// NewLostSatchel packs half the player's gold and all carried items at their
+// current tile. The player keeps nothing that went into the satchel.
+func NewLostSatchel(runSeed int64, d *Dungeon, p *Player) *LostSatchel {
+	s := &LostSatchel{
+		RunSeed:   runSeed,
+		FloorSeed: d.Seed,
+		Level:     d.Level,
+		X:         p.X,
+		Y:         p.Y,
+		Gold:      p.Gold / 2,
+		Items:     p.Inventory,
+	}
+	p.Gold -= s.Gold
+	p.Inventory = nil
+	return s
+}
+
+// loadLostSatchel reads the satchel from disk, returning nil if there is none
+func loadLostSatchel() (*LostSatchel, error) {
+	file := dataPath(satchelFile)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s LostSatchel
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return &s, nil
+}
+
+// saveLostSatchel writes the satchel to disk, replacing any older one
+func saveLostSatchel(s *LostSatchel) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dataPath(satchelFile), data, 0o644)
+}
+
+// clearLostSatchel removes the satchel from disk once it has been recovered
+func clearLostSatchel() error {
+	err := os.Remove(dataPath(satchelFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// --- Satchel Interaction ---
+
+type SatchelInteraction struct {
+	Satchel *LostSatchel
+}
+
+func NewSatchelInteraction(s *LostSatchel) *SatchelInteraction {
+	return &SatchelInteraction{Satchel: s}
+}
+
+func (s *SatchelInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	player.Gold += s.Satchel.Gold
+
+	message := tr("Recovered your lost satchel: %d gold and %d items!", s.Satchel.Gold, len(s.Satchel.Items))
+	if err := clearLostSatchel(); err != nil {
+		message += tr(" (could not clear saved satchel: %v)", err)
+	}
+
+	return InteractionResult{
+		Message:       message,
+		Kind:          LogPickup,
+		RemoveEntity:  true,
+		EntityRemoved: Satchel,
+		Items:         s.Satchel.Items,
+	}
+}
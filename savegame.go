@@ -0,0 +1,460 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	saveSlots = 3 // How many runs can be saved side by side by hand
+
+	// autosaveSlots are kept apart from the manual ones and take turns, so
+	// a crash while one is written still leaves the other
+	autosaveSlots = 2
+)
+
+// saveSlotFile is the file a save slot is stored in. Slots count from 0,
+// with the autosave slots after the manual ones.
+func saveSlotFile(slot int) string {
+	if slot >= saveSlots {
+		return fmt.Sprintf("autosave_%d.json", slot-saveSlots+1)
+	}
+	return fmt.Sprintf("savegame_%d.json", slot+1)
+}
+
+// saveSlotName names a save slot in menus and prompts
+func saveSlotName(slot int) string {
+	if slot >= saveSlots {
+		return tr("Autosave %d", slot-saveSlots+1)
+	}
+	return tr("Slot %d", slot+1)
+}
+
+// saveVersion is bumped whenever the save layout changes, so older saves are
+// turned away rather than loaded wrong
+const saveVersion = 1
+
+// SavedGame is a run frozen between two ticks. The floor's layout follows
+// from its spec, so loading generates it again and lays the saved state over
+// it: cells as they are now, what was explored, the monsters, and how far
+// each fountain, altar and room event has been used. Floors left behind
+// can't be gone back to, so only the current one is kept.
+//
+// A few things start over on load: the floor's random streams, the
+// merchant's stock, the state scripts keep between visits, and cooldowns
+// that only last a few turns.
+type SavedGame struct {
+	Version     int            `json:"version"`
+	Summary     SaveSummary    `json:"summary"`
+	Settings    GameSettings   `json:"settings"`
+	RunSeed     int64          `json:"run_seed"`
+	Floor       FloorSpec      `json:"floor"`
+	Cells       [][]Cell       `json:"cells"`
+	Visited     [][]bool       `json:"visited"`
+	AlarmTurns  int            `json:"alarm_turns"`
+	Monsters    []SavedMonster `json:"monsters"`
+	Usage       []SavedUsage   `json:"usage"`
+	RoomEvents  []bool         `json:"room_events"` // Which of the floor's room events have fired
+	Player      *Player        `json:"player"`
+	Quests      []*Quest       `json:"quests"`
+	ShrineQuest *Quest         `json:"shrine_quest,omitempty"` // Offered by this floor's shrine but not taken yet
+	Stats       RunStats       `json:"stats"`
+	Arena       *Arena         `json:"arena,omitempty"`
+	Turn        int            `json:"turn"`
+	Ticks       int            `json:"ticks"`
+}
+
+// SaveSummary describes a save for the load menu, which reads it without
+// the rest of the run
+type SaveSummary struct {
+	Name    string    `json:"name"`
+	Depth   int       `json:"depth"`
+	Score   int       `json:"score"`
+	RunSeed int64     `json:"run_seed"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// SavedMonster is a monster entity with its links to other monsters written
+// as indexes into the saved list, -1 for none
+type SavedMonster struct {
+	X         int        `json:"x"`
+	Y         int        `json:"y"`
+	Health    int        `json:"health"`
+	MaxHealth int        `json:"max_health"`
+	State     AIState    `json:"state"`
+	Route     []Point    `json:"route,omitempty"`
+	RouteStep int        `json:"route_step"`
+	Target    Point      `json:"target"`
+	Rival     int        `json:"rival"`
+	Hunter    bool       `json:"hunter"`
+	Boss      *BossState `json:"boss,omitempty"`
+	Master    int        `json:"master"`
+	Guarding  *Point     `json:"guarding,omitempty"`
+}
+
+// SavedUsage is how far the limited interaction on one tile has been used
+type SavedUsage struct {
+	At    Point `json:"at"`
+	Usage Usage `json:"usage"`
+}
+
+// MarshalJSON writes a point as [x, y], since its fields are unexported
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{p.x, p.y})
+}
+
+// UnmarshalJSON reads a point written as [x, y]
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var xy [2]int
+	if err := json.Unmarshal(data, &xy); err != nil {
+		return err
+	}
+	p.x, p.y = xy[0], xy[1]
+	return nil
+}
+
+// SaveGame writes the run to a save slot, replacing whatever was in it
+func (g *Game) SaveGame(slot int) error {
+	data, err := json.Marshal(g.snapshot())
+	if err != nil {
+		return err
+	}
+
+	// Write beside the slot first, so a crash mid-write leaves the old save whole
+	file := dataPath(saveSlotFile(slot))
+	if err := os.WriteFile(file+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// LoadGame reads the run in a save slot, returning nil if the slot is empty
+func LoadGame(slot int) (*SavedGame, error) {
+	file := dataPath(saveSlotFile(slot))
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s SavedGame
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	if s.Version != saveVersion {
+		return nil, fmt.Errorf("%s is from an incompatible version", file)
+	}
+	if len(s.Cells) != s.Floor.Height || len(s.Visited) != s.Floor.Height || s.Player == nil {
+		return nil, fmt.Errorf("%s does not match its floor", file)
+	}
+	for y := range s.Cells {
+		if len(s.Cells[y]) != s.Floor.Width || len(s.Visited[y]) != s.Floor.Width {
+			return nil, fmt.Errorf("%s does not match its floor", file)
+		}
+	}
+	return &s, nil
+}
+
+// loadSaveSummaries reads what each slot holds, nil for an empty one. A slot
+// that can't be read counts as empty, so it can still be saved over.
+func loadSaveSummaries() []*SaveSummary {
+	summaries := make([]*SaveSummary, saveSlots+autosaveSlots)
+	for slot := range summaries {
+		file := dataPath(saveSlotFile(slot))
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("could not read %s: %v", file, err)
+			}
+			continue
+		}
+		var s struct {
+			Summary SaveSummary `json:"summary"`
+		}
+		if err := json.Unmarshal(data, &s); err != nil {
+			log.Printf("parse %s: %v", file, err)
+			continue
+		}
+		summaries[slot] = &s.Summary
+	}
+	return summaries
+}
+
+// latestSave returns the slot saved to most recently, if any holds a save
+func latestSave(summaries []*SaveSummary) (int, bool) {
+	latest := -1
+	for slot, s := range summaries {
+		if s != nil && (latest < 0 || s.SavedAt.After(summaries[latest].SavedAt)) {
+			latest = slot
+		}
+	}
+	return latest, latest >= 0
+}
+
+// saveSlotLabel describes a save slot in the save and load menus
+func saveSlotLabel(slot int, s *SaveSummary) string {
+	if s == nil {
+		return tr("%s: empty", saveSlotName(slot))
+	}
+	return tr("%s: %s, level %d, %d points, %s", saveSlotName(slot), s.Name, s.Depth, s.Score,
+		s.SavedAt.Format("Jan 2 15:04"))
+}
+
+// clearSavedGame empties a save slot
+func clearSavedGame(slot int) error {
+	err := os.Remove(dataPath(saveSlotFile(slot)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// forgetSave empties the slots holding a run that has just ended, so a
+// finished run is never loaded again. Saves of other runs are left alone.
+func (g *Game) forgetSave() {
+	for slot, s := range loadSaveSummaries() {
+		if s == nil || s.RunSeed != g.runSeed {
+			continue
+		}
+		if err := clearSavedGame(slot); err != nil {
+			g.interactionHandler.AddMessage(tr("Could not remove the saved game: %v", err))
+		}
+	}
+}
+
+// autosave saves to whichever autosave slot is empty, or else the oldest.
+// It runs on every new floor and every so many world turns.
+func (g *Game) autosave() {
+	g.lastAutosave = g.turns.Turn
+	if g.gameOver {
+		return
+	}
+	summaries := loadSaveSummaries()
+	slot := saveSlots
+	for s := saveSlots; s < len(summaries); s++ {
+		if summaries[s] == nil {
+			slot = s
+			break
+		}
+		if summaries[s].SavedAt.Before(summaries[slot].SavedAt) {
+			slot = s
+		}
+	}
+	if err := g.SaveGame(slot); err != nil {
+		g.interactionHandler.Post(MsgSystem, SeverityWarning, tr("Autosave failed: %v", err))
+	}
+}
+
+// saveRun saves to a slot from the pause menu and says how it went
+func (g *Game) saveRun(slot int) {
+	if g.gameOver {
+		g.interactionHandler.AddMessage(tr("The run is over; there is nothing to save."))
+		return
+	}
+	if err := g.SaveGame(slot); err != nil {
+		g.interactionHandler.AddMessage(tr("Could not save the game: %v", err))
+		return
+	}
+	g.interactionHandler.AddMessage(tr("Game saved to slot %d.", slot+1))
+}
+
+// snapshot captures the run as it stands
+func (g *Game) snapshot() *SavedGame {
+	d := g.dungeon
+	s := &SavedGame{
+		Version: saveVersion,
+		Summary: SaveSummary{
+			Name:    g.player.Name,
+			Depth:   d.Level,
+			Score:   g.player.Score,
+			RunSeed: g.runSeed,
+			SavedAt: time.Now(),
+		},
+		Settings:   g.settings,
+		RunSeed:    g.runSeed,
+		Floor:      d.Spec,
+		Cells:      d.Cells,
+		Visited:    d.Visited,
+		AlarmTurns: d.AlarmTurns,
+		Player:     g.player,
+		Quests:     g.quests.Quests,
+		Stats:      *g.stats,
+		Arena:      g.arena,
+		Turn:       g.turns.Turn,
+		Ticks:      g.clock.Ticks,
+	}
+
+	// Options changed from the pause menu carry over
+	s.Settings.EnableFOV = g.player.FOVEnabled
+	s.Settings.ConfirmDanger = g.confirmDanger
+	s.Settings.InteractKey = g.interactKey
+	s.Settings.ReducedMotion = g.reducedMotion
+	s.Settings.SpriteTiles = spriteTiles
+
+	index := make(map[*MonsterEntity]int, len(d.Monsters))
+	for i, m := range d.Monsters {
+		index[m] = i
+	}
+	indexOf := func(m *MonsterEntity) int {
+		if i, ok := index[m]; ok {
+			return i
+		}
+		return -1
+	}
+	for _, m := range d.Monsters {
+		s.Monsters = append(s.Monsters, SavedMonster{
+			X: m.X, Y: m.Y, Health: m.Health, MaxHealth: m.MaxHealth,
+			State: m.AI.State, Route: m.AI.Route, RouteStep: m.AI.RouteStep, Target: m.AI.Target,
+			Rival: indexOf(m.AI.Rival), Hunter: m.Hunter, Boss: m.Boss, Master: indexOf(m.Master),
+			Guarding: m.Guarding,
+		})
+	}
+
+	for y, row := range d.Cells {
+		for x, cell := range row {
+			if l, ok := cell.Interaction.(Limited); ok {
+				s.Usage = append(s.Usage, SavedUsage{At: Point{x, y}, Usage: *l.Usage()})
+			}
+			if shrine, ok := cell.Interaction.(*ShrineInteraction); ok && shrine.Quest.Status == QuestOffered {
+				s.ShrineQuest = shrine.Quest
+			}
+		}
+	}
+	for _, e := range d.RoomEvents {
+		s.RoomEvents = append(s.RoomEvents, e.Fired)
+	}
+	return s
+}
+
+// Restore builds the saved run on its floor, freshly generated from the
+// saved spec
+func (s *SavedGame) Restore(d *Dungeon) *Game {
+	g := newGame(s.Settings, s.RunSeed, d)
+	g.player = s.Player
+	g.player.Path = nil
+	g.clock.Ticks = s.Ticks
+	g.turns.Turn = s.Turn
+	g.lastAutosave = s.Turn
+	*g.stats = s.Stats
+	g.stats.lastGold = g.player.Gold
+	g.quests.Quests = s.Quests
+	if s.Arena != nil {
+		g.arena = s.Arena
+		g.arena.timer = arenaFirstWave
+	}
+
+	s.restoreFloor(d)
+	g.floorSeed = d.Seed
+	g.camera.EnterFloor()
+	g.quests.EnterFloor(d.Seed)
+
+	// The interactions the game hands out on arrival are handed out again
+	if d.Shrine != nil {
+		if q := g.shrineQuest(s.ShrineQuest); q != nil {
+			d.Cells[d.Shrine.y][d.Shrine.x].Interaction = NewShrineInteraction(q, g.quests)
+		}
+	}
+	g.setUpShop()
+	for y := range d.Cells {
+		for x := range d.Cells[y] {
+			cell := &d.Cells[y][x]
+			def, ok := scripts.ForCell(cell.Type)
+			if cell.Type == Scripted {
+				def, ok = scripts.ByID(cell.ScriptID)
+			}
+			if ok {
+				cell.Interaction = NewScriptInteraction(scripts, def, d.Level)
+			}
+		}
+	}
+	g.interactionHandler.Log.Add(LogEntry{
+		Tick: g.clock.Ticks,
+		Kind: LogFloor,
+		Text: tr("Continued on dungeon level %d: %s", d.Level, d.Theme.LocalName()),
+	})
+	return g
+}
+
+// shrineQuest finds the quest of the floor's shrine: the one taken on this
+// floor if any, or else the one it was still offering
+func (g *Game) shrineQuest(offered *Quest) *Quest {
+	for _, q := range g.quests.Quests {
+		if q.FloorSeed == g.dungeon.Seed {
+			return q
+		}
+	}
+	return offered
+}
+
+// restoreFloor lays the saved state over the generated floor. Cells that
+// still hold what was generated there keep its interaction; treasure, which
+// is rescaled on arrival and dropped by monsters, gets a new one.
+func (s *SavedGame) restoreFloor(d *Dungeon) {
+	for y, row := range s.Cells {
+		for x, cell := range row {
+			generated := d.Cells[y][x]
+			switch {
+			case cell.Type == Treasure:
+				cell.Interaction = NewTreasureInteraction(cell.InteractionLevel, cell.TreasureType)
+			case cell.Type == Satchel:
+				cell = savedSatchelCell(d, x, y)
+			case cell.Type == generated.Type:
+				cell.Interaction = generated.Interaction
+			}
+			if l, ok := cell.Interaction.(*LeverInteraction); ok {
+				l.pulled = cell.Switched
+			}
+			d.Cells[y][x] = cell
+		}
+	}
+	d.Visited = s.Visited
+	d.AlarmTurns = s.AlarmTurns
+
+	for _, u := range s.Usage {
+		if inBounds(u.At.x, u.At.y, d.Width, d.Height) {
+			if l, ok := d.Cells[u.At.y][u.At.x].Interaction.(Limited); ok {
+				*l.Usage() = u.Usage
+			}
+		}
+	}
+	for i, fired := range s.RoomEvents {
+		if i < len(d.RoomEvents) {
+			d.RoomEvents[i].Fired = fired
+		}
+	}
+
+	d.Monsters = make([]*MonsterEntity, len(s.Monsters))
+	for i, sm := range s.Monsters {
+		d.Monsters[i] = &MonsterEntity{
+			X: sm.X, Y: sm.Y, Health: sm.Health, MaxHealth: sm.MaxHealth,
+			AI:     MonsterAI{State: sm.State, Route: sm.Route, RouteStep: sm.RouteStep, Target: sm.Target},
+			Hunter: sm.Hunter, Boss: sm.Boss, Guarding: sm.Guarding,
+		}
+	}
+	linked := func(i int) *MonsterEntity {
+		if i < 0 || i >= len(d.Monsters) {
+			return nil
+		}
+		return d.Monsters[i]
+	}
+	for i, sm := range s.Monsters {
+		d.Monsters[i].AI.Rival = linked(sm.Rival)
+		d.Monsters[i].Master = linked(sm.Master)
+	}
+}
+
+// savedSatchelCell puts back a lost satchel that was still lying on the
+// floor, or leaves the tile empty if it has since been picked up in another run
+func savedSatchelCell(d *Dungeon, x, y int) Cell {
+	satchel, err := loadLostSatchel()
+	if err != nil || satchel == nil || satchel.FloorSeed != d.Seed || satchel.X != x || satchel.Y != y {
+		return Cell{Type: Empty}
+	}
+	return Cell{Type: Satchel, InteractionLevel: satchel.Gold, Interaction: NewSatchelInteraction(satchel)}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// scriptDir holds the Lua files that define modded interactables
+const scriptDir = "scripts"
+
+// scriptTimeout is the longest a script may run at a time. Scripts run on
+// the game loop, so one stuck in a loop would otherwise freeze the game.
+const scriptTimeout = 100 * time.Millisecond
+
+// scriptPath finds the scripts directory: under the config directory if the
+// player put one there, else in the working directory beside the game
+func scriptPath() string {
+	if info, err := os.Stat(configPath(scriptDir)); err == nil && info.IsDir() {
+		return configPath(scriptDir)
+	}
+	return scriptDir
+}
+
+// ScriptDef is an interactable defined by a script file. A script either
+// binds to an existing cell type, replacing its interaction everywhere, or
+// has its own id and is placed on floors by chance as a Scripted cell.
+type ScriptDef struct {
+	ID          string
+	Name        string
+	Description string
+	Cell        CellType // Existing cell type the script takes over; Scripted for its own cells
+	Chance      int      // Percent of floors a Scripted cell appears on
+	interact    *lua.LFunction
+}
+
+// ScriptRegistry holds the scripted interactables loaded at startup. All
+// scripts share one Lua state; the game loop is single threaded.
+type ScriptRegistry struct {
+	L    *lua.LState
+	Defs []*ScriptDef
+}
+
+// scripts are the registered script definitions; empty without a scripts directory
+var scripts = &ScriptRegistry{}
+
+// scriptLibs are the only Lua libraries scripts get. Without io, os and
+// package a script can't touch files or start programs; the base library's
+// own ways of loading files are taken out in newScriptState.
+var scriptLibs = []struct {
+	name string
+	open lua.LGFunction
+}{
+	{lua.BaseLibName, lua.OpenBase},
+	{lua.TabLibName, lua.OpenTable},
+	{lua.StringLibName, lua.OpenString},
+	{lua.MathLibName, lua.OpenMath},
+}
+
+// newScriptState opens a sandboxed Lua state for scripts
+func newScriptState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range scriptLibs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "require"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	return L
+}
+
+// loadScripts runs every .lua file in dir. Scripts call interactable{...} to
+// register definitions. A missing directory is not an error, and a script
+// that fails is logged and skipped so the others still load.
+func loadScripts(dir string) (*ScriptRegistry, error) {
+	r := &ScriptRegistry{L: newScriptState()}
+	r.L.SetGlobal("interactable", r.L.NewFunction(r.register))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.lua"))
+	if err != nil {
+		return r, err
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		if err := runScript(r.L, func() error { return r.L.DoFile(f) }); err != nil {
+			log.Printf("skipping script %s: %v", f, err)
+		}
+	}
+	return r, nil
+}
+
+// runScript runs fn against the Lua state, stopping the script with an
+// error once scriptTimeout has passed
+func runScript(L *lua.LState, fn func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	defer L.RemoveContext()
+	return fn()
+}
+
+// register is the interactable{...} function exposed to scripts
+func (r *ScriptRegistry) register(L *lua.LState) int {
+	t := L.CheckTable(1)
+	def := &ScriptDef{
+		ID:          lua.LVAsString(t.RawGetString("id")),
+		Name:        lua.LVAsString(t.RawGetString("name")),
+		Description: lua.LVAsString(t.RawGetString("description")),
+		Cell:        Scripted,
+		Chance:      int(lua.LVAsNumber(t.RawGetString("chance"))),
+	}
+	fn, ok := t.RawGetString("interact").(*lua.LFunction)
+	if !ok || def.ID == "" {
+		L.ArgError(1, "interactable needs an id and an interact function")
+		return 0
+	}
+	def.interact = fn
+	if def.Name == "" {
+		def.Name = def.ID
+	}
+	if name := lua.LVAsString(t.RawGetString("cell")); name != "" {
+		ct, ok := cellTypeByName(name)
+		if !ok {
+			L.ArgError(1, "unknown cell type "+name)
+			return 0
+		}
+		def.Cell = ct
+	}
+	r.Defs = append(r.Defs, def)
+	return 0
+}
+
+// cellTypeByName looks up a cell type from its String name
+func cellTypeByName(name string) (CellType, bool) {
+	for ct := Empty; ct.String() != "Unknown"; ct++ {
+		if ct.String() == name {
+			return ct, true
+		}
+	}
+	return 0, false
+}
+
+// ByID returns the definition registered under id
+func (r *ScriptRegistry) ByID(id string) (*ScriptDef, bool) {
+	for _, def := range r.Defs {
+		if def.ID == id {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+// ForCell returns the definition that takes over a cell type, if any
+func (r *ScriptRegistry) ForCell(ct CellType) (*ScriptDef, bool) {
+	for _, def := range r.Defs {
+		if def.Cell == ct && ct != Scripted {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+// --- Script Interaction ---
+
+// ScriptInteraction runs a script's interact function. Each instance keeps a
+// state table the script can use to remember earlier visits.
+type ScriptInteraction struct {
+	Def   *ScriptDef
+	L     *lua.LState
+	state *lua.LTable
+	level int // Dungeon level, passed to the script
+}
+
+func NewScriptInteraction(r *ScriptRegistry, def *ScriptDef, level int) *ScriptInteraction {
+	return &ScriptInteraction{Def: def, L: r.L, state: r.L.NewTable(), level: level}
+}
+
+// Interact calls interact(player, state) and turns the returned table into
+// an InteractionResult. Fields: message, health, score, xp, gold, remove,
+// item (a treasure type), effect {kind, turns, amount} and dialogue, which
+// shows the message in the dialogue box.
+func (s *ScriptInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	L := s.L
+	p := L.NewTable()
+	p.RawSetString("name", lua.LString(player.Name))
+	p.RawSetString("health", lua.LNumber(player.Health))
+	p.RawSetString("max_health", lua.LNumber(player.MaxHealth))
+	p.RawSetString("gold", lua.LNumber(player.Gold))
+	p.RawSetString("score", lua.LNumber(player.Score))
+	p.RawSetString("level", lua.LNumber(player.Level))
+	p.RawSetString("luck", lua.LNumber(player.EffectiveLuck()))
+	p.RawSetString("defense", lua.LNumber(player.EffectiveDefense()))
+	p.RawSetString("dungeon_level", lua.LNumber(s.level))
+	p.RawSetString("roll", lua.LNumber(rng.Stream(StreamLoot).Intn(100)))
+
+	err := runScript(L, func() error {
+		return L.CallByParam(lua.P{Fn: s.Def.interact, NRet: 1, Protect: true}, p, s.state)
+	})
+	if err != nil {
+		return InteractionResult{Message: tr("%s fizzles: %v", s.Def.Name, err), Kind: LogEvent}
+	}
+	ret, ok := L.Get(-1).(*lua.LTable)
+	L.Pop(1)
+	if !ok {
+		return InteractionResult{Message: tr("Nothing happens."), Kind: LogEvent}
+	}
+
+	result := InteractionResult{
+		Message:          lua.LVAsString(ret.RawGetString("message")),
+		Kind:             LogEvent,
+		HealthChange:     int(lua.LVAsNumber(ret.RawGetString("health"))),
+		ScoreChange:      int(lua.LVAsNumber(ret.RawGetString("score"))),
+		ExperienceChange: int(lua.LVAsNumber(ret.RawGetString("xp"))),
+		GoldChange:       int(lua.LVAsNumber(ret.RawGetString("gold"))),
+		RemoveEntity:     lua.LVAsBool(ret.RawGetString("remove")),
+		EntityRemoved:    s.Def.Cell,
+	}
+	if lua.LVAsBool(ret.RawGetString("dialogue")) {
+		result.Category = MsgDialogue
+	}
+	if name := lua.LVAsString(ret.RawGetString("item")); name != "" {
+		if item, ok := NewTreasureItem(TreasureType(name)); ok {
+			result.Items = append(result.Items, item)
+		}
+	}
+	if e, ok := ret.RawGetString("effect").(*lua.LTable); ok {
+		if kind, ok := statusKindByName(lua.LVAsString(e.RawGetString("kind"))); ok {
+			result.Effects = append(result.Effects, StatusEffect{Kind: kind,
+				Turns: int(lua.LVAsNumber(e.RawGetString("turns"))), Amount: int(lua.LVAsNumber(e.RawGetString("amount")))})
+		}
+	}
+	return result
+}
+
+// statusKindByName looks up a status kind from its lower-case name
+func statusKindByName(name string) (StatusKind, bool) {
+	switch name {
+	case "torch":
+		return StatusTorch, true
+	case "regeneration":
+		return StatusRegeneration, true
+	case "poison":
+		return StatusPoison, true
+	}
+	return 0, false
+}
+
+// attachScripts hands cells over to scripts: cell types a script binds to
+// get its interaction, and scripts with their own id may appear in a dead end
+func (g *Game) attachScripts() {
+	d := g.dungeon
+	if len(scripts.Defs) == 0 {
+		return
+	}
+	for y := range d.Cells {
+		for x := range d.Cells[y] {
+			if def, ok := scripts.ForCell(d.Cells[y][x].Type); ok {
+				d.Cells[y][x].Interaction = NewScriptInteraction(scripts, def, d.Level)
+			}
+		}
+	}
+	if g.arena != nil {
+		return
+	}
+	rng := g.rng.Stream(StreamLoot)
+	for _, def := range scripts.Defs {
+		if def.Cell != Scripted || rng.Intn(100) >= def.Chance {
+			continue
+		}
+		x, y := d.placeInDeadEnd(Scripted)
+		d.Cells[y][x].ScriptID = def.ID
+		d.Cells[y][x].Interaction = NewScriptInteraction(scripts, def, d.Level)
+	}
+}
@@ -0,0 +1,27 @@
+-- A wishing well: toss in gold for a chance at a blessing.
+-- Scripts register interactables with interactable{...}. The interact
+-- function gets the player (a read-only table) and a state table that
+-- persists for this well, and returns what happens.
+interactable {
+	id = "wishing_well",
+	name = "Wishing well",
+	description = "toss in 10 gold and make a wish",
+	chance = 30,
+	interact = function(player, state)
+		if player.gold < 10 then
+			return { message = "You need 10 gold to make a wish." }
+		end
+		state.wishes = (state.wishes or 0) + 1
+		if state.wishes > 3 then
+			return { message = "The well has run dry.", remove = true }
+		end
+		if player.roll < 20 + player.luck * 5 then
+			return {
+				message = "Your wish is granted! You feel lively.",
+				gold = -10,
+				effect = { kind = "regeneration", turns = 10, amount = 2 },
+			}
+		end
+		return { message = "The coin sinks without a sound.", gold = -10, score = 5 }
+	end,
+}
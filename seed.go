@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// maxSeedLen is the most characters the seed field takes: a sign and the
+// digits of the largest int64
+const maxSeedLen = 20
+
+// parseSeed reads a seed typed or pasted by the player. Surrounding space and
+// a leading "Seed:" label, as copied from the pause menu, are ignored.
+func parseSeed(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSpace(strings.TrimPrefix(s, "Seed:"))
+	seed, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse seed %q: %w", s, err)
+	}
+	return seed, nil
+}
+
+// copySeed puts the run seed on the system clipboard
+func copySeed(seed int64) error {
+	return clipboard.WriteAll(strconv.FormatInt(seed, 10))
+}
+
+// pasteSeed reads a seed from the system clipboard
+func pasteSeed() (int64, error) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	return parseSeed(text)
+}
+
+// copyRunSeed copies the seed of the current run and tells the player how it went
+func (g *Game) copyRunSeed() {
+	if err := copySeed(g.runSeed); err != nil {
+		g.interactionHandler.Post(MsgSystem, SeverityWarning, tr("Could not copy the seed: %v", err))
+		return
+	}
+	g.interactionHandler.AddMessage(tr("Seed %d copied to the clipboard.", g.runSeed))
+}
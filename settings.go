@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// settingsFile stores the menu's choices between sessions
+const settingsFile = "settings.json"
+
+// MenuPrefs are the menu choices kept between sessions. Choices from a list
+// are stored by name rather than position, so a list that grows or reorders
+// doesn't shift them onto another option. The seed is left out: it belongs
+// to one run.
+type MenuPrefs struct {
+	Language       string `json:"language"`
+	Resolution     string `json:"resolution"`
+	TileSize       int    `json:"tile_size"`
+	Difficulty     string `json:"difficulty"`
+	CameraFollow   string `json:"camera_follow"`
+	Sight          string `json:"sight"`
+	ScreenShake    string `json:"screen_shake"`
+	FogOpacity     int    `json:"fog_opacity"`
+	RepeatDelay    int    `json:"repeat_delay"`
+	RepeatRate     int    `json:"repeat_rate"`
+	AutosaveTurns  int    `json:"autosave_turns"`
+	SpriteTiles    bool   `json:"sprite_tiles"`
+	Vignette       bool   `json:"vignette"`
+	Scanlines      bool   `json:"scanlines"`
+	LowHealthPulse bool   `json:"low_health_pulse"`
+	EnableFOV      bool   `json:"enable_fov"`
+	CasualMode     bool   `json:"casual_mode"`
+	ReducedMotion  bool   `json:"reduced_motion"`
+	TurnBased      bool   `json:"turn_based"`
+	Arena          bool   `json:"arena"`
+	ConfirmDanger  bool   `json:"confirm_danger"`
+	InteractKey    bool   `json:"interact_key"`
+	DungeonWidth   int    `json:"dungeon_width"`
+	DungeonHeight  int    `json:"dungeon_height"`
+	PlayerName     string `json:"player_name"`
+	PlayerColor    string `json:"player_color"`
+}
+
+// prefs returns the menu's current choices
+func (m *MainMenu) prefs() MenuPrefs {
+	return MenuPrefs{
+		Language:       languages[m.selectedLanguage].Code,
+		Resolution:     resolutions[m.selectedResolution].Label,
+		TileSize:       tileSizeOptions[m.selectedTileSize],
+		Difficulty:     difficulties[m.selectedDifficulty].Label,
+		CameraFollow:   cameraEasings[m.selectedEasing].Label,
+		Sight:          fovAlgorithms[m.selectedSight].String(),
+		ScreenShake:    shakeIntensities[m.selectedShake].Label,
+		FogOpacity:     m.fogOpacity,
+		RepeatDelay:    m.repeatDelay,
+		RepeatRate:     m.repeatRate,
+		AutosaveTurns:  m.autosaveTurns,
+		SpriteTiles:    m.spriteTiles,
+		Vignette:       m.vignette,
+		Scanlines:      m.scanlines,
+		LowHealthPulse: m.lowHealthPulse,
+		EnableFOV:      m.enableFOV,
+		CasualMode:     m.casualMode,
+		ReducedMotion:  m.reducedMotion,
+		TurnBased:      m.turnBased,
+		Arena:          m.arena,
+		ConfirmDanger:  m.confirmDanger,
+		InteractKey:    m.interactKey,
+		DungeonWidth:   m.dungeonWidth,
+		DungeonHeight:  m.dungeonHeight,
+		PlayerName:     m.playerName,
+		PlayerColor:    playerColors[m.selectedColor].Label,
+	}
+}
+
+// apply sets the menu to the saved choices. A name that matches no option
+// keeps the menu's default, and numbers are held to their sliders' ranges.
+func (m *MainMenu) apply(p MenuPrefs) {
+	pick := func(selected *int, n int, match func(i int) bool) {
+		for i := range n {
+			if match(i) {
+				*selected = i
+				return
+			}
+		}
+	}
+	pick(&m.selectedLanguage, len(languages), func(i int) bool { return languages[i].Code == p.Language })
+	pick(&m.selectedResolution, len(resolutions), func(i int) bool { return resolutions[i].Label == p.Resolution })
+	pick(&m.selectedTileSize, len(tileSizeOptions), func(i int) bool { return tileSizeOptions[i] == p.TileSize })
+	pick(&m.selectedDifficulty, len(difficulties), func(i int) bool { return difficulties[i].Label == p.Difficulty })
+	pick(&m.selectedEasing, len(cameraEasings), func(i int) bool { return cameraEasings[i].Label == p.CameraFollow })
+	pick(&m.selectedSight, len(fovAlgorithms), func(i int) bool { return fovAlgorithms[i].String() == p.Sight })
+	pick(&m.selectedShake, len(shakeIntensities), func(i int) bool { return shakeIntensities[i].Label == p.ScreenShake })
+	pick(&m.selectedColor, len(playerColors), func(i int) bool { return playerColors[i].Label == p.PlayerColor })
+
+	m.fogOpacity = min(max(p.FogOpacity, 0), 100)
+	m.repeatDelay = min(max(p.RepeatDelay, 100), 1000)
+	m.repeatRate = min(max(p.RepeatRate, 1), 30)
+	m.autosaveTurns = min(max(p.AutosaveTurns, 0), 1000)
+	m.dungeonWidth = min(max(p.DungeonWidth, 20), 80)
+	m.dungeonHeight = min(max(p.DungeonHeight, 10), 40)
+	m.spriteTiles = p.SpriteTiles
+	m.vignette = p.Vignette
+	m.scanlines = p.Scanlines
+	m.lowHealthPulse = p.LowHealthPulse
+	m.enableFOV = p.EnableFOV
+	m.casualMode = p.CasualMode
+	m.reducedMotion = p.ReducedMotion
+	m.turnBased = p.TurnBased
+	m.arena = p.Arena
+	m.confirmDanger = p.ConfirmDanger
+	m.interactKey = p.InteractKey
+	if p.PlayerName != "" {
+		m.playerName = p.PlayerName
+	}
+}
+
+// loadMenuPrefs reads the settings file over the menu's defaults. A missing
+// file is not an error; fields it leaves out keep their defaults.
+func loadMenuPrefs(m *MainMenu) error {
+	file := configPath(settingsFile)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	prefs := m.prefs()
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return fmt.Errorf("parse %s: %w", file, err)
+	}
+	m.apply(prefs)
+	return nil
+}
+
+// saveMenuPrefs writes the settings file
+func saveMenuPrefs(p MenuPrefs) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath(settingsFile), data, 0o644)
+}
@@ -0,0 +1,135 @@
+package main
+
+// FOVAlgorithm decides how walls block sight, for the player's view and
+// monsters' alike
+type FOVAlgorithm int
+
+const (
+	// FOVShadowcast is recursive shadowcasting: every wall casts a shadow
+	// over what lies behind it, so corners and pillars hide what they should
+	FOVShadowcast FOVAlgorithm = iota
+	// FOVLineOfSight needs a clear Bresenham line between the two tiles
+	FOVLineOfSight
+)
+
+func (a FOVAlgorithm) String() string {
+	switch a {
+	case FOVShadowcast:
+		return "Shadowcasting"
+	case FOVLineOfSight:
+		return "Line of Sight"
+	default:
+		return "Unknown"
+	}
+}
+
+// fovAlgorithm is a global like tileSize, set from the settings of the run
+// being played so the two algorithms can be compared
+var fovAlgorithm = FOVShadowcast
+
+// sightKey identifies one shadowcast field of view
+type sightKey struct {
+	Origin Point
+	Radius int
+}
+
+// sightField is the tiles lit from an origin, in a square just big enough to
+// hold the radius
+type sightField struct {
+	origin Point
+	radius int
+	lit    []bool
+}
+
+// Sees reports whether the tile at p is lit
+func (f *sightField) Sees(p Point) bool {
+	dx, dy := p.x-f.origin.x+f.radius, p.y-f.origin.y+f.radius
+	side := 2*f.radius + 1
+	if dx < 0 || dy < 0 || dx >= side || dy >= side {
+		return false
+	}
+	return f.lit[dy*side+dx]
+}
+
+func (f *sightField) light(p Point) {
+	side := 2*f.radius + 1
+	f.lit[(p.y-f.origin.y+f.radius)*side+p.x-f.origin.x+f.radius] = true
+}
+
+// ResetSight forgets the fields of view worked out so far. It runs every
+// tick, as doors open and walls fall between them.
+func (d *Dungeon) ResetSight() {
+	clear(d.sight)
+}
+
+// fieldOfView shadowcasts from origin, reusing the result until ResetSight
+func (d *Dungeon) fieldOfView(origin Point, radius int) *sightField {
+	key := sightKey{origin, radius}
+	if f, ok := d.sight[key]; ok {
+		return f
+	}
+	side := 2*radius + 1
+	f := &sightField{origin: origin, radius: radius, lit: make([]bool, side*side)}
+	f.light(origin)
+	for _, oct := range octants {
+		d.castLight(f, 1, 1, 0, oct)
+	}
+	if d.sight == nil {
+		d.sight = make(map[sightKey]*sightField)
+	}
+	d.sight[key] = f
+	return f
+}
+
+// octants turn the coordinates of the first octant into each of the eight
+// around the origin, as the xx, xy, yx and yy factors of a transform
+var octants = [8][4]int{
+	{1, 0, 0, 1}, {0, 1, 1, 0}, {0, -1, 1, 0}, {-1, 0, 0, 1},
+	{-1, 0, 0, -1}, {0, -1, -1, 0}, {0, 1, -1, 0}, {1, 0, 0, -1},
+}
+
+// castLight lights one octant row by row, from row outwards, between the
+// slopes start and end. A run of walls splits the light: the part before it
+// carries on in a recursive call and the scan resumes past the walls.
+func (d *Dungeon) castLight(f *sightField, row int, start, end float64, oct [4]int) {
+	if start < end {
+		return
+	}
+	for dist := row; dist <= f.radius; dist++ {
+		blocked := false
+		nextStart := start
+		dy := -dist
+		for dx := -dist; dx <= 0; dx++ {
+			leftSlope := (float64(dx) - 0.5) / (float64(dy) + 0.5)
+			rightSlope := (float64(dx) + 0.5) / (float64(dy) - 0.5)
+			if start < rightSlope {
+				continue
+			}
+			if end > leftSlope {
+				break
+			}
+
+			p := Point{f.origin.x + dx*oct[0] + dy*oct[1], f.origin.y + dx*oct[2] + dy*oct[3]}
+			onMap := inBounds(p.x, p.y, d.Width, d.Height)
+			if onMap && isWithinFOV(f.origin.x, f.origin.y, p.x, p.y, f.radius) {
+				f.light(p)
+			}
+
+			wall := !onMap || d.Cells[p.y][p.x].Type == Wall
+			switch {
+			case blocked && wall:
+				nextStart = rightSlope
+			case blocked:
+				blocked = false
+				start = nextStart
+			case wall && dist < f.radius:
+				blocked = true
+				d.castLight(f, dist+1, start, leftSlope, oct)
+				nextStart = rightSlope
+			}
+		}
+		if blocked {
+			return
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// sightFixture builds a dungeon from rows of '#' walls and '.' floors, with
+// '@' marking the floor tile the view is cast from
+func sightFixture(t *testing.T, rows []string) (*Dungeon, Point) {
+	t.Helper()
+	d := &Dungeon{Width: len(rows[0]), Height: len(rows), Cells: make([][]Cell, len(rows))}
+	origin := Point{-1, -1}
+	for y, row := range rows {
+		d.Cells[y] = make([]Cell, len(row))
+		for x, c := range row {
+			switch c {
+			case '#':
+				d.Cells[y][x].Type = Wall
+			case '@':
+				origin = Point{x, y}
+			}
+		}
+	}
+	if origin.x < 0 {
+		t.Fatal("fixture has no '@'")
+	}
+	return d, origin
+}
+
+// litRows draws a field of view over the fixture: '*' for lit tiles, the
+// fixture's own character for dark ones
+func litRows(rows []string, f *sightField) []string {
+	lit := make([]string, len(rows))
+	for y, row := range rows {
+		b := []byte(row)
+		for x := range b {
+			if f.Sees(Point{x, y}) {
+				b[x] = '*'
+			}
+		}
+		lit[y] = string(b)
+	}
+	return lit
+}
+
+var sightCases = []struct {
+	name   string
+	radius int
+	rows   []string
+	want   []string
+}{
+	{
+		name:   "pillar",
+		radius: 8,
+		rows: []string{
+			"#########",
+			"#.......#",
+			"#.......#",
+			"#.@.#...#",
+			"#.......#",
+			"#.......#",
+			"#########",
+		},
+		want: []string{
+			"*********",
+			"*********",
+			"********#",
+			"*****...#",
+			"********#",
+			"*********",
+			"*********",
+		},
+	},
+	{
+		name:   "corridor corner",
+		radius: 8,
+		rows: []string{
+			"#########",
+			"#@....###",
+			"#####.###",
+			"#####.###",
+			"#####...#",
+			"#########",
+		},
+		want: []string{
+			"*******##",
+			"*******##",
+			"*******##",
+			"#####.###",
+			"#####...#",
+			"#########",
+		},
+	},
+	{
+		name:   "room doorway",
+		radius: 8,
+		rows: []string{
+			"###########",
+			"#.........#",
+			"#.........#",
+			"#####.#####",
+			"#.........#",
+			"#....@....#",
+			"###########",
+		},
+		want: []string{
+			"####***####",
+			"#...***...#",
+			"#...***...#",
+			"***********",
+			"***********",
+			"***********",
+			"***********",
+		},
+	},
+	{
+		name:   "map edge",
+		radius: 3,
+		rows: []string{
+			"@....",
+			".....",
+			"..#..",
+			".....",
+		},
+		want: []string{
+			"****.",
+			"***..",
+			"***..",
+			"*....",
+		},
+	},
+}
+
+func TestFieldOfView(t *testing.T) {
+	for _, tc := range sightCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, origin := sightFixture(t, tc.rows)
+			got := litRows(tc.rows, d.fieldOfView(origin, tc.radius))
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("lit tiles:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestFieldOfViewOffMap(t *testing.T) {
+	d, origin := sightFixture(t, sightCases[3].rows)
+	f := d.fieldOfView(origin, 3)
+	for _, p := range []Point{{-1, 0}, {0, -1}, {-1, -1}, {100, 100}} {
+		if f.Sees(p) {
+			t.Errorf("Sees(%v) = true off the map", p)
+		}
+	}
+}
+
+// Along a row, a column or a diagonal from the origin the Bresenham line is
+// exact, so shadowcasting and LineOfSight should agree there
+func TestFieldOfViewMatchesLineOfSight(t *testing.T) {
+	for _, tc := range sightCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, origin := sightFixture(t, tc.rows)
+			f := d.fieldOfView(origin, tc.radius)
+			for y := range d.Height {
+				for x := range d.Width {
+					dx, dy := abs(x-origin.x), abs(y-origin.y)
+					p := Point{x, y}
+					if dx != 0 && dy != 0 && dx != dy || !isWithinFOV(origin.x, origin.y, x, y, tc.radius) {
+						continue
+					}
+					if f.Sees(p) != d.LineOfSight(origin, p) {
+						t.Errorf("%v: shadowcast sees %t, line of sight %t", p, f.Sees(p), d.LineOfSight(origin, p))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCanSeeShadowcastSymmetric(t *testing.T) {
+	defer func(a FOVAlgorithm) { fovAlgorithm = a }(fovAlgorithm)
+	fovAlgorithm = FOVShadowcast
+
+	for _, tc := range sightCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, _ := sightFixture(t, tc.rows)
+			var floors []Point
+			for y := range d.Height {
+				for x := range d.Width {
+					if d.Cells[y][x].Type != Wall {
+						floors = append(floors, Point{x, y})
+					}
+				}
+			}
+			for _, a := range floors {
+				for _, b := range floors {
+					if d.CanSee(a, b, tc.radius) != d.CanSee(b, a, tc.radius) {
+						t.Errorf("CanSee(%v, %v) = %t but not the other way round", a, b, d.CanSee(a, b, tc.radius))
+					}
+				}
+			}
+		})
+	}
+}
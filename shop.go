@@ -0,0 +1,265 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	shopChance        = 30 // Percent of floors with a merchant
+	shopOfferCount    = 4  // Offers on sale at each merchant
+	shopPricePerLevel = 15 // Percent prices rise per dungeon level
+	shopRowHeight     = 16
+)
+
+// OfferKind is what buying an offer does for the player
+type OfferKind int
+
+const (
+	OfferItem      OfferKind = iota // Adds an item to the inventory
+	OfferHeal                       // Restores health
+	OfferMaxHealth                  // Raises max health
+	OfferDefense                    // Raises defense
+	OfferLuck                       // Raises luck
+	OfferTorch                      // Lights a torch
+)
+
+// ShopOffer is one priced line in a merchant's stock
+type ShopOffer struct {
+	Name   string
+	Kind   OfferKind
+	Amount int          // Health, stat points or item count the offer grants
+	Item   TreasureType // Item granted by OfferItem
+	Price  int
+}
+
+// shopCatalog holds every offer a merchant can stock, at level 1 prices
+var shopCatalog = []ShopOffer{
+	{Name: "Healing draught", Kind: OfferHeal, Amount: 30, Price: 25},
+	{Name: "Vitality tonic", Kind: OfferMaxHealth, Amount: 10, Price: 60},
+	{Name: "Iron plating", Kind: OfferDefense, Amount: 3, Price: 50},
+	{Name: "Lucky charm", Kind: OfferLuck, Amount: 3, Price: 40},
+	{Name: "Torch", Kind: OfferTorch, Price: 15},
+	{Name: "Weapon", Kind: OfferItem, Item: TreasureWeapon, Price: 45},
+	{Name: "Artifact", Kind: OfferItem, Item: TreasureArtifact, Price: 90},
+}
+
+// sellPrices is what the merchant pays for carried items
+var sellPrices = map[TreasureType]int{
+	TreasureGems:     20,
+	TreasureArtifact: 35,
+	TreasureWeapon:   20,
+	TrapComponents:   5,
+}
+
+// Shop is a merchant's stock for one floor
+type Shop struct {
+	Offers []ShopOffer
+}
+
+// newShop picks the merchant's offers and prices them for the dungeon level
+func (g *Game) newShop() *Shop {
+	rng := g.rng.Stream(StreamLoot)
+	shop := &Shop{}
+	for _, i := range rng.Perm(len(shopCatalog))[:shopOfferCount] {
+		offer := shopCatalog[i]
+		offer.Price = offer.Price * (100 + shopPricePerLevel*(g.dungeon.Level-1)) / 100
+		shop.Offers = append(shop.Offers, offer)
+	}
+	return shop
+}
+
+// setUpShop stocks the floor's merchant, if it has one
+func (g *Game) setUpShop() {
+	d := g.dungeon
+	if d.Merchant == nil {
+		return
+	}
+	d.Cells[d.Merchant.y][d.Merchant.x].Interaction = NewShopInteraction(g.newShop(), func(s *Shop) {
+		g.player.Path = nil
+		g.shop = &ShopMenu{Shop: s}
+		g.panels.Open(PanelShop)
+	})
+}
+
+// --- Shop Interaction ---
+
+// ShopInteraction opens the merchant's menu. The shop stays on the floor, so
+// the player can come back to it.
+type ShopInteraction struct {
+	Shop *Shop
+	open func(*Shop)
+}
+
+func NewShopInteraction(shop *Shop, open func(*Shop)) *ShopInteraction {
+	return &ShopInteraction{Shop: shop, open: open}
+}
+
+func (s *ShopInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	s.open(s.Shop)
+	return InteractionResult{Message: tr("The merchant spreads out their wares."), Kind: LogEvent, Category: MsgDialogue}
+}
+
+// --- Shop Menu ---
+
+// ShopMenu is the modal buy/sell overlay. While it is open it takes all input
+// and the world stands still.
+type ShopMenu struct {
+	Shop    *Shop
+	Selling bool
+	Cursor  int
+}
+
+// rows returns the number of selectable lines on the current tab
+func (m *ShopMenu) rows(p *Player) int {
+	if m.Selling {
+		return len(p.Inventory)
+	}
+	return len(m.Shop.Offers)
+}
+
+// shopPanel returns the rectangle the shop menu is drawn in
+func shopPanel(screenW, screenH, rows int) (x, y, w, h int) {
+	w, h = 380, 64+shopRowHeight*max(1, rows)
+	return screenW/2 - w/2, screenH/2 - h/2, w, h
+}
+
+// updateShop handles input while the shop menu is open
+func (g *Game) updateShop() {
+	m, p := g.shop, g.player
+	if input.JustPressed(ActionCancel) {
+		g.closePanel(PanelShop)
+		return
+	}
+	if input.JustPressed(ActionSwitchTab) {
+		m.Selling = !m.Selling
+		m.Cursor = 0
+	}
+
+	rows := m.rows(p)
+	if input.Repeated(ActionMoveUp) {
+		m.Cursor--
+	}
+	if input.Repeated(ActionMoveDown) {
+		m.Cursor++
+	}
+
+	// Hovering a row selects it and clicking confirms
+	confirm := input.JustPressed(ActionConfirm)
+	mouseX, mouseY := input.Cursor.X, input.Cursor.Y
+	panelX, panelY, panelW, _ := shopPanel(g.screenW, g.screenH, rows)
+	if row := (mouseY - panelY - 44) / shopRowHeight; mouseX >= panelX && mouseX < panelX+panelW &&
+		mouseY >= panelY+44 && row < rows {
+		m.Cursor = row
+		confirm = confirm || input.JustPressed(ActionClick)
+	}
+
+	if rows == 0 {
+		m.Cursor = 0
+		return
+	}
+	m.Cursor = (m.Cursor + rows) % rows
+	if !confirm {
+		return
+	}
+	if m.Selling {
+		g.interactionHandler.Record(LogPickup, sellItem(p, m.Cursor), 0)
+		m.Cursor = min(m.Cursor, max(0, len(p.Inventory)-1))
+	} else {
+		g.interactionHandler.Record(LogPickup, buyOffer(p, m.Shop.Offers[m.Cursor], g.rng), 0)
+	}
+}
+
+// buyOffer debits the player's gold and applies the offer
+func buyOffer(p *Player, offer ShopOffer, rng *RNG) string {
+	if p.Gold < offer.Price {
+		return tr("You can't afford the %s (%d gold).", offer.Name, offer.Price)
+	}
+	p.Gold -= offer.Price
+
+	switch offer.Kind {
+	case OfferHeal:
+		p.Health = min(p.MaxHealth, p.Health+offer.Amount)
+	case OfferMaxHealth:
+		p.MaxHealth += offer.Amount
+		p.Health += offer.Amount
+	case OfferDefense:
+		p.Defense += offer.Amount
+	case OfferLuck:
+		p.Luck += offer.Amount
+	case OfferTorch:
+		p.LightTorch()
+	case OfferItem:
+		item, _ := NewTreasureItem(offer.Item)
+		switch offer.Item {
+		case TreasureWeapon:
+			item.Damage = DamageType(rng.Stream(StreamLoot).Intn(int(numDamageTypes)))
+			item.Name = tr(weaponNames[item.Damage])
+		case TreasureArtifact:
+			item.Trait = artifactTraits[rng.Stream(StreamLoot).Intn(len(artifactTraits))]
+			item.Name = item.Trait.ArtifactName()
+		}
+		p.AddItem(item)
+		return tr("Bought a %s for %d gold.", item.Name, offer.Price)
+	}
+	return tr("Bought %s for %d gold.", offer.Name, offer.Price)
+}
+
+// sellItem trades the inventory item at index i for gold
+func sellItem(p *Player, i int) string {
+	item := p.Inventory[i]
+	price, ok := sellPrices[item.Type]
+	if !ok {
+		return tr("The merchant has no use for your %s.", item.Name)
+	}
+	if item.Blessing == Cursed {
+		return tr("The merchant won't touch your %s.", item.Name)
+	}
+	p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+	p.Gold += price
+	return tr("Sold %s for %d gold.", item.Name, price)
+}
+
+// drawShop draws the shop menu over the dungeon
+func (g *Game) drawShop(screen *ebiten.Image) {
+	m, p := g.shop, g.player
+	rows := m.rows(p)
+	panelX, panelY, panelW, panelH := shopPanel(screen.Bounds().Dx(), screen.Bounds().Dy(), rows)
+	drawPanel(screen, panelX, panelY, panelW, panelH)
+
+	tab := tr("BUY  | sell")
+	if m.Selling {
+		tab = tr("buy  | SELL")
+	}
+	ui.DrawText(screen, tr("Merchant (Tab: buy/sell, Enter/click: trade, Esc: leave)"), panelX+6, panelY+4)
+	ui.DrawText(screen, tr("%s      Gold: %d", tab, p.Gold), panelX+6, panelY+22)
+
+	y := panelY + 44
+	if rows == 0 {
+		ui.DrawText(screen, tr("(nothing to sell)"), panelX+6, y)
+	}
+	for i := 0; i < rows; i++ {
+		if i == m.Cursor {
+			vector.DrawFilledRect(screen, float32(panelX+2), float32(y), float32(panelW-4), shopRowHeight,
+				color.RGBA{60, 60, 90, 255}, false)
+		}
+		var line string
+		if m.Selling {
+			item := p.Inventory[i]
+			if price, ok := sellPrices[item.Type]; ok {
+				line = tr("%-24s %4d gold", item.Name, price)
+			} else {
+				line = tr("%-24s  not wanted", item.Name)
+			}
+		} else {
+			offer := m.Shop.Offers[i]
+			line = tr("%-24s %4d gold", offer.Name, offer.Price)
+		}
+		ui.DrawText(screen, line, panelX+6, y)
+		y += shopRowHeight
+	}
+}
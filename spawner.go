@@ -0,0 +1,94 @@
+package main
+
+import "math/rand"
+
+const (
+	spawnBaseInterval = 80 // World turns between spawns on Normal difficulty
+	spawnEdgeBand     = 2  // Tiles from the map border that count as its edge
+	spawnMinDistance  = 3  // Tiles beyond the player's light a spawn must be
+	maxFloorMonsters  = NumMonsters * 2
+)
+
+// Spawner brings new wandering monsters onto a floor over time, so an
+// explored floor never becomes perfectly safe.
+type Spawner struct {
+	Interval int // World turns between spawns; harder difficulties spawn faster
+	timer    int
+}
+
+// NewSpawner scales the spawn rate by the difficulty's monster modifier
+func NewSpawner(monsterMod float64) *Spawner {
+	interval := spawnInterval(monsterMod)
+	return &Spawner{Interval: interval, timer: interval}
+}
+
+// spawnInterval is the number of world turns between spawns at a monster modifier
+func spawnInterval(monsterMod float64) int {
+	return int(spawnBaseInterval / max(monsterMod, 0.1))
+}
+
+// Reset restarts the countdown, used when a new floor is entered
+func (s *Spawner) Reset() {
+	s.timer = s.Interval
+}
+
+// Tick counts down one world turn and reports whether a spawn is due
+func (s *Spawner) Tick() bool {
+	s.timer--
+	if s.timer > 0 {
+		return false
+	}
+	s.timer = s.Interval
+	return true
+}
+
+// spawnWanderer adds a monster at a map edge or dark dead end out of the
+// player's sight. It reports whether a monster was placed.
+func (g *Game) spawnWanderer() bool {
+	d, p := g.dungeon, g.player
+	if len(d.Monsters) >= maxFloorMonsters {
+		return false
+	}
+
+	spot, ok := d.pickSpawnPoint(p, g.rng.Stream(StreamSpawn))
+	if !ok {
+		return false
+	}
+
+	level := max(1, d.Level+g.rng.Stream(StreamSpawn).Intn(3)-1)
+	tier := monsterTierForLevel(level)
+	d.Cells[spot.y][spot.x] = Cell{Type: Monster, InteractionLevel: level, MonsterTier: tier,
+		Species: pickSpecies(tier, d.Theme.Name, g.rng.Stream(StreamSpawn))}
+	d.addMonster(spot.x, spot.y)
+	g.interactionHandler.Record(LogEvent, tr("You hear something stir in the dark."), 0)
+	return true
+}
+
+// pickSpawnPoint picks a bare floor tile near the map edge or in a dead end,
+// well outside the player's light.
+func (d *Dungeon) pickSpawnPoint(p *Player, rng *rand.Rand) (Point, bool) {
+	hidden := p.EffectiveFOVRadius(d) + spawnMinDistance
+
+	var candidates []Point
+	for _, de := range d.findDeadEnds() {
+		candidates = append(candidates, Point{de[0], de[1]})
+	}
+	for y := 0; y < d.Height; y++ {
+		for x := 0; x < d.Width; x++ {
+			if x < spawnEdgeBand || y < spawnEdgeBand || x >= d.Width-spawnEdgeBand || y >= d.Height-spawnEdgeBand {
+				candidates = append(candidates, Point{x, y})
+			}
+		}
+	}
+
+	var valid []Point
+	for _, c := range candidates {
+		if d.Cells[c.y][c.x].Type == Empty && !isWithinFOV(p.X, p.Y, c.x, c.y, hidden) {
+			valid = append(valid, c)
+		}
+	}
+	if len(valid) == 0 {
+		return Point{}, false
+	}
+	return valid[rng.Intn(len(valid))], true
+}
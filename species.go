@@ -0,0 +1,105 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SpeciesID indexes speciesTable
+type SpeciesID int
+
+const (
+	SpeciesRat SpeciesID = iota
+	SpeciesSkeleton
+	SpeciesOgre
+	SpeciesLich
+	SpeciesGiantFrog
+	SpeciesMyconid
+	SpeciesFireImp
+	SpeciesArcher
+	SpeciesCultist
+	SpeciesNecromancer
+	SpeciesRatKing
+)
+
+// Species holds the base stats of a kind of monster. Levels add to these.
+type Species struct {
+	Name         string
+	Tier         MonsterTier // Monsters of this tier may be this species
+	Biome        string      // Floor theme the species is limited to; "" lives anywhere
+	Health       int         // Health before level scaling
+	Damage       int         // Damage per blow before level scaling
+	MoveInterval int         // World turns between steps; lower is faster
+	Range        int         // Tiles it can shoot across with line of sight; 0 for melee only
+	Attack       DamageType  // Type of damage its blows and shots deal
+	Resist       Resistances // Damage taken off (or added to) the player's attacks
+	Faction      Faction     // Monsters fight members of other factions
+	Summons      SpeciesID   // Minion species of summoners and bosses
+	SummonEvery  int         // World turns between summons; 0 for monsters that don't summon
+	Ability      Ability     // Special move used on the player
+	Color        color.RGBA
+}
+
+var speciesTable = []Species{
+	SpeciesRat: {Name: "rat", Faction: FactionBeasts, Tier: TierEasy, Health: 6, Damage: 3, MoveInterval: 1,
+		Attack: DamagePoison, Resist: Resistances{DamageFire: -50}, Color: color.RGBA{150, 110, 80, 255}},
+	SpeciesSkeleton: {Name: "skeleton", Faction: FactionUndead, Tier: TierMedium, Health: 12, Damage: 5, MoveInterval: 2,
+		Resist: Resistances{DamagePoison: 100, DamagePhysical: 25, DamageMagic: -50}, Color: color.RGBA{225, 225, 200, 255}},
+	SpeciesOgre: {Name: "ogre", Faction: FactionBeasts, Tier: TierHard, Health: 28, Damage: 9, MoveInterval: 3,
+		Resist: Resistances{DamagePhysical: 30, DamageMagic: -30}, Color: color.RGBA{90, 150, 60, 255}},
+	SpeciesLich: {Name: "lich", Faction: FactionUndead, Tier: TierBoss, Health: 36, Damage: 12, MoveInterval: 2, Range: 5, Attack: DamageMagic,
+		Resist: Resistances{DamageMagic: 50, DamagePoison: 100, DamageFire: -30}, Summons: SpeciesSkeleton, Color: color.RGBA{150, 60, 220, 255}},
+	SpeciesGiantFrog: {Name: "giant frog", Faction: FactionBeasts, Tier: TierEasy, Biome: "Flooded Caves", Health: 8, Damage: 3, MoveInterval: 2,
+		Attack: DamagePoison, Resist: Resistances{DamagePoison: 50, DamageFire: 25}, Color: color.RGBA{60, 170, 120, 255}},
+	SpeciesMyconid: {Name: "myconid", Faction: FactionBeasts, Tier: TierMedium, Biome: "Fungal Grotto", Health: 16, Damage: 4, MoveInterval: 3,
+		Attack: DamagePoison, Resist: Resistances{DamagePoison: 75, DamageFire: -75}, Color: color.RGBA{200, 120, 150, 255}},
+	SpeciesFireImp: {Name: "fire imp", Faction: FactionCultists, Tier: TierMedium, Biome: "Scorched Halls", Health: 9, Damage: 6, MoveInterval: 1,
+		Attack: DamageFire, Resist: Resistances{DamageFire: 100, DamageMagic: -25}, Ability: AbilityBlink, Color: color.RGBA{255, 110, 30, 255}},
+	SpeciesArcher: {Name: "skeleton archer", Faction: FactionUndead, Tier: TierMedium, Health: 8, Damage: 4, MoveInterval: 2, Range: 5,
+		Resist: Resistances{DamagePoison: 100, DamageMagic: -50}, Color: color.RGBA{200, 200, 150, 255}},
+	SpeciesCultist: {Name: "cultist", Faction: FactionCultists, Tier: TierHard, Health: 16, Damage: 7, MoveInterval: 2, Range: 4,
+		Attack: DamageFire, Resist: Resistances{DamageMagic: 25, DamagePhysical: -25}, Ability: AbilityPull, Color: color.RGBA{180, 40, 40, 255}},
+	SpeciesNecromancer: {Name: "necromancer", Faction: FactionUndead, Tier: TierHard, Health: 14, Damage: 5, MoveInterval: 3, Attack: DamageMagic,
+		Resist: Resistances{DamagePoison: 50, DamagePhysical: -25}, Summons: SpeciesSkeleton, SummonEvery: 8,
+		Color: color.RGBA{90, 200, 200, 255}},
+	SpeciesRatKing: {Name: "rat king", Faction: FactionBeasts, Tier: TierMedium, Health: 14, Damage: 4, MoveInterval: 2, Attack: DamagePoison,
+		Resist: Resistances{DamageFire: -50}, Summons: SpeciesRat, SummonEvery: 6, Color: color.RGBA{120, 80, 50, 255}},
+}
+
+// Info returns the stats of a species
+func (s SpeciesID) Info() Species {
+	return speciesTable[s]
+}
+
+// LocalName is the species name in the player's language
+func (s SpeciesID) LocalName() string {
+	return tr(speciesTable[s].Name)
+}
+
+// Title is the species name with a capital letter, for the start of a line
+func (s SpeciesID) Title() string {
+	return capitalize(s.LocalName())
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// pickSpecies chooses a species for a monster of the given tier on a floor
+// with the given theme.
+func pickSpecies(tier MonsterTier, theme string, rng *rand.Rand) SpeciesID {
+	var candidates []SpeciesID
+	for id, s := range speciesTable {
+		if s.Tier == tier && (s.Biome == "" || s.Biome == theme) {
+			candidates = append(candidates, SpeciesID(id))
+		}
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
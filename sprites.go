@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The tileset is drawn in shades of gray so each sprite can be tinted with
+// the color its tile has in the flat-color mode, keeping the two modes and
+// the help screen's legend in agreement.
+//
+//go:embed assets/tiles.png
+var tilesPNG []byte
+
+const (
+	spriteSize    = 16 // Width and height of one sprite in the atlas, in pixels
+	spriteColumns = 8  // Sprites per row of the atlas
+)
+
+// Sprites in the order they appear in the atlas, row by row
+const (
+	spriteWall = iota
+	spriteFloor
+	spriteStairsDown
+	spriteStairsUp
+	spritePlayer
+	spriteTrap
+	spriteSatchel
+	spriteCage
+	spriteMonsterEasy
+	spriteMonsterMedium
+	spriteMonsterHard
+	spriteMonsterBoss
+	spriteShrine
+	spriteMerchant
+	spriteLever
+	spriteFountain
+	spriteGold
+	spriteGems
+	spriteArtifact
+	spritePotion
+	spriteTorch
+	spriteLantern
+	spriteWeapon
+	spriteAmulet
+	spriteAltar
+	spriteScripted
+	spriteChest
+	spriteGate
+	spriteTorch2
+	spriteTorch3
+	spriteFountain2
+	spriteFountain3
+
+	// Wall pieces start a new row: one for each combination of neighboring
+	// walls, indexed by wallMask
+	spriteWallTiles = 4 * spriteColumns
+)
+
+// Idle frames of the monster sprites follow the wall pieces
+const (
+	spriteMonsterEasy2 = spriteWallTiles + 16 + iota
+	spriteMonsterMedium2
+	spriteMonsterHard2
+	spriteMonsterBoss2
+	spriteMonsterEasy3
+	spriteMonsterMedium3
+	spriteMonsterHard3
+	spriteMonsterBoss3
+	numSprites
+)
+
+// spriteTiles draws the dungeon with the tileset instead of flat colors. It
+// is a global like tileSize, set from the settings of the run being played.
+var spriteTiles = true
+
+// Floor tiles are lighter than the flat-color floor so the sprite's
+// texture stays visible
+var floorSpriteColor = color.RGBA{70, 70, 80, 255}
+
+var monsterSprites = map[MonsterTier]int{
+	TierEasy:   spriteMonsterEasy,
+	TierMedium: spriteMonsterMedium,
+	TierHard:   spriteMonsterHard,
+	TierBoss:   spriteMonsterBoss,
+}
+
+var treasureSprites = map[TreasureType]int{
+	TreasureGold:     spriteGold,
+	TreasureGems:     spriteGems,
+	TreasureArtifact: spriteArtifact,
+	TreasurePotion:   spritePotion,
+	TreasureTorch:    spriteTorch,
+	TreasureLantern:  spriteLantern,
+	TreasureWeapon:   spriteWeapon,
+	TreasureAmulet:   spriteAmulet,
+}
+
+var objectSprites = map[CellType]int{
+	Entrance: spriteStairsUp,
+	Exit:     spriteStairsDown,
+	Satchel:  spriteSatchel,
+	Trap:     spriteTrap,
+	Cage:     spriteCage,
+	Shrine:   spriteShrine,
+	Merchant: spriteMerchant,
+	Lever:    spriteLever,
+	Fountain: spriteFountain,
+	Altar:    spriteAltar,
+	Scripted: spriteScripted,
+}
+
+// sprites holds each sprite cut out of the atlas
+var sprites [numSprites]*ebiten.Image
+
+func init() {
+	img, err := png.Decode(bytes.NewReader(tilesPNG))
+	if err != nil {
+		log.Fatalf("decode tileset: %v", err)
+	}
+	atlas := ebiten.NewImageFromImage(img)
+	for i := range sprites {
+		x, y := (i%spriteColumns)*spriteSize, (i/spriteColumns)*spriteSize
+		sprites[i] = atlas.SubImage(image.Rect(x, y, x+spriteSize, y+spriteSize)).(*ebiten.Image)
+	}
+}
+
+// drawSprite draws a sprite over the tile at (x, y), scaled to tileSize and
+// tinted with the given color
+func drawSprite(screen *ebiten.Image, sprite, x, y int, tint color.RGBA) {
+	op := &ebiten.DrawImageOptions{}
+	scale := float64(tileSize) / spriteSize
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(float64(x*tileSize), float64(y*tileSize))
+	op.ColorScale.ScaleWithColor(tint)
+	screen.DrawImage(sprites[sprite], op)
+}
+
+// cellSprite picks the sprite for what the player sees on a cell, or
+// returns false for bare floor
+func cellSprite(cell Cell) (int, bool) {
+	switch cell.VisibleType() {
+	case Empty:
+		return 0, false
+	case Wall:
+		if cell.Gate {
+			return spriteGate, true
+		}
+		return spriteWall, true
+	case Monster:
+		return monsterSprites[cell.MonsterTier], true
+	case Treasure:
+		if sprite, ok := treasureSprites[cell.TreasureType]; ok {
+			return sprite, true
+		}
+		return spriteChest, true
+	}
+	sprite, ok := objectSprites[cell.VisibleType()]
+	return sprite, ok
+}
+
+// wallMask tells which neighbors of the wall at (x, y) are walls too, as
+// bits for north (1), east (2), south (4) and west (8). The wall piece drawn
+// there only shows an edge towards open floor, so rooms and corridors read
+// as shapes rather than a grid of blocks. Tiles past the map's edge count as
+// walls, and so do ones never seen with fov on, so the pieces don't give away
+// the layout.
+func (d *Dungeon) wallMask(x, y int, fov bool) int {
+	mask := 0
+	for bit, dir := range [4]Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		nx, ny := x+dir.x, y+dir.y
+		if !inBounds(nx, ny, d.Width, d.Height) || d.Cells[ny][nx].Type == Wall || (fov && !d.Visited[ny][nx]) {
+			mask |= 1 << bit
+		}
+	}
+	return mask
+}
+
+// lightenColor raises each channel of c by a fixed amount
+func lightenColor(c color.RGBA) color.RGBA {
+	const lift = 70
+	return color.RGBA{
+		R: uint8(min(255, int(c.R)+lift)),
+		G: uint8(min(255, int(c.G)+lift)),
+		B: uint8(min(255, int(c.B)+lift)),
+		A: c.A,
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import "fmt"
+
+const (
+	fountainRegenTurns  = 5 // Turns a fountain keeps healing after the first sip
+	fountainRegenAmount = 2
+)
+
+// StatusKind is a timed effect on the player
+type StatusKind int
+
+const (
+	StatusTorch        StatusKind = iota // Lights a torch; lasts torchDuration
+	StatusRegeneration                   // Heals Amount every world turn
+	StatusPoison                         // Deals Amount every world turn
+)
+
+func (k StatusKind) String() string {
+	switch k {
+	case StatusTorch:
+		return "Torch"
+	case StatusRegeneration:
+		return "Regenerating"
+	case StatusPoison:
+		return "Poisoned"
+	default:
+		return "Unknown"
+	}
+}
+
+// StatusEffect is a status applied to the player for a number of world turns
+type StatusEffect struct {
+	Kind   StatusKind
+	Turns  int
+	Amount int
+}
+
+func (e StatusEffect) String() string {
+	return fmt.Sprintf("%s (%d)", e.Kind, e.Turns)
+}
+
+// ApplyEffect starts a status effect on the player. Effects of a kind the
+// player already has refresh it rather than stacking.
+func (p *Player) ApplyEffect(e StatusEffect) {
+	if e.Kind == StatusTorch {
+		p.LightTorch()
+		return
+	}
+	for i := range p.Effects {
+		if p.Effects[i].Kind == e.Kind {
+			p.Effects[i] = e
+			return
+		}
+	}
+	p.Effects = append(p.Effects, e)
+}
+
+// tickEffects runs one world turn of the player's timed effects
+func (p *Player) tickEffects() {
+	active := p.Effects[:0]
+	for _, e := range p.Effects {
+		switch e.Kind {
+		case StatusRegeneration:
+			p.Health = min(p.MaxHealth, p.Health+e.Amount)
+		case StatusPoison:
+			p.Health -= e.Amount
+		}
+		if e.Turns--; e.Turns > 0 {
+			active = append(active, e)
+		}
+	}
+	p.Effects = active
+}
@@ -0,0 +1,56 @@
+package main
+
+import "fmt"
+
+const (
+	maxMinions        = 3 // Living minions a summoner keeps at once
+	minionLevelOffset = 2 // Minions are this many levels below their summoner
+)
+
+// updateSummoner counts down a summoner's timer and calls a weaker minion
+// onto a nearby empty tile when it runs out.
+func (g *Game) updateSummoner(m *MonsterEntity) {
+	d := g.dungeon
+	cell := d.Cells[m.Y][m.X]
+	species := cell.Species.Info()
+	if species.SummonEvery == 0 {
+		return
+	}
+	if m.summon > 0 {
+		m.summon--
+		return
+	}
+	m.summon = species.SummonEvery - 1
+
+	if d.minionCount(m) >= maxMinions {
+		return
+	}
+	spots := d.nearestEmptyCells(Point{m.X, m.Y}, 1, Point{g.player.X, g.player.Y})
+	if len(spots) == 0 {
+		return
+	}
+
+	p := spots[0]
+	level := max(1, cell.InteractionLevel-minionLevelOffset)
+	d.Cells[p.y][p.x] = Cell{Type: Monster, InteractionLevel: level,
+		MonsterTier: monsterTierForLevel(level), Species: species.Summons}
+	minion := d.addMonster(p.x, p.y)
+	minion.Master = m
+	minion.AI.State = m.AI.State
+
+	if d.CanSee(Point{g.player.X, g.player.Y}, p, g.player.EffectiveFOVRadius(d)) {
+		g.interactionHandler.Record(LogCombat,
+			fmt.Sprintf("The %s summons a %s!", cell.MonsterName(), species.Summons.LocalName()), 0)
+	}
+}
+
+// minionCount returns how many of a summoner's minions are still alive
+func (d *Dungeon) minionCount(master *MonsterEntity) int {
+	n := 0
+	for _, m := range d.Monsters {
+		if m.Master == master {
+			n++
+		}
+	}
+	return n
+}
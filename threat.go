@@ -0,0 +1,70 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	threatArrowSize  = 10 // Length of an edge arrow in pixels
+	threatEdgeInset  = 14 // Distance of edge arrows from the viewport border
+	threatPulseTicks = 40 // Ticks for one pulse of the threat highlight
+)
+
+var threatColor = color.RGBA{230, 60, 50, 255}
+
+// drawThreatIndicators warns about monsters hunting the player that can't be
+// seen: those scrolled outside the viewport get an arrow on its edge, those
+// hidden in the dark get an outline on their tile
+func (g *Game) drawThreatIndicators(screen *ebiten.Image, view image.Rectangle) {
+	d, p := g.dungeon, g.player
+	radius := p.EffectiveFOVRadius(d)
+	pulse := 0.6 + 0.4*math.Sin(2*math.Pi*float64(g.clock.Ticks%threatPulseTicks)/threatPulseTicks)
+	clr := threatColor
+	clr.A = uint8(255 * pulse)
+	clr = premultiply(clr)
+
+	for _, m := range d.Monsters {
+		if !m.Aware() {
+			continue
+		}
+		x, y := g.camera.WorldToScreen(m.X, m.Y)
+		size := g.camera.TileSize()
+		cx, cy := x+size/2, y+size/2
+		if !image.Pt(int(cx), int(cy)).In(view) {
+			drawEdgeArrow(screen, view, cx, cy, clr)
+			continue
+		}
+		if p.FOVEnabled && !d.CanSee(Point{p.X, p.Y}, Point{m.X, m.Y}, radius) {
+			vector.StrokeRect(screen, float32(x), float32(y), float32(size), float32(size), 2, clr, false)
+		}
+	}
+}
+
+// drawEdgeArrow draws a chevron on the border of view pointing at (tx, ty)
+func drawEdgeArrow(screen *ebiten.Image, view image.Rectangle, tx, ty float64, clr color.RGBA) {
+	// Aim from the middle of the viewport and stop the ray at the inset border
+	mx := float64(view.Min.X+view.Max.X) / 2
+	my := float64(view.Min.Y+view.Max.Y) / 2
+	dx, dy := tx-mx, ty-my
+	halfW := float64(view.Dx())/2 - threatEdgeInset
+	halfH := float64(view.Dy())/2 - threatEdgeInset
+	if halfW <= 0 || halfH <= 0 || (dx == 0 && dy == 0) {
+		return
+	}
+	scale := math.Min(halfW/math.Max(math.Abs(dx), 1e-9), halfH/math.Max(math.Abs(dy), 1e-9))
+	ax, ay := mx+dx*scale, my+dy*scale
+
+	angle := math.Atan2(dy, dx)
+	tipX, tipY := ax+math.Cos(angle)*threatArrowSize/2, ay+math.Sin(angle)*threatArrowSize/2
+	backX, backY := ax-math.Cos(angle)*threatArrowSize/2, ay-math.Sin(angle)*threatArrowSize/2
+	sideX, sideY := -math.Sin(angle)*threatArrowSize/2, math.Cos(angle)*threatArrowSize/2
+
+	// A chevron reads clearly at small sizes and needs no triangle mesh
+	vector.StrokeLine(screen, float32(tipX), float32(tipY), float32(backX+sideX), float32(backY+sideY), 3, clr, true)
+	vector.StrokeLine(screen, float32(tipX), float32(tipY), float32(backX-sideX), float32(backY-sideY), 3, clr, true)
+}
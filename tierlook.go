@@ -0,0 +1,74 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// TierLook is how a monster's tier shows on the map, so its threat reads at a
+// glance without hovering: the weaker the monster, the smaller it is drawn
+type TierLook struct {
+	Size    float64 // Share of the tile the monster fills
+	Outline bool    // Ringed in bossOutlineColor
+}
+
+var tierLooks = map[MonsterTier]TierLook{
+	TierEasy:   {Size: 0.6},
+	TierMedium: {Size: 0.75},
+	TierHard:   {Size: 0.9},
+	TierBoss:   {Size: 0.875, Outline: true},
+}
+
+// bossOutlineColor rings bosses in both drawing modes
+var bossOutlineColor = color.RGBA{220, 40, 40, 255}
+
+// breathFrames are the idle frames that drop a monster's body a pixel
+var breathFrames = map[int]bool{
+	spriteMonsterEasy2:   true,
+	spriteMonsterMedium2: true,
+	spriteMonsterHard2:   true,
+	spriteMonsterBoss2:   true,
+}
+
+// drawMonsterSprite draws a monster's sprite at the size of its tier,
+// standing on the bottom of the tile, with the boss outline traced around it
+func drawMonsterSprite(screen *ebiten.Image, sprite, x, y int, tier MonsterTier, tint color.RGBA) {
+	look := tierLooks[tier]
+	scale := float64(tileSize) / spriteSize * look.Size
+	left := float64(x*tileSize) + float64(tileSize)*(1-look.Size)/2
+	top := float64(y*tileSize) + float64(tileSize)*(1-look.Size)
+	if look.Outline {
+		top -= scale // Leave room for the outline below the feet
+		for _, d := range [4]Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(scale, scale)
+			op.GeoM.Translate(left+float64(d.x)*scale, top+float64(d.y)*scale)
+			op.ColorScale.ScaleWithColor(bossOutlineColor)
+			screen.DrawImage(sprites[sprite], op)
+		}
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(left, top)
+	op.ColorScale.ScaleWithColor(tint)
+	screen.DrawImage(sprites[sprite], op)
+}
+
+// drawFlatMonster draws a monster in the flat-color mode as a square the
+// size of its tier, bobbing with its idle frames
+func drawFlatMonster(screen *ebiten.Image, x, y int, cell Cell, clr color.RGBA) {
+	tile := float32(tileSize)
+	look := tierLooks[cell.MonsterTier]
+	size := tile * float32(look.Size)
+	left := float32(x)*tile + (tile-size)/2
+	top, height := float32(y)*tile+(tile-size)/2, size
+	if frame, _ := animate(monsterSprites[cell.MonsterTier], x, y, clr); breathFrames[frame] {
+		top, height = top+max(1, tile/spriteSize), height-max(1, tile/spriteSize)
+	}
+	vector.DrawFilledRect(screen, left, top, size, height, clr, false)
+	if look.Outline {
+		vector.StrokeRect(screen, left-1, top-1, size+2, height+2, 2, bossOutlineColor, false)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	tooltipLineHeight = 16
+	tooltipCharWidth  = 6 // Width of a debug font glyph
+	tooltipPadding    = 6
+	tooltipOffset     = 16 // Gap between the cursor and the box
+)
+
+// Tooltip is the boxed, multi-line description shown next to the cursor; the
+// type lives in the ui package so menu widgets can carry one
+type Tooltip = ui.Tooltip
+
+// drawTooltip draws the tooltip below and right of the cursor, flipping to
+// the other side wherever it would leave the screen
+func drawTooltip(screen *ebiten.Image, t Tooltip, cursorX, cursorY int) {
+	if t.Empty() {
+		return
+	}
+	lines := append([]string{t.Title}, t.Lines...)
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line)*tooltipCharWidth)
+	}
+	w, h := width+2*tooltipPadding, len(lines)*tooltipLineHeight+tooltipPadding
+
+	screenW, screenH := screen.Bounds().Dx(), screen.Bounds().Dy()
+	x, y := cursorX+tooltipOffset, cursorY+tooltipOffset
+	if x+w > screenW {
+		x = cursorX - tooltipOffset/2 - w
+	}
+	if y+h > screenH {
+		y = cursorY - tooltipOffset/2 - h
+	}
+	x, y = max(0, x), max(0, y)
+
+	drawPanel(screen, x, y, w, h)
+	for i, line := range lines {
+		ui.DrawText(screen, line, x+tooltipPadding, y+2+tooltipLineHeight*i)
+	}
+}
+
+// cellTooltip describes the tile at x, y as far as the player can tell
+func (g *Game) cellTooltip(x, y int) Tooltip {
+	cell := g.dungeon.Cells[y][x]
+	var t Tooltip
+
+	switch cell.VisibleType() {
+	case Monster:
+		t.Title = fmt.Sprintf("%s (Level %d)", capitalize(cell.MonsterName()), cell.InteractionLevel)
+		if cell.Elite != AffixNone {
+			t.Title = fmt.Sprintf("Elite %s (Level %d)", cell.MonsterName(), cell.InteractionLevel)
+		}
+		if cell.Shrieker {
+			t.Title = fmt.Sprintf("Shrieking %s (Level %d)", cell.MonsterName(), cell.InteractionLevel)
+			t.Add("Raises the alarm when struck")
+		}
+		if m := g.dungeon.MonsterAt(x, y); m != nil {
+			if m.Boss != nil {
+				t.Title = fmt.Sprintf("%s (Level %d, boss)", m.Boss.Name, cell.InteractionLevel)
+			}
+			t.Add("HP %d/%d, %s", m.Health, m.MaxHealth, m.AI.State)
+			if m.Guarding != nil {
+				t.Add("Guards a chest")
+			}
+		}
+		species := cell.Species.Info()
+		t.Add("%s, deals %s", capitalize(species.Faction.String()), species.Attack)
+		if species.SummonEvery > 0 {
+			t.Add("Summons %ss - kill it first", species.Summons.Info().Name)
+		}
+		t.Add("%s", species.Resist.Describe())
+	case Treasure:
+		t.Title = fmt.Sprintf("%s (Value %d)", capitalize(string(cell.TreasureType)), cell.InteractionLevel)
+		if cell.Locked {
+			t.Title = "Locked " + t.Title
+			t.Add("Noisy to open")
+		}
+		if _, carried := itemWeights[cell.TreasureType]; carried {
+			t.Add("Unidentified, may be cursed")
+		}
+		switch cell.Guard {
+		case GuardSealed:
+			t.Add("Sealed until its guardian dies")
+		case GuardWatched:
+			t.Add("Watched by a guardian")
+		}
+	case Exit:
+		t.Title = fmt.Sprintf("Exit to Level %d", cell.InteractionLevel)
+		t.Lines = strings.Split(g.dungeon.NextFloorSpec().Forecast(), "\n")
+		if g.arena == nil && cell.InteractionLevel > victoryDepth {
+			t.Title, t.Lines = "The way out", []string{"Leave the dungeon and win the run"}
+		}
+	case Entrance:
+		t.Title = "Entrance"
+	case Cage:
+		t.Title = "Cage"
+		t.Add("Something stirs inside. Open it to free a companion")
+	case Lever:
+		t.Title = "Lever"
+		t.Add("Opens a gate somewhere on this floor")
+		if cell.Switched {
+			t.Lines = []string{"Already pulled"}
+		}
+	case Fountain:
+		t.Title = "Fountain"
+		t.Add("Heals %d, refills every %d turns", fountainHeal, fountainCooldown)
+	case Altar:
+		t.Title = "Healing altar"
+		t.Add("Restores all health once")
+	case Scripted:
+		if def, ok := scripts.ByID(cell.ScriptID); ok {
+			t.Title = def.Name
+			t.Add("%s", def.Description)
+		}
+	case Merchant:
+		t.Title = "Merchant"
+		t.Add("Buy supplies or sell your finds")
+	case Shrine:
+		t.Title = "Shrine"
+		t.Add("Touch it to receive or turn in a quest")
+		if s, ok := cell.Interaction.(*ShrineInteraction); ok && s.Quest.Status != QuestOffered {
+			t.Lines = []string{fmt.Sprintf("%s (%d/%d)", s.Quest.Title, s.Quest.Progress, s.Quest.Target)}
+		}
+		t.Add("Lifts curses from carried items")
+	case Satchel:
+		t.Title = "Your lost satchel"
+		t.Add("%d gold inside", cell.InteractionLevel)
+	case Trap:
+		t.Title = fmt.Sprintf("Trap (%d damage)", cell.InteractionLevel)
+		t.Add("Click to disarm")
+	case Empty:
+		t.Title = "Empty"
+	case Wall:
+		t.Title = "Wall"
+		if cell.Gate {
+			t.Title = "Gate"
+			t.Add("Opened by a lever")
+		}
+	}
+
+	if l, ok := cell.Interaction.(Limited); ok {
+		t.Add("%s", capitalize(l.Usage().Describe(g.turns.Turn)))
+	}
+	return t
+}
+
+// Tooltip describes a carried item for the inventory panel
+func (item Item) Tooltip() Tooltip {
+	t := Tooltip{Title: capitalize(item.Name)}
+	t.Add("Weight %d", item.Weight)
+	if item.Equipped {
+		t.Add("Equipped")
+	}
+	if item.Trait != TraitNone {
+		t.Add("%s: %s", item.Trait, item.Trait.Description())
+	}
+	if item.Type == TreasureWeapon {
+		t.Add("Deals %s damage", item.Damage)
+	}
+	switch item.Blessing {
+	case Cursed:
+		t.Add("Cursed: -%d defense, -%d luck. Can't be dropped or sold", curseDefense, curseLuck)
+	case Blessed:
+		t.Add("Blessed: +%d defense, +%d luck", blessDefense, blessLuck)
+	}
+	if price, ok := sellPrices[item.Type]; ok && item.Blessing != Cursed {
+		t.Add("Sells for %d gold", price)
+	}
+	return t
+}
+
+// hoverTooltip returns the tooltip for whatever the cursor is over, preferring
+// open panels over the map beneath them
+func (g *Game) hoverTooltip(screen *ebiten.Image) Tooltip {
+	if g.inventory != nil {
+		if item, ok := g.hoveredItem(screen.Bounds().Dx()); ok {
+			return item.Tooltip()
+		}
+		return Tooltip{}
+	}
+	if g.hoverX >= 0 && g.hoverY >= 0 && g.hoverX < g.dungeon.Width && g.hoverY < g.dungeon.Height {
+		t := g.cellTooltip(g.hoverX, g.hoverY)
+		g.addTravelInfo(&t)
+		return t
+	}
+	return Tooltip{}
+}
+
+// addTravelInfo tells how far away the hovered tile is along the highlighted
+// path, and how much of the way is risky. A selected tile's tooltip also
+// says how to set off.
+func (g *Game) addTravelInfo(t *Tooltip) {
+	if len(g.pathToHover) == 0 || g.pathTarget() != (Point{g.hoverX, g.hoverY}) {
+		return
+	}
+	steps := len(g.pathToHover)
+	cost, risky := g.dungeon.PathCost(g.pathToHover)
+	if last := g.pathToHover[steps-1]; last != [2]int{g.hoverX, g.hoverY} {
+		t.Add("%s", tr("Path stops after %d steps", steps))
+	} else if steps == 1 {
+		t.Add("%s", tr("1 step away"))
+	} else {
+		t.Add("%s", tr("%d steps away", steps))
+	}
+	if risky > 0 {
+		t.Add("%s", tr("Travel cost %d, %d risky steps", cost, risky))
+	}
+	if g.selected != nil {
+		t.Add("%s", tr("Double-click or Enter to travel"))
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+// Trait is a passive modifier granted by carrying an artifact
+type Trait int
+
+const (
+	TraitNone         Trait = iota
+	TraitLifesteal          // Heal part of the damage taken when killing a monster
+	TraitTrapImmunity       // Traps never trigger
+	TraitMonsterSense       // Sense monsters beyond the light radius
+)
+
+// artifactTraits lists the traits an artifact can roll
+var artifactTraits = []Trait{TraitLifesteal, TraitTrapImmunity, TraitMonsterSense}
+
+const (
+	lifestealPercent = 25 // Share of kill damage healed per lifesteal artifact
+	senseRangeBonus  = 3  // Extra monster detection range per sense artifact
+)
+
+func (t Trait) String() string {
+	switch t {
+	case TraitNone:
+		return "None"
+	case TraitLifesteal:
+		return "Lifesteal"
+	case TraitTrapImmunity:
+		return "Trap Immunity"
+	case TraitMonsterSense:
+		return "Monster Sense"
+	default:
+		return "Unknown"
+	}
+}
+
+// Description explains the trait for the character sheet
+func (t Trait) Description() string {
+	switch t {
+	case TraitLifesteal:
+		return tr("heal %d%% of damage taken on kills", lifestealPercent)
+	case TraitTrapImmunity:
+		return tr("traps never trigger")
+	case TraitMonsterSense:
+		return tr("+%d monster detection range", senseRangeBonus)
+	default:
+		return ""
+	}
+}
+
+// ArtifactName names an artifact by its trait, in the player's language
+func (t Trait) ArtifactName() string {
+	return tr("artifact of %s", tr(t.String()))
+}
+
+// TraitCount returns how many carried artifacts grant the trait; effects stack
+func (p *Player) TraitCount(t Trait) int {
+	count := 0
+	for _, item := range p.Inventory {
+		if item.Trait == t {
+			count++
+		}
+	}
+	return count
+}
+
+// HasTrait reports whether any carried artifact grants the trait
+func (p *Player) HasTrait(t Trait) bool {
+	return p.TraitCount(t) > 0
+}
+
+// MonsterSenseRadius returns how far away monsters are sensed, or 0 without the trait
+func (p *Player) MonsterSenseRadius(d *Dungeon) int {
+	stacks := p.TraitCount(TraitMonsterSense)
+	if stacks == 0 {
+		return 0
+	}
+	return p.EffectiveFOVRadius(d) + stacks*senseRangeBonus
+}
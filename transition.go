@@ -0,0 +1,98 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/ZDSDD/AI_GAME/ui"
+)
+
+const (
+	transitionFade = 20 // Ticks to fade to black, and back in on the new floor
+	transitionHold = 40 // Ticks the black screen stays up at least, so it can be read
+)
+
+// FloorTransition is the fade through black between floors. The next floor
+// is generated in the background while the screen is dark, so a large
+// dungeon doesn't stall the frame it is entered on.
+type FloorTransition struct {
+	Spec    FloorSpec
+	frame   int           // Ticks since the exit was taken
+	arrived int           // Frame the new floor was swapped in; 0 until then
+	ready   chan *Dungeon // Receives the floor once generated
+	next    *Dungeon
+}
+
+// newFloorTransition starts generating the floor described by spec
+func newFloorTransition(spec FloorSpec) *FloorTransition {
+	return &FloorTransition{Spec: spec, ready: generateFloor(spec)}
+}
+
+// alpha is how dark the screen is, from 0 to 1
+func (t *FloorTransition) alpha() float64 {
+	if t.arrived == 0 {
+		return min(1, float64(t.frame)/transitionFade)
+	}
+	return max(0, 1-float64(t.frame-t.arrived)/transitionFade)
+}
+
+// updateTransition advances the fade, swapping in the new floor once it is
+// generated and the screen has been dark long enough
+func (g *Game) updateTransition() {
+	t := g.transition
+	t.frame++
+	if t.next == nil {
+		select {
+		case t.next = <-t.ready:
+		default:
+		}
+	}
+
+	if t.arrived == 0 && t.next != nil && t.frame >= transitionFade+transitionHold {
+		// Copy into the existing dungeon so everything holding it sees the new floor
+		*g.dungeon = *t.next
+		g.player.X, g.player.Y = g.dungeon.Entrance[0], g.dungeon.Entrance[1]
+		g.player.Path, g.selected = nil, nil
+		g.enterFloor()
+		g.autosave() // Every new floor is a checkpoint
+		t.arrived = t.frame
+	}
+	if t.arrived > 0 && t.frame-t.arrived >= transitionFade {
+		g.transition = nil
+	}
+}
+
+// drawTransition darkens the screen and names the floor being entered
+func (g *Game) drawTransition(screen *ebiten.Image) {
+	t := g.transition
+	if t == nil {
+		return
+	}
+	a := t.alpha()
+	screenW, screenH := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, 0, 0, float32(screenW), float32(screenH),
+		color.NRGBA{0, 0, 0, uint8(255 * a)}, false)
+
+	title := tr("Descending to depth %d…", t.Spec.Level)
+	subtitle := t.Spec.Theme.LocalName()
+	if t.Spec.Level > victoryDepth {
+		title, subtitle = tr("Climbing out of the dungeon…"), ""
+	}
+	if t.Spec.Modifier != ModifierNone && subtitle != "" {
+		subtitle += " - " + tr(t.Spec.Modifier.String())
+	}
+
+	textAlpha := uint8(255 * a)
+	y := screenH/2 - 40
+	ui.Text.Draw(screen, title, screenW/2, y, ui.TextStyle{Size: ui.SizeTitle, Bold: true,
+		Align: ui.AlignCenter, Color: color.NRGBA{255, 255, 255, textAlpha}})
+	if subtitle != "" {
+		ui.Text.Draw(screen, subtitle, screenW/2, y+44, ui.TextStyle{Size: ui.SizeLarge,
+			Align: ui.AlignCenter, Color: color.NRGBA{180, 180, 200, textAlpha}})
+	}
+	if t.next == nil && t.frame >= transitionFade+transitionHold {
+		drawSpinner(screen, screenW/2, y+100, t.frame, textAlpha)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math/rand"
+)
+
+const (
+	NumTraps = 6
+
+	trapSpotChance      = 20 // Base percent chance per step to spot an adjacent hidden trap
+	disarmRollBase      = 50 // Base percent chance of the reduced-motion disarm roll
+	disarmZoneBase      = 0.15
+	disarmMarkerSpeed   = 0.015 // Marker travel per tick on a level 0 trap
+	disarmSpeedPerLevel = 0.002
+)
+
+// trapDamage returns the damage dealt by a trap on the given dungeon level
+func trapDamage(level int) int {
+	return 5 + level*2
+}
+
+// DisarmMinigame is the timing check for disarming a revealed trap: a marker
+// sweeps across a bar and the player has to stop it inside the target zone.
+type DisarmMinigame struct {
+	X, Y      int     // Trap being disarmed
+	Marker    float64 // Marker position along the bar, 0..1
+	Speed     float64 // Marker travel per tick
+	ZoneStart float64 // Start of the target zone along the bar
+	ZoneWidth float64
+}
+
+// NewDisarmMinigame sets up the check for the trap at x, y. Luck widens the
+// target zone, deeper traps move the marker faster.
+func NewDisarmMinigame(x, y, luck, level int, rng *rand.Rand) *DisarmMinigame {
+	zoneWidth := disarmZoneBase + float64(luck)/200
+	return &DisarmMinigame{
+		X:         x,
+		Y:         y,
+		Speed:     disarmMarkerSpeed + disarmSpeedPerLevel*float64(level),
+		ZoneStart: rng.Float64() * (1 - zoneWidth),
+		ZoneWidth: zoneWidth,
+	}
+}
+
+// Update moves the marker, bouncing it off both ends of the bar
+func (m *DisarmMinigame) Update() {
+	m.Marker += m.Speed
+	if m.Marker > 1 {
+		m.Marker = 2 - m.Marker
+		m.Speed = -m.Speed
+	} else if m.Marker < 0 {
+		m.Marker = -m.Marker
+		m.Speed = -m.Speed
+	}
+}
+
+// Hit reports whether the marker is currently inside the target zone
+func (m *DisarmMinigame) Hit() bool {
+	return m.Marker >= m.ZoneStart && m.Marker <= m.ZoneStart+m.ZoneWidth
+}
+
+// disarmRoll is the Luck-based alternative to the minigame for reduced-motion mode
+func disarmRoll(luck int, rng *rand.Rand) bool {
+	return rng.Intn(100) < disarmRollBase+luck*2
+}
+
+// spotAdjacentTraps gives the player a chance to notice hidden traps next to them
+func (g *Game) spotAdjacentTraps() {
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			x, y := g.player.X+dx, g.player.Y+dy
+			if !inBounds(x, y, g.dungeon.Width, g.dungeon.Height) {
+				continue
+			}
+			cell := &g.dungeon.Cells[y][x]
+			if cell.Type == Trap && !cell.Revealed && g.rng.Stream(StreamCombat).Intn(100) < trapSpotChance+g.player.EffectiveLuck() {
+				cell.Revealed = true
+				g.stopTravel()
+				g.interactionHandler.Post(MsgSystem, SeverityWarning, tr("You spot a trap!"))
+			}
+		}
+	}
+}
+
+// startDisarm begins disarming the revealed trap at x, y
+func (g *Game) startDisarm(x, y int) {
+	g.player.Path = nil
+	if g.reducedMotion {
+		g.resolveDisarm(x, y, disarmRoll(g.player.EffectiveLuck(), g.rng.Stream(StreamCombat)))
+		return
+	}
+	g.disarm = NewDisarmMinigame(x, y, g.player.EffectiveLuck(), g.dungeon.Level, g.rng.Stream(StreamCombat))
+}
+
+// resolveDisarm finishes a disarm attempt: success salvages components,
+// failure sets the trap off.
+func (g *Game) resolveDisarm(x, y int, success bool) {
+	g.disarm = nil
+	g.turns.PlayerActed()
+	if !success {
+		g.interactionHandler.AddMessage(tr("You fumble the mechanism!"))
+		g.triggerTrap(x, y)
+		return
+	}
+
+	g.dungeon.Cells[y][x] = Cell{Type: Empty}
+	item, _ := NewTreasureItem(TrapComponents)
+	g.player.AddItem(item)
+	g.interactionHandler.Record(LogPickup, tr("Trap disarmed! Salvaged trap components."), 0)
+}
+
+// triggerTrap sets off the trap at x, y and removes it from the map
+func (g *Game) triggerTrap(x, y int) {
+	damage := g.dungeon.Cells[y][x].InteractionLevel
+	g.dungeon.Cells[y][x] = Cell{Type: Empty}
+	g.dungeon.MakeNoise(Point{x, y}, noiseTrap)
+	g.dungeon.Impact(Point{x, y}, trapImpact)
+
+	if g.player.HasTrait(TraitTrapImmunity) {
+		g.interactionHandler.Record(LogEvent, tr("A trap springs, but your artifact shields you."), 0)
+		return
+	}
+	g.damagePlayer(nil, damage)
+	g.interactionHandler.Record(LogEvent, tr("A trap springs! Took %d damage.", damage), -damage)
+}
@@ -0,0 +1,120 @@
+package main
+
+// Mouse travel takes two steps. A click selects a tile and pins the path
+// preview to it; a second click on it, or Enter, sets off along the path.
+// The walk then goes on by itself, a step at a time, until it gets there,
+// runs into something, a monster comes into view or any key or click stops
+// it. The loot key sets off the same way towards the nearest known loot.
+
+// doubleClickTicks is the longest gap, in ticks, between the two clicks of
+// a double click
+const doubleClickTicks = 20
+
+// clickTile selects a tile, or travels to it when it is clicked again soon
+// after being selected
+func (g *Game) clickTile(tx, ty int) {
+	tile := Point{tx, ty}
+	if g.selected != nil && *g.selected == tile && animTicks-g.lastClick <= doubleClickTicks {
+		g.startTravel(tile)
+		return
+	}
+	g.selected = &tile
+	g.lastClick = animTicks
+}
+
+// startTravel clears the selection and sets off towards a tile, taking the
+// first step now so a dangerous one asks right away
+func (g *Game) startTravel(target Point) {
+	g.selected = nil
+	g.travel = &target
+	g.travelSeen = g.monstersInSight()
+	if !g.walkTo(target.x, target.y, true) {
+		g.travel = nil
+	}
+}
+
+// updateTravel keeps an auto-travel going, or stops it on any key press or
+// click, along with whatever steps the player had left
+func (g *Game) updateTravel() {
+	if g.travel == nil {
+		return
+	}
+	if input.Pressed() {
+		g.stopTravel()
+		return
+	}
+	// Monsters leaving sight lower the count, so the next to appear counts
+	seen := g.monstersInSight()
+	if seen > g.travelSeen {
+		g.stopTravel()
+		g.interactionHandler.AddMessage(tr("A monster comes into view."))
+		return
+	}
+	g.travelSeen = seen
+	if !g.clock.Paused && !g.walkTo(g.travel.x, g.travel.y, false) {
+		g.travel = nil
+	}
+}
+
+// stopTravel ends an auto-travel where the player stands. Getting hurt or
+// spotting a trap calls it too, so travel never walks on into danger.
+func (g *Game) stopTravel() {
+	g.travel = nil
+	g.player.Path = nil
+}
+
+// travelToLoot sets off towards the nearest treasure or satchel the player
+// has seen on this floor
+func (g *Game) travelToLoot() {
+	target, ok := g.nearestLoot()
+	if !ok {
+		g.interactionHandler.AddMessage(tr("No known loot is in reach."))
+		return
+	}
+	g.startTravel(target)
+}
+
+// nearestLoot finds the treasure or satchel the player knows of that is the
+// fewest steps away
+func (g *Game) nearestLoot() (Point, bool) {
+	d, p := g.dungeon, g.player
+	var best Point
+	bestSteps := 0
+	for y, row := range d.Cells {
+		for x, cell := range row {
+			if cell.Type != Treasure && cell.Type != Satchel {
+				continue
+			}
+			if p.FOVEnabled && !d.Visited[y][x] {
+				continue
+			}
+			path := d.FindPath(Point{p.X, p.Y}, Point{x, y})
+			if len(path) > 1 && (bestSteps == 0 || len(path) < bestSteps) {
+				best, bestSteps = Point{x, y}, len(path)
+			}
+		}
+	}
+	return best, bestSteps > 0
+}
+
+// monstersInSight counts the monsters the player can see
+func (g *Game) monstersInSight() int {
+	d, p := g.dungeon, g.player
+	radius := p.EffectiveFOVRadius(d)
+	n := 0
+	for _, m := range d.Monsters {
+		if !p.FOVEnabled || d.CanSee(Point{p.X, p.Y}, Point{m.X, m.Y}, radius) {
+			n++
+		}
+	}
+	return n
+}
+
+// pathTarget is the tile the path preview leads to: the selected tile, or
+// else the one pointed at
+func (g *Game) pathTarget() Point {
+	if g.selected != nil {
+		return *g.selected
+	}
+	return Point{g.hoverX, g.hoverY}
+}
@@ -0,0 +1,62 @@
+package main
+
+// realtimeTurnTicks is how many ticks make up one world turn in real-time
+// mode, matching the player's unencumbered step delay
+const realtimeTurnTicks = baseMoveCooldown
+
+// TurnScheduler decides when the world simulation (monster moves, status
+// effects, hunger) advances. In real-time mode it runs on a fixed tick cadence;
+// in turn-based mode it only runs when the player acts.
+type TurnScheduler struct {
+	TurnBased bool
+	Turn      int // World turns simulated so far
+
+	pending int // Player actions not yet simulated
+	ticks   int // Ticks since the last real-time world turn
+}
+
+func NewTurnScheduler(turnBased bool) *TurnScheduler {
+	return &TurnScheduler{TurnBased: turnBased}
+}
+
+// PlayerActed records that the player spent a turn (a step, an attack, ...)
+func (s *TurnScheduler) PlayerActed() {
+	s.pending++
+}
+
+// Tick is called once per unpaused simulation tick and returns how many world
+// turns should be simulated now.
+func (s *TurnScheduler) Tick() int {
+	if s.TurnBased {
+		n := s.pending
+		s.pending = 0
+		s.Turn += n
+		return n
+	}
+
+	s.pending = 0
+	s.ticks++
+	if s.ticks < realtimeTurnTicks {
+		return 0
+	}
+	s.ticks = 0
+	s.Turn++
+	return 1
+}
+
+// advanceWorld simulates a single world turn
+func (g *Game) advanceWorld() {
+	g.checkRoomEvents()
+	g.player.TickStatus()
+	g.updateCompanion()
+	g.updateMonsters()
+	if g.arena != nil {
+		g.updateArena()
+	} else if g.spawner.Tick() {
+		g.spawnWanderer()
+	}
+
+	if g.dungeon.TickAlarm() {
+		g.interactionHandler.AddMessage(tr("The dungeon grows quiet again."))
+	}
+}
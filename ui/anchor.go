@@ -0,0 +1,48 @@
+package ui
+
+import "image"
+
+// Anchor places a rectangle relative to its parent, so it follows the parent
+// when the window is resized or the resolution changes. Each edge is pinned
+// to a fraction of the parent's width or height, 0 being its left or top
+// edge and 1 its right or bottom edge, then moved by a pixel offset.
+type Anchor struct {
+	Left, Top, Right, Bottom float64 // Where each edge is pinned, as a fraction of the parent
+	Offset                   image.Rectangle
+}
+
+// Resolve returns the rectangle the anchor describes inside parent
+func (a Anchor) Resolve(parent image.Rectangle) image.Rectangle {
+	at := func(origin, size int, frac float64) int {
+		return origin + int(float64(size)*frac)
+	}
+	w, h := parent.Dx(), parent.Dy()
+	return image.Rect(
+		at(parent.Min.X, w, a.Left)+a.Offset.Min.X,
+		at(parent.Min.Y, h, a.Top)+a.Offset.Min.Y,
+		at(parent.Min.X, w, a.Right)+a.Offset.Max.X,
+		at(parent.Min.Y, h, a.Bottom)+a.Offset.Max.Y,
+	)
+}
+
+// Fill stretches over the whole parent, inset by the given margins
+func Fill(left, top, right, bottom int) Anchor {
+	return Anchor{Right: 1, Bottom: 1, Offset: image.Rect(left, top, -right, -bottom)}
+}
+
+// Center is a w by h rectangle centered in the parent
+func Center(w, h int) Anchor {
+	return Anchor{Left: 0.5, Top: 0.5, Right: 0.5, Bottom: 0.5, Offset: image.Rect(-w/2, -h/2, w-w/2, h-h/2)}
+}
+
+// TopCenter is a w by h rectangle centered horizontally, y pixels below the
+// parent's top edge
+func TopCenter(w, h, y int) Anchor {
+	return Anchor{Left: 0.5, Right: 0.5, Offset: image.Rect(-w/2, y, w-w/2, y+h)}
+}
+
+// BottomCenter is a w by h rectangle centered horizontally, y pixels above
+// the parent's bottom edge
+func BottomCenter(w, h, y int) Anchor {
+	return Anchor{Left: 0.5, Top: 1, Right: 0.5, Bottom: 1, Offset: image.Rect(-w/2, -y-h, w-w/2, -y)}
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Button runs OnClick when clicked
+type Button struct {
+	box
+	focus
+	Label    string
+	Selected bool
+	Height   int         // 0 means DefaultHeight
+	Swatch   color.Color // Optional color sample drawn inside the button
+	Tooltip  Tooltip
+	OnClick  func()
+}
+
+func (b *Button) PreferredHeight(int) int {
+	if b.Height > 0 {
+		return b.Height
+	}
+	return DefaultHeight
+}
+
+func (b *Button) Update(in *Input) {
+	if in.Clicked && in.Over(b.rect) && b.OnClick != nil {
+		in.Clicked = false
+		b.OnClick()
+	}
+}
+
+func (b *Button) Draw(dst *ebiten.Image, offset image.Point) {
+	r := b.rect.Add(offset)
+	fill := ColorIdle
+	if b.Selected {
+		fill = ColorSelected
+	}
+	drawFrame(dst, r, fill)
+
+	// Color sample on the right side of the button
+	if b.Swatch != nil {
+		vector.DrawFilledRect(dst, float32(r.Max.X-22), float32(r.Min.Y+8),
+			14, float32(r.Dy()-16), b.Swatch, false)
+	}
+	drawCaption(dst, b.Label, r)
+	b.drawFocus(dst, r)
+}
+
+func (b *Button) Navigate(n Nav) bool {
+	if n != NavActivate || b.OnClick == nil {
+		return false
+	}
+	b.OnClick()
+	return true
+}
+
+func (b *Button) TooltipAt(p image.Point) Tooltip {
+	if p.In(b.rect) {
+		return b.Tooltip
+	}
+	return Tooltip{}
+}
+
+// Toggle is an ON/OFF switch that shows its state in its label
+type Toggle struct {
+	box
+	focus
+	Label    string
+	Value    bool
+	Tooltip  Tooltip
+	OnChange func(bool)
+}
+
+// ToggleLabel returns the caption of an ON/OFF switch
+func ToggleLabel(name string, enabled bool) string {
+	if enabled {
+		return name + ": " + Translate("ON")
+	}
+	return name + ": " + Translate("OFF")
+}
+
+func (t *Toggle) PreferredHeight(int) int { return DefaultHeight }
+
+func (t *Toggle) Update(in *Input) {
+	if in.Clicked && in.Over(t.rect) {
+		in.Clicked = false
+		t.flip()
+	}
+}
+
+func (t *Toggle) flip() {
+	t.Value = !t.Value
+	if t.OnChange != nil {
+		t.OnChange(t.Value)
+	}
+}
+
+func (t *Toggle) Navigate(n Nav) bool {
+	if n != NavActivate {
+		return false
+	}
+	t.flip()
+	return true
+}
+
+func (t *Toggle) Draw(dst *ebiten.Image, offset image.Point) {
+	r := t.rect.Add(offset)
+	fill := ColorIdle
+	if t.Value {
+		fill = ColorSelected
+	}
+	drawFrame(dst, r, fill)
+	drawCaption(dst, ToggleLabel(t.Label, t.Value), r)
+	t.drawFocus(dst, r)
+}
+
+func (t *Toggle) TooltipAt(p image.Point) Tooltip {
+	if p.In(t.rect) {
+		return t.Tooltip
+	}
+	return Tooltip{}
+}
+
+// Label is a section heading
+type Label struct {
+	box
+	Text    string
+	Tooltip Tooltip
+}
+
+func (l *Label) PreferredHeight(int) int { return DefaultHeight }
+
+func (l *Label) Update(*Input) {}
+
+func (l *Label) Draw(dst *ebiten.Image, offset image.Point) {
+	r := l.rect.Add(offset)
+	style := TextStyle{Size: SizeLarge, Bold: true}
+	_, h := Text.Measure(l.Text, style)
+	Text.Draw(dst, l.Text, r.Min.X+10, r.Max.Y-h-2, style)
+}
+
+func (l *Label) TooltipAt(p image.Point) Tooltip {
+	if p.In(l.rect) {
+		return l.Tooltip
+	}
+	return Tooltip{}
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const dropdownOptionHeight = 26
+
+// Dropdown picks one of several options from a list that opens below it.
+// With focus, activating opens the list, up and down move through it and
+// activating again picks; left and right step through the options directly.
+type Dropdown struct {
+	box
+	focus
+	Label    string
+	Options  []string
+	Selected int
+	Tooltip  Tooltip
+	OnChange func(int)
+	open     bool
+	hover    int // Option highlighted from the keyboard while open
+}
+
+func (d *Dropdown) PreferredHeight(int) int { return DefaultHeight }
+
+// Open reports whether the option list is showing
+func (d *Dropdown) Open() bool { return d.open }
+
+// option returns where the i-th option is drawn while the list is open
+func (d *Dropdown) option(i int) image.Rectangle {
+	y := d.rect.Max.Y + i*dropdownOptionHeight
+	return image.Rect(d.rect.Min.X, y, d.rect.Max.X, y+dropdownOptionHeight)
+}
+
+func (d *Dropdown) Update(in *Input) {
+	if !in.Clicked {
+		return
+	}
+	if !d.open {
+		if in.Over(d.rect) {
+			in.Clicked = false
+			d.setOpen(true)
+		}
+		return
+	}
+
+	// Any click closes the list; a click on an option also picks it
+	in.Clicked = false
+	d.open = false
+	for i := range d.Options {
+		if in.Cursor.In(d.option(i)) {
+			d.pick(i)
+			return
+		}
+	}
+}
+
+func (d *Dropdown) setOpen(open bool) {
+	d.open = open
+	d.hover = d.Selected
+}
+
+// pick selects an option, notifying OnChange if it changed
+func (d *Dropdown) pick(i int) {
+	if i == d.Selected || i < 0 || i >= len(d.Options) {
+		return
+	}
+	d.Selected = i
+	if d.OnChange != nil {
+		d.OnChange(i)
+	}
+}
+
+func (d *Dropdown) Navigate(n Nav) bool {
+	switch {
+	case n == NavActivate && d.open:
+		d.open = false
+		d.pick(d.hover)
+	case n == NavActivate:
+		d.setOpen(true)
+	case d.open && n == NavUp:
+		d.hover = max(0, d.hover-1)
+	case d.open && n == NavDown:
+		d.hover = min(len(d.Options)-1, d.hover+1)
+	case !d.open && n == NavLeft:
+		d.pick((d.Selected + len(d.Options) - 1) % len(d.Options))
+	case !d.open && n == NavRight:
+		d.pick((d.Selected + 1) % len(d.Options))
+	default:
+		return false
+	}
+	return true
+}
+
+func (d *Dropdown) Draw(dst *ebiten.Image, offset image.Point) {
+	r := d.rect.Add(offset)
+	fill := ColorIdle
+	if d.open {
+		fill = ColorSelected
+	}
+	drawFrame(dst, r, fill)
+	caption := d.Label + ": "
+	if d.Selected >= 0 && d.Selected < len(d.Options) {
+		caption += d.Options[d.Selected]
+	}
+	drawCaption(dst, caption, r)
+	Text.Draw(dst, "▼", r.Max.X-10, r.Min.Y+(r.Dy()-SizeBody)/2, TextStyle{Size: SizeSmall, Align: AlignRight})
+	d.drawFocus(dst, r)
+}
+
+// DrawPopup draws the open option list over whatever lies below the dropdown
+func (d *Dropdown) DrawPopup(dst *ebiten.Image, offset image.Point) {
+	if !d.open {
+		return
+	}
+	for i, label := range d.Options {
+		r := d.option(i).Add(offset)
+		fill := ColorIdle
+		if i == d.Selected {
+			fill = ColorSelected
+		}
+		drawFrame(dst, r, fill)
+		drawCaption(dst, label, r)
+		if d.focused && i == d.hover {
+			vector.StrokeRect(dst, float32(r.Min.X)+1, float32(r.Min.Y)+1,
+				float32(r.Dx())-2, float32(r.Dy())-2, 2, ColorFocus, false)
+		}
+	}
+}
+
+func (d *Dropdown) TooltipAt(p image.Point) Tooltip {
+	if p.In(d.rect) && !d.open {
+		return d.Tooltip
+	}
+	return Tooltip{}
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Nav is a keyboard or gamepad navigation press
+type Nav int
+
+const (
+	NavNone Nav = iota
+	NavUp
+	NavDown
+	NavLeft
+	NavRight
+	NavActivate
+)
+
+// ColorFocus outlines the widget that keyboard and gamepad input goes to
+var ColorFocus = color.RGBA{255, 210, 80, 255}
+
+// navKeys maps keyboard keys to navigation presses
+var navKeys = map[ebiten.Key]Nav{
+	ebiten.KeyArrowUp:    NavUp,
+	ebiten.KeyArrowDown:  NavDown,
+	ebiten.KeyArrowLeft:  NavLeft,
+	ebiten.KeyArrowRight: NavRight,
+	ebiten.KeyEnter:      NavActivate,
+	ebiten.KeySpace:      NavActivate,
+}
+
+// navButtons maps standard gamepad buttons to navigation presses: the D-pad
+// moves and A activates
+var navButtons = map[ebiten.StandardGamepadButton]Nav{
+	ebiten.StandardGamepadButtonLeftTop:     NavUp,
+	ebiten.StandardGamepadButtonLeftBottom:  NavDown,
+	ebiten.StandardGamepadButtonLeftLeft:    NavLeft,
+	ebiten.StandardGamepadButtonLeftRight:   NavRight,
+	ebiten.StandardGamepadButtonRightBottom: NavActivate,
+}
+
+// readNav returns this frame's navigation press, if any
+func readNav() Nav {
+	for key, nav := range navKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			return nav
+		}
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for button, nav := range navButtons {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+				return nav
+			}
+		}
+	}
+	return NavNone
+}
+
+// Focusable is a widget that can be reached and used without the mouse
+type Focusable interface {
+	Widget
+	SetFocused(bool)
+	// Navigate handles a press while the widget has focus and reports
+	// whether it used it; unused moves take focus to a neighbour
+	Navigate(n Nav) bool
+}
+
+// focus is embedded by focusable widgets
+type focus struct {
+	focused bool
+}
+
+func (f *focus) SetFocused(on bool) { f.focused = on }
+
+// drawFocus outlines r when the widget has focus
+func (f *focus) drawFocus(dst *ebiten.Image, r image.Rectangle) {
+	if f.focused {
+		vector.StrokeRect(dst, float32(r.Min.X)-1, float32(r.Min.Y)-1,
+			float32(r.Dx())+2, float32(r.Dy())+2, 2, ColorFocus, false)
+	}
+}
+
+// container is implemented by widgets that hold other widgets
+type container interface {
+	widgets() []Widget
+}
+
+// focusables lists the focusable widgets under ws, depth first
+func focusables(ws []Widget) []Focusable {
+	var out []Focusable
+	for _, w := range ws {
+		if f, ok := w.(Focusable); ok {
+			out = append(out, f)
+		}
+		if c, ok := w.(container); ok {
+			out = append(out, focusables(c.widgets())...)
+		}
+	}
+	return out
+}
+
+// neighbour finds the closest widget from cur in the direction of n. Moving
+// up or down may drift sideways, so a column of grid cells can be walked;
+// moving left or right stays on the same row.
+func neighbour(all []Focusable, cur Focusable, n Nav) Focusable {
+	from := center(cur.Rect())
+	var best Focusable
+	bestDist := 0
+	for _, f := range all {
+		if f == cur {
+			continue
+		}
+		r := f.Rect()
+		d := center(r).Sub(from)
+		var dist int
+		switch n {
+		case NavUp, NavDown:
+			if (n == NavUp) != (d.Y < 0) || d.Y == 0 {
+				continue
+			}
+			dist = abs(d.Y) + 2*abs(d.X)
+		case NavLeft, NavRight:
+			sameRow := r.Min.Y < cur.Rect().Max.Y && r.Max.Y > cur.Rect().Min.Y
+			if !sameRow || (n == NavLeft) != (d.X < 0) || d.X == 0 {
+				continue
+			}
+			dist = abs(d.X)
+		default:
+			return nil
+		}
+		if best == nil || dist < bestDist {
+			best, bestDist = f, dist
+		}
+	}
+	return best
+}
+
+func center(r image.Rectangle) image.Point {
+	return r.Min.Add(r.Max).Div(2)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,340 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	scrollBarWidth     = 10
+	scrollBarMinHeight = 30
+	wheelStep          = 20 // Pixels scrolled per wheel notch
+)
+
+// Grid lays its children out in equal columns, filling rows left to right
+type Grid struct {
+	box
+	Columns  int
+	Gap      int
+	Children []Widget
+}
+
+// rowHeight is the height of the tallest child, which every row uses
+func (g *Grid) rowHeight(cellW int) int {
+	h := 0
+	for _, c := range g.Children {
+		h = max(h, c.PreferredHeight(cellW))
+	}
+	return h
+}
+
+func (g *Grid) cellWidth(width int) int {
+	cols := max(1, g.Columns)
+	return (width - (cols-1)*g.Gap) / cols
+}
+
+func (g *Grid) PreferredHeight(width int) int {
+	cols := max(1, g.Columns)
+	rows := (len(g.Children) + cols - 1) / cols
+	if rows == 0 {
+		return 0
+	}
+	return rows*g.rowHeight(g.cellWidth(width)) + (rows-1)*g.Gap
+}
+
+func (g *Grid) SetRect(r image.Rectangle) {
+	g.rect = r
+	cols := max(1, g.Columns)
+	cellW := g.cellWidth(r.Dx())
+	rowH := g.rowHeight(cellW)
+	for i, c := range g.Children {
+		x := r.Min.X + (i%cols)*(cellW+g.Gap)
+		y := r.Min.Y + (i/cols)*(rowH+g.Gap)
+		c.SetRect(image.Rect(x, y, x+cellW, y+rowH))
+	}
+}
+
+func (g *Grid) widgets() []Widget { return g.Children }
+
+func (g *Grid) Update(in *Input) {
+	for _, c := range g.Children {
+		c.Update(in)
+	}
+}
+
+func (g *Grid) Draw(dst *ebiten.Image, offset image.Point) {
+	for _, c := range g.Children {
+		c.Draw(dst, offset)
+	}
+}
+
+func (g *Grid) TooltipAt(p image.Point) Tooltip {
+	for _, c := range g.Children {
+		if p.In(c.Rect()) {
+			return c.TooltipAt(p)
+		}
+	}
+	return Tooltip{}
+}
+
+// VBox stacks its children in a centered column inside a viewport, scrolled
+// with the mouse wheel or a scrollbar along the viewport's right edge when
+// they don't all fit. Open popups among its children get input first and
+// are drawn on top. Navigation presses move focus between every focusable
+// widget inside it, scrolling the focused one into view.
+type VBox struct {
+	box
+	Width    int // Width of the column of children, narrowed to fit a smaller viewport
+	Gap      int
+	Padding  int // Space above the first and below the last child
+	Children []Widget
+	ScrollY  int
+
+	contentHeight int
+	grabbed       bool // Is the scrollbar being dragged
+	focused       Focusable
+}
+
+func (v *VBox) widgets() []Widget { return v.Children }
+
+// Focus moves keyboard focus to w, or clears it if w is nil
+func (v *VBox) Focus(w Focusable) {
+	if v.focused != nil {
+		v.focused.SetFocused(false)
+	}
+	v.focused = w
+	if w == nil {
+		return
+	}
+	w.SetFocused(true)
+
+	// Scroll just far enough to show the whole widget
+	r := w.Rect()
+	if r.Min.Y-v.ScrollY < v.rect.Min.Y {
+		v.scrollTo(r.Min.Y - v.rect.Min.Y - v.Padding)
+	} else if r.Max.Y-v.ScrollY > v.rect.Max.Y {
+		v.scrollTo(r.Max.Y - v.rect.Max.Y + v.Padding)
+	}
+}
+
+// Focused returns the widget with keyboard focus, if any
+func (v *VBox) Focused() Focusable {
+	return v.focused
+}
+
+// FocusIndex returns the position of the focused widget among all the
+// focusable ones, or -1 if nothing has focus
+func (v *VBox) FocusIndex() int {
+	for i, f := range focusables(v.Children) {
+		if f == v.focused {
+			return i
+		}
+	}
+	return -1
+}
+
+// FocusNth focuses the i-th focusable widget; an index out of range clears focus
+func (v *VBox) FocusNth(i int) {
+	all := focusables(v.Children)
+	if i < 0 || i >= len(all) {
+		v.Focus(nil)
+		return
+	}
+	v.Focus(all[i])
+}
+
+// navigate hands a press to the focused widget, or moves focus if it has no
+// use for it. The first press only shows where focus is.
+func (v *VBox) navigate(n Nav) {
+	all := focusables(v.Children)
+	if len(all) == 0 {
+		return
+	}
+	if v.focused == nil {
+		v.Focus(all[0])
+		return
+	}
+	if v.focused.Navigate(n) {
+		return
+	}
+	if next := neighbour(all, v.focused, n); next != nil {
+		v.Focus(next)
+	}
+}
+
+// focusClicked moves focus to the focusable widget under a click, so mouse
+// and keyboard can be mixed freely
+func (v *VBox) focusClicked(in *Input) {
+	if !in.Clicked || !in.Over(v.rect) {
+		return
+	}
+	p := in.Cursor.Add(image.Pt(0, v.ScrollY))
+	for _, f := range focusables(v.Children) {
+		if p.In(f.Rect()) {
+			v.Focus(f)
+			return
+		}
+	}
+}
+
+// columnWidth is the width of the column of children in a viewport of the
+// given width, leaving room for the scrollbar
+func (v *VBox) columnWidth(viewW int) int {
+	return max(1, min(v.Width, viewW-4*scrollBarWidth))
+}
+
+// PreferredHeight is the full height of the content, unscrolled
+func (v *VBox) PreferredHeight(width int) int {
+	h := 2 * v.Padding
+	for i, c := range v.Children {
+		if i > 0 {
+			h += v.Gap
+		}
+		h += c.PreferredHeight(v.columnWidth(width))
+	}
+	return h
+}
+
+// SetRect sets the viewport and lays the children out in content
+// coordinates, which start at the top of the viewport
+func (v *VBox) SetRect(r image.Rectangle) {
+	v.rect = r
+	w := v.columnWidth(r.Dx())
+	x := r.Min.X + (r.Dx()-w)/2
+	y := r.Min.Y + v.Padding
+	for _, c := range v.Children {
+		h := c.PreferredHeight(w)
+		c.SetRect(image.Rect(x, y, x+w, y+h))
+		y += h + v.Gap
+	}
+	v.contentHeight = v.PreferredHeight(r.Dx())
+	v.scrollTo(v.ScrollY)
+}
+
+// maxScroll is how far the content can scroll before its end comes into view
+func (v *VBox) maxScroll() int {
+	return max(0, v.contentHeight-v.rect.Dy())
+}
+
+func (v *VBox) scrollTo(y int) {
+	v.ScrollY = max(0, min(y, v.maxScroll()))
+}
+
+// scrollBar returns the track and handle of the scrollbar
+func (v *VBox) scrollBar() (track, handle image.Rectangle) {
+	track = image.Rect(v.rect.Max.X-2*scrollBarWidth, v.rect.Min.Y, v.rect.Max.X-scrollBarWidth, v.rect.Max.Y)
+	h := max(scrollBarMinHeight, v.rect.Dy()*v.rect.Dy()/max(1, v.contentHeight))
+	y := track.Min.Y
+	if v.maxScroll() > 0 {
+		y += v.ScrollY * (track.Dy() - h) / v.maxScroll()
+	}
+	return track, image.Rect(track.Min.X, y, track.Max.X, y+h)
+}
+
+// content translates the input into content coordinates
+func (v *VBox) content(in *Input) *Input {
+	c := *in
+	c.Hover = in.Over(v.rect)
+	c.Cursor.Y += v.ScrollY
+	return &c
+}
+
+func (v *VBox) Update(in *Input) {
+	// An open popup takes the click and navigation, wherever they land
+	for _, c := range v.Children {
+		if p, ok := c.(popup); ok && p.Open() {
+			if f, ok := p.(Focusable); ok && in.Nav != NavNone {
+				v.Focus(f)
+				f.Navigate(in.Nav)
+			}
+			inner := v.content(in)
+			p.Update(inner)
+			in.Clicked = inner.Clicked
+			return
+		}
+	}
+
+	if in.Nav != NavNone {
+		v.navigate(in.Nav)
+	}
+	v.focusClicked(in)
+
+	if in.WheelY != 0 && in.Over(v.rect) {
+		v.scrollTo(v.ScrollY - int(in.WheelY*wheelStep))
+	}
+
+	// Drag the scrollbar; clicking the track jumps there
+	if v.maxScroll() > 0 {
+		track, handle := v.scrollBar()
+		if in.Clicked && in.Over(track) {
+			in.Clicked = false
+			v.grabbed = true
+		}
+		if !in.Down {
+			v.grabbed = false
+		}
+		if v.grabbed {
+			pos := float64(in.Cursor.Y-track.Min.Y-handle.Dy()/2) / float64(track.Dy()-handle.Dy())
+			v.scrollTo(int(pos * float64(v.maxScroll())))
+		}
+	}
+
+	inner := v.content(in)
+	for _, c := range v.Children {
+		c.Update(inner)
+	}
+	in.Clicked = inner.Clicked
+}
+
+func (v *VBox) Draw(dst *ebiten.Image, offset image.Point) {
+	viewport := v.rect.Add(offset)
+	clip := dst.SubImage(viewport).(*ebiten.Image)
+	scrolled := offset.Sub(image.Pt(0, v.ScrollY))
+	for _, c := range v.Children {
+		r := c.Rect().Add(scrolled)
+		if r.Overlaps(viewport) {
+			c.Draw(clip, scrolled)
+		}
+	}
+
+	if v.maxScroll() > 0 {
+		track, handle := v.scrollBar()
+		track, handle = track.Add(offset), handle.Add(offset)
+		vector.DrawFilledRect(dst, float32(track.Min.X), float32(track.Min.Y),
+			float32(track.Dx()), float32(track.Dy()), color.RGBA{40, 40, 50, 255}, false)
+		fill := color.RGBA{100, 100, 120, 255}
+		if v.grabbed {
+			fill = color.RGBA{120, 120, 150, 255}
+		}
+		vector.DrawFilledRect(dst, float32(handle.Min.X), float32(handle.Min.Y),
+			float32(handle.Dx()), float32(handle.Dy()), fill, false)
+	}
+
+	// Popups may hang past the viewport, so they are not clipped
+	for _, c := range v.Children {
+		if p, ok := c.(popup); ok {
+			p.DrawPopup(dst, scrolled)
+		}
+	}
+}
+
+func (v *VBox) TooltipAt(p image.Point) Tooltip {
+	if !p.In(v.rect) {
+		return Tooltip{}
+	}
+	for _, c := range v.Children {
+		if pop, ok := c.(popup); ok && pop.Open() {
+			return Tooltip{} // The open list covers whatever it would explain
+		}
+	}
+	p.Y += v.ScrollY
+	for _, c := range v.Children {
+		if p.In(c.Rect()) {
+			return c.TooltipAt(p)
+		}
+	}
+	return Tooltip{}
+}
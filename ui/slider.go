@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	sliderLabelHeight = 20
+	sliderTrackHeight = 20
+)
+
+// Slider picks an integer between Min and Max. It is dragged by the handle
+// or set by clicking anywhere on the track; with focus, left and right step
+// the value.
+type Slider struct {
+	box
+	focus
+	Label    string
+	Min, Max int
+	Value    int
+	Tooltip  Tooltip
+	OnChange func(int)
+	dragging bool
+}
+
+func (s *Slider) PreferredHeight(int) int {
+	return sliderLabelHeight + sliderTrackHeight + 5 // Room for the handle's overhang
+}
+
+// track is where the bar is drawn, below the label
+func (s *Slider) track() image.Rectangle {
+	top := s.rect.Min.Y + sliderLabelHeight
+	return image.Rect(s.rect.Min.X, top, s.rect.Max.X, top+sliderTrackHeight)
+}
+
+func (s *Slider) Update(in *Input) {
+	if in.Clicked && in.Over(s.track()) {
+		in.Clicked = false
+		s.dragging = true
+	}
+	if !in.Down {
+		s.dragging = false
+	}
+	if !s.dragging {
+		return
+	}
+
+	track := s.track()
+	pos := float64(in.Cursor.X-track.Min.X) / float64(track.Dx())
+	s.set(s.Min + int(pos*float64(s.Max-s.Min)))
+}
+
+// set clamps and applies a new value
+func (s *Slider) set(value int) {
+	value = max(s.Min, min(s.Max, value))
+	if value != s.Value {
+		s.Value = value
+		if s.OnChange != nil {
+			s.OnChange(value)
+		}
+	}
+}
+
+func (s *Slider) Navigate(n Nav) bool {
+	switch n {
+	case NavLeft:
+		s.set(s.Value - 1)
+	case NavRight:
+		s.set(s.Value + 1)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Slider) Draw(dst *ebiten.Image, offset image.Point) {
+	r := s.rect.Add(offset)
+	DrawText(dst, fmt.Sprintf("%s: %d", s.Label, s.Value), r.Min.X, r.Min.Y)
+
+	track := s.track().Add(offset)
+	vector.DrawFilledRect(dst, float32(track.Min.X), float32(track.Min.Y),
+		float32(track.Dx()), float32(track.Dy()), ColorTrack, false)
+
+	handle := float32(track.Min.X) + float32(track.Dx())*float32(s.Value-s.Min)/float32(s.Max-s.Min)
+	vector.DrawFilledRect(dst, handle-5, float32(track.Min.Y)-5, 10, float32(track.Dy())+10, ColorHandle, false)
+	s.drawFocus(dst, track)
+}
+
+func (s *Slider) TooltipAt(p image.Point) Tooltip {
+	if p.In(s.rect) {
+		return s.Tooltip
+	}
+	return Tooltip{}
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"bytes"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Text sizes in pixels. SizeBody is close to the old debug font, so
+// layouts sized for it still fit.
+const (
+	SizeSmall = 11
+	SizeBody  = 13
+	SizeLarge = 18
+	SizeTitle = 28
+)
+
+// TextAlign positions a line of text relative to the x it is drawn at
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextStyle controls how a string is drawn. The zero value is plain white
+// body text, left aligned.
+type TextStyle struct {
+	Size   float64     // Pixel size; 0 means SizeBody
+	Color  color.Color // nil means white
+	Align  TextAlign
+	Bold   bool
+	Shadow bool // Dark drop shadow, for text over the dungeon
+}
+
+// TextRenderer draws UI text in the Go fonts bundled with the binary
+type TextRenderer struct {
+	regular *text.GoTextFaceSource
+	bold    *text.GoTextFaceSource
+}
+
+// Text is the renderer shared by all UI drawing code
+var Text = mustTextRenderer()
+
+// NewTextRenderer parses the bundled fonts
+func NewTextRenderer() (*TextRenderer, error) {
+	regular, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		return nil, err
+	}
+	bold, err := text.NewGoTextFaceSource(bytes.NewReader(gobold.TTF))
+	if err != nil {
+		return nil, err
+	}
+	return &TextRenderer{regular: regular, bold: bold}, nil
+}
+
+// mustTextRenderer panics if the bundled fonts fail to parse, which can only
+// happen if the binary itself is broken
+func mustTextRenderer() *TextRenderer {
+	r, err := NewTextRenderer()
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *TextRenderer) face(style TextStyle) *text.GoTextFace {
+	size := style.Size
+	if size == 0 {
+		size = SizeBody
+	}
+	source := r.regular
+	if style.Bold {
+		source = r.bold
+	}
+	return &text.GoTextFace{Source: source, Size: size}
+}
+
+// lineSpacing leaves a little room between lines of multi-line text
+func lineSpacing(face *text.GoTextFace) float64 {
+	return face.Size * 1.25
+}
+
+// Draw draws s with its top at y. Multi-line strings are supported.
+func (r *TextRenderer) Draw(screen *ebiten.Image, s string, x, y int, style TextStyle) {
+	face := r.face(style)
+	op := &text.DrawOptions{}
+	op.LineSpacing = lineSpacing(face)
+	switch style.Align {
+	case AlignCenter:
+		op.PrimaryAlign = text.AlignCenter
+	case AlignRight:
+		op.PrimaryAlign = text.AlignEnd
+	}
+
+	if style.Shadow {
+		// The shadow fades along with translucent text
+		shade := uint8(200)
+		if style.Color != nil {
+			_, _, _, a := style.Color.RGBA()
+			shade = uint8(200 * a / 0xffff)
+		}
+		op.GeoM.Translate(float64(x+1), float64(y+1))
+		op.ColorScale.ScaleWithColor(color.RGBA{0, 0, 0, shade})
+		text.Draw(screen, s, face, op)
+		op.GeoM.Reset()
+		op.ColorScale.Reset()
+	}
+
+	op.GeoM.Translate(float64(x), float64(y))
+	if style.Color != nil {
+		op.ColorScale.ScaleWithColor(style.Color)
+	}
+	text.Draw(screen, s, face, op)
+}
+
+// Measure returns the width and height s takes up in the given style
+func (r *TextRenderer) Measure(s string, style TextStyle) (int, int) {
+	face := r.face(style)
+	w, h := text.Measure(s, face, lineSpacing(face))
+	return int(w + 0.5), int(h + 0.5)
+}
+
+// DrawText draws body text in the default style, the drop-in replacement
+// for ebitenutil.DebugPrintAt
+func DrawText(screen *ebiten.Image, s string, x, y int) {
+	Text.Draw(screen, s, x, y, TextStyle{})
+}
@@ -0,0 +1,125 @@
+// Package ui is the small widget toolkit the game's menus are built from.
+// Containers lay widgets out, pass them the pointer state every frame and
+// handle scrolling; widgets draw themselves with the shared Text renderer.
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Colors shared by all widgets
+var (
+	ColorIdle     = color.RGBA{50, 50, 60, 255}
+	ColorSelected = color.RGBA{100, 100, 200, 255}
+	ColorBorder   = color.RGBA{200, 200, 220, 255}
+	ColorTrack    = color.RGBA{80, 80, 90, 255}
+	ColorHandle   = color.RGBA{180, 180, 220, 255}
+)
+
+// DefaultHeight is the height of a button, toggle or dropdown row
+const DefaultHeight = 30
+
+// Translate turns the few words the widgets draw themselves into the game's
+// current language. Labels passed in by the game are expected to be
+// translated already.
+var Translate = func(s string) string { return s }
+
+// Tooltip is the boxed, multi-line description shown next to the cursor for
+// whatever it is over: a tile, an inventory slot or a menu widget
+type Tooltip struct {
+	Title string
+	Lines []string
+}
+
+// Empty reports whether there is nothing to show
+func (t Tooltip) Empty() bool {
+	return t.Title == "" && len(t.Lines) == 0
+}
+
+// Add appends a line, skipping empty ones so callers can add optional details freely
+func (t *Tooltip) Add(format string, args ...any) {
+	if line := fmt.Sprintf(format, args...); line != "" {
+		t.Lines = append(t.Lines, line)
+	}
+}
+
+// Input is the pointer and navigation state for one frame. Containers
+// translate Cursor into their content coordinates before passing it on, and a
+// widget that handles a click clears Clicked so nothing underneath reacts to
+// it as well.
+type Input struct {
+	Cursor  image.Point
+	Down    bool    // Left button held
+	Clicked bool    // Left button pressed this frame
+	WheelY  float64 // Vertical wheel movement this frame
+	Hover   bool    // Cursor is over the visible part of the container
+	Nav     Nav     // Keyboard or gamepad navigation pressed this frame
+}
+
+// ReadInput reads this frame's mouse state in screen coordinates, along with
+// any navigation press from the keyboard or a gamepad
+func ReadInput() *Input {
+	x, y := ebiten.CursorPosition()
+	_, wheelY := ebiten.Wheel()
+	return &Input{
+		Cursor:  image.Pt(x, y),
+		Down:    ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft),
+		Clicked: inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft),
+		WheelY:  wheelY,
+		Hover:   true,
+		Nav:     readNav(),
+	}
+}
+
+// Over reports whether the cursor is on r and not hidden by scrolling
+func (in *Input) Over(r image.Rectangle) bool {
+	return in.Hover && in.Cursor.In(r)
+}
+
+// Widget is anything a container can lay out, update and draw
+type Widget interface {
+	// PreferredHeight is how tall the widget wants to be at the given width
+	PreferredHeight(width int) int
+	// SetRect places the widget, in its container's content coordinates
+	SetRect(r image.Rectangle)
+	Rect() image.Rectangle
+	Update(in *Input)
+	// Draw draws the widget shifted by offset, which containers use for scrolling
+	Draw(dst *ebiten.Image, offset image.Point)
+	// TooltipAt returns what to show with the cursor at p, if anything
+	TooltipAt(p image.Point) Tooltip
+}
+
+// popup is implemented by widgets that can open over their neighbours. While
+// open they get input before anything else and are drawn on top.
+type popup interface {
+	Widget
+	Open() bool
+	DrawPopup(dst *ebiten.Image, offset image.Point)
+}
+
+// box holds a widget's placement
+type box struct {
+	rect image.Rectangle
+}
+
+func (b *box) SetRect(r image.Rectangle) { b.rect = r }
+func (b *box) Rect() image.Rectangle     { return b.rect }
+
+// drawFrame draws a filled, bordered rectangle
+func drawFrame(dst *ebiten.Image, r image.Rectangle, fill color.Color) {
+	vector.DrawFilledRect(dst, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), fill, false)
+	vector.StrokeRect(dst, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), 1, ColorBorder, false)
+}
+
+// drawCaption draws s left aligned and vertically centered in r
+func drawCaption(dst *ebiten.Image, s string, r image.Rectangle) {
+	_, h := Text.Measure(s, TextStyle{})
+	Text.Draw(dst, s, r.Min.X+10, r.Min.Y+(r.Dy()-h)/2, TextStyle{})
+}
@@ -0,0 +1,101 @@
+package main
+
+const (
+	fountainCooldown = 100 // World turns before a fountain refills
+	fountainHeal     = 20
+	altarUses        = 1 // A healing altar works once per floor
+	fountainChance   = 35
+	altarChance      = 25
+)
+
+// Usage holds the per-instance limits of a repeatable interaction: how often
+// it can be used in total and how long it needs between uses
+type Usage struct {
+	MaxUses  int // 0 means unlimited
+	Cooldown int // World turns between uses; 0 means none
+	Uses     int
+	ReadyAt  int // World turn the interaction can next be used on
+}
+
+// Limited is implemented by interactions with usage limits. The interaction
+// handler checks and records their usage around Interact.
+type Limited interface {
+	Interactable
+	Usage() *Usage
+}
+
+// Ready reports whether the interaction can be used on the given turn, with
+// the reason if it can't
+func (u *Usage) Ready(turn int) (bool, string) {
+	switch {
+	case u.MaxUses > 0 && u.Uses >= u.MaxUses:
+		return false, tr("It has nothing more to give.")
+	case turn < u.ReadyAt:
+		return false, tr("It needs %d more turns to recover.", u.ReadyAt-turn)
+	}
+	return true, ""
+}
+
+// Use records one use on the given turn
+func (u *Usage) Use(turn int) {
+	u.Uses++
+	u.ReadyAt = turn + u.Cooldown
+}
+
+// Describe summarises the usage state for hover info
+func (u *Usage) Describe(turn int) string {
+	if ready, _ := u.Ready(turn); !ready {
+		if u.MaxUses > 0 && u.Uses >= u.MaxUses {
+			return tr("used up")
+		}
+		return tr("ready in %d turns", u.ReadyAt-turn)
+	}
+	if u.MaxUses > 0 {
+		return tr("%d use(s) left", u.MaxUses-u.Uses)
+	}
+	return tr("ready")
+}
+
+// --- Fountain Interaction ---
+
+// FountainInteraction heals the player and refills after a cooldown
+type FountainInteraction struct {
+	usage Usage
+}
+
+func NewFountainInteraction() *FountainInteraction {
+	return &FountainInteraction{usage: Usage{Cooldown: fountainCooldown}}
+}
+
+func (f *FountainInteraction) Usage() *Usage { return &f.usage }
+
+func (f *FountainInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	return InteractionResult{
+		Message:      tr("You drink from the fountain. (+%d health, then some)", fountainHeal),
+		Kind:         LogEvent,
+		HealthChange: fountainHeal,
+		Effects: []StatusEffect{{Kind: StatusRegeneration, Turns: fountainRegenTurns,
+			Amount: fountainRegenAmount}},
+	}
+}
+
+// --- Healing Altar Interaction ---
+
+// AltarInteraction fully heals the player, once per floor
+type AltarInteraction struct {
+	usage Usage
+}
+
+func NewAltarInteraction() *AltarInteraction {
+	return &AltarInteraction{usage: Usage{MaxUses: altarUses}}
+}
+
+func (a *AltarInteraction) Usage() *Usage { return &a.usage }
+
+func (a *AltarInteraction) Interact(player *Player, rng *RNG) InteractionResult {
+	return InteractionResult{
+		Message:      tr("You kneel at the altar. Your wounds close."),
+		Kind:         LogEvent,
+		HealthChange: player.MaxHealth - player.Health,
+	}
+}